@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// maxConnectBackoff caps the wait between two connection attempts.
+const maxConnectBackoff = 30 * time.Second
+
+// connectWithRetry calls open up to attempts times until it succeeds. The
+// wait before the second attempt is interval and doubles after every
+// further failure, up to maxConnectBackoff, so a database that is still
+// starting up has time to come up.
+func connectWithRetry(ctx context.Context, open func() (*repository.SQLiteJokeRepository, error), attempts int, interval time.Duration, logger *slog.Logger) (*repository.SQLiteJokeRepository, error) {
+	wait := interval
+
+	for attempt := 1; ; attempt++ {
+		repo, err := open()
+		if err == nil {
+			if attempt > 1 {
+				logger.Info("Connected to database", "attempt", attempt)
+			}
+			return repo, nil
+		}
+
+		if attempt >= attempts {
+			return nil, fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		logger.Warn("Failed to connect to database, retrying",
+			"attempt", attempt,
+			"max_attempts", attempts,
+			"retry_in", wait,
+			slog.String("error", err.Error()),
+		)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+
+		wait = min(wait*2, maxConnectBackoff)
+	}
+}
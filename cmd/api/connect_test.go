@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// flakyOpener fails the first failures calls before opening a real
+// repository, like a database that takes a while to come up.
+func flakyOpener(t *testing.T, failures int, calls *int) func() (*repository.SQLiteJokeRepository, error) {
+	dbPath := filepath.Join(t.TempDir(), "jokes.db")
+
+	return func() (*repository.SQLiteJokeRepository, error) {
+		*calls++
+		if *calls <= failures {
+			return nil, errors.New("failed to connect to database: connection refused")
+		}
+		return repository.NewSQLiteJokeRepository(dbPath, repository.Options{})
+	}
+}
+
+func TestConnectWithRetryWaitsForDatabase(t *testing.T) {
+	var calls int
+	var logs bytes.Buffer
+
+	repo, err := connectWithRetry(context.Background(), flakyOpener(t, 2, &calls), 5, time.Millisecond, slog.New(slog.NewTextHandler(&logs, nil)))
+	if err != nil {
+		t.Fatalf("connectWithRetry: %v", err)
+	}
+	defer repo.Close()
+
+	if err := repo.Ping(context.Background()); err != nil {
+		t.Errorf("Ping after connecting: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("open called %d times, want 3", calls)
+	}
+	if got := strings.Count(logs.String(), "retrying"); got != 2 {
+		t.Errorf("logged %d retries, want 2:\n%s", got, logs.String())
+	}
+	if !strings.Contains(logs.String(), "attempt=3") {
+		t.Errorf("logs = %q, want the successful attempt", logs.String())
+	}
+}
+
+func TestConnectWithRetryGivesUp(t *testing.T) {
+	var calls int
+
+	_, err := connectWithRetry(context.Background(), flakyOpener(t, 5, &calls), 3, time.Millisecond, slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+	if err == nil || !strings.Contains(err.Error(), "giving up after 3 attempts") {
+		t.Fatalf("connectWithRetry error = %v, want giving up after 3 attempts", err)
+	}
+	if calls != 3 {
+		t.Errorf("open called %d times, want 3", calls)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/middleware"
+)
+
+// envDuration reads a positive duration such as "30s" from the environment,
+// returning def when the variable is unset.
+func envDuration(key string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s: %q", key, v)
+	}
+
+	return d, nil
+}
+
+// envDurationAllowZero is envDuration for settings where zero turns the
+// feature off, so an explicit 0 is accepted as well.
+func envDurationAllowZero(key string, def time.Duration) (time.Duration, error) {
+	if os.Getenv(key) == "0" {
+		return 0, nil
+	}
+
+	return envDuration(key, def)
+}
+
+// envInt reads a positive integer from the environment, returning def when
+// the variable is unset.
+func envInt(key string, def int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid %s: %q", key, v)
+	}
+
+	return n, nil
+}
+
+// envIntAllowZero is envInt for limits where zero means no limit, so an
+// explicit 0 is accepted as well.
+func envIntAllowZero(key string, def int) (int, error) {
+	if os.Getenv(key) == "0" {
+		return 0, nil
+	}
+
+	return envInt(key, def)
+}
+
+// minAdminKeyLength is the shortest admin key accepted at startup.
+const minAdminKeyLength = 16
+
+// parseAdminKey trims surrounding whitespace from the configured admin key
+// and rejects keys that are missing or too short to be safe.
+func parseAdminKey(v string) (string, error) {
+	key := strings.TrimSpace(v)
+
+	switch {
+	case key == "":
+		return "", fmt.Errorf("ADMIN_API_KEY environment variable not set")
+	case len(key) < minAdminKeyLength:
+		return "", fmt.Errorf("ADMIN_API_KEY must be at least %d characters long", minAdminKeyLength)
+	}
+
+	return key, nil
+}
+
+// parseCacheControlPolicies parses CACHE_CONTROL_POLICY, a semicolon
+// separated list of path.Match patterns and the Cache-Control value for
+// them, such as "/api/joke/random=no-store; /api/joke/*=public, max-age=300".
+// The first matching pattern wins.
+func parseCacheControlPolicies(v string) ([]middleware.CacheControlPolicy, error) {
+	var policies []middleware.CacheControlPolicy
+	for _, entry := range strings.Split(v, ";") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+
+		pattern, value, ok := strings.Cut(entry, "=")
+		pattern, value = strings.TrimSpace(pattern), strings.TrimSpace(value)
+		if !ok || pattern == "" || value == "" {
+			return nil, fmt.Errorf("invalid CACHE_CONTROL_POLICY entry %q, expected PATTERN=VALUE", entry)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid CACHE_CONTROL_POLICY pattern %q", pattern)
+		}
+
+		policies = append(policies, middleware.CacheControlPolicy{Path: pattern, Value: value})
+	}
+
+	return policies, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/middleware"
+)
+
+func TestParseAdminKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"empty", "", "", true},
+		{"whitespace only", " \t\n  ", "", true},
+		{"too short", "short-key", "", true},
+		{"too short after trimming", "   short-key      ", "", true},
+		{"valid", "0123456789abcdef", "0123456789abcdef", false},
+		{"valid with surrounding whitespace", "  0123456789abcdef\n", "0123456789abcdef", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAdminKey(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAdminKey(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseAdminKey(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCacheControlPolicies(t *testing.T) {
+	policies, err := parseCacheControlPolicies(" /api/joke/random=no-store; /api/joke/*=public, max-age=300 ;")
+	if err != nil {
+		t.Fatalf("parseCacheControlPolicies: %v", err)
+	}
+
+	want := []middleware.CacheControlPolicy{
+		{Path: "/api/joke/random", Value: "no-store"},
+		{Path: "/api/joke/*", Value: "public, max-age=300"},
+	}
+	if !slices.Equal(policies, want) {
+		t.Errorf("policies = %v, want %v", policies, want)
+	}
+
+	for _, v := range []string{"/api/joke/*", "=no-store", "/api/joke/*=", "/api/[joke=no-store"} {
+		if _, err := parseCacheControlPolicies(v); err == nil {
+			t.Errorf("parseCacheControlPolicies(%q) succeeded, want an error", v)
+		}
+	}
+}
+
+func TestEnvDurationAllowZero(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 10 * time.Second, false},
+		{"0", 0, false},
+		{"30s", 30 * time.Second, false},
+		{"0s", 0, true},
+		{"-1s", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("TEST_TIMEOUT", tt.value)
+
+		got, err := envDurationAllowZero("TEST_TIMEOUT", 10*time.Second)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: err = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestEnvIntAllowZero(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"", 5, false},
+		{"0", 0, false},
+		{"100", 100, false},
+		{"-1", 0, true},
+		{"many", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("TEST_LIMIT", tt.value)
+
+		got, err := envIntAllowZero("TEST_LIMIT", 5)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: err = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: got %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"compress/flate"
 	"context"
 	"fmt"
 	"log"
@@ -8,15 +9,24 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
+	"github.com/treboc/huhu-api/internal/backup"
+	"github.com/treboc/huhu-api/internal/config"
+	"github.com/treboc/huhu-api/internal/consistency"
+	"github.com/treboc/huhu-api/internal/featureflag"
 	"github.com/treboc/huhu-api/internal/handler"
-	internalMiddleware "github.com/treboc/huhu-api/internal/middleware"
+	"github.com/treboc/huhu-api/internal/importer"
+	"github.com/treboc/huhu-api/internal/middleware"
+	"github.com/treboc/huhu-api/internal/profanity"
 	"github.com/treboc/huhu-api/internal/repository"
+	"github.com/treboc/huhu-api/internal/seed"
+	"github.com/treboc/huhu-api/internal/server"
+	"github.com/treboc/huhu-api/internal/views"
+	"golang.org/x/text/unicode/norm"
 )
 
 func main() {
@@ -27,9 +37,36 @@ func main() {
 }
 
 func run() error {
-	adminApiKey := os.Getenv("ADMIN_API_KEY")
-	if adminApiKey == "" {
-		return fmt.Errorf("ADMIN_API_KEY environment variable not set")
+	startedAt := time.Now()
+
+	var keyAuth, jwtAuth bool
+	switch adminAuth := os.Getenv("ADMIN_AUTH"); adminAuth {
+	case "", "key":
+		keyAuth = true
+	case "jwt":
+		jwtAuth = true
+	case "both":
+		keyAuth, jwtAuth = true, true
+	default:
+		return fmt.Errorf("invalid ADMIN_AUTH %q, expected key, jwt or both", adminAuth)
+	}
+
+	var adminApiKey string
+	if keyAuth {
+		key, err := parseAdminKey(os.Getenv("ADMIN_API_KEY"))
+		if err != nil {
+			return err
+		}
+		adminApiKey = key
+	}
+
+	var adminJWT *middleware.JWTVerifier
+	if jwtAuth {
+		verifier, err := loadJWTVerifier()
+		if err != nil {
+			return err
+		}
+		adminJWT = verifier
 	}
 
 	port := os.Getenv("PORT")
@@ -37,63 +74,417 @@ func run() error {
 		return fmt.Errorf("PORT environment variable not set")
 	}
 
-	repo, err := repository.NewSQLiteJokeRepository("./jokes.db")
+	maxHeaderBytes, err := envInt("MAX_HEADER_BYTES", http.DefaultMaxHeaderBytes)
+	if err != nil {
+		return err
+	}
+
+	maxURLLength, err := envInt("MAX_URL_LENGTH", 2048)
+	if err != nil {
+		return err
+	}
+
+	// BODY_READ_TIMEOUT=0 turns the timeout off.
+	bodyReadTimeout, err := envDurationAllowZero("BODY_READ_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return err
+	}
+
+	// MAX_STREAMS=0 leaves concurrent streams unlimited.
+	maxStreams, err := envIntAllowZero("MAX_STREAMS", 0)
+	if err != nil {
+		return err
+	}
+
+	// MAX_OFFSET=0 leaves offsets unlimited.
+	maxOffset, err := envIntAllowZero("MAX_OFFSET", 0)
+	if err != nil {
+		return err
+	}
+
+	var duplicateWindow time.Duration
+	if os.Getenv("DUPLICATE_SUBMISSION_WINDOW") != "" {
+		duplicateWindow, err = envDuration("DUPLICATE_SUBMISSION_WINDOW", 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	cacheControlPolicies, err := parseCacheControlPolicies(os.Getenv("CACHE_CONTROL_POLICY"))
+	if err != nil {
+		return err
+	}
+
+	// CONTENT_SECURITY_POLICY=none turns the header off.
+	csp := os.Getenv("CONTENT_SECURITY_POLICY")
+	switch csp {
+	case "":
+		csp = middleware.DefaultContentSecurityPolicy
+	case "none":
+		csp = ""
+	}
+
+	var publicIDs bool
+	switch idScheme := os.Getenv("ID_SCHEME"); idScheme {
+	case "", "integer":
+	case "uuid":
+		publicIDs = true
+	default:
+		return fmt.Errorf("invalid ID_SCHEME %q, expected integer or uuid", idScheme)
+	}
+
+	var compressText bool
+	switch compression := os.Getenv("TEXT_COMPRESSION"); compression {
+	case "", "none":
+	case "gzip":
+		compressText = true
+	default:
+		return fmt.Errorf("invalid TEXT_COMPRESSION %q, expected none or gzip", compression)
+	}
+
+	maxJokes, err := envInt("MAX_JOKES", 0)
+	if err != nil {
+		return err
+	}
+
+	envFile := os.Getenv("ENV_FILE")
+	if envFile == "" {
+		envFile = ".env"
+	}
+
+	reloadable, err := config.NewReloadable(envFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: reloadable.LogLevel()}))
+
+	connectAttempts, err := envInt("DB_CONNECT_ATTEMPTS", 1)
+	if err != nil {
+		return err
+	}
+
+	connectInterval, err := envDuration("DB_CONNECT_INTERVAL", time.Second)
+	if err != nil {
+		return err
+	}
+
+	repo, err := connectWithRetry(context.Background(), func() (*repository.SQLiteJokeRepository, error) {
+		return repository.NewSQLiteJokeRepository("./jokes.db", repository.Options{
+			PublicIDs:    publicIDs,
+			CompressText: compressText,
+			MaxJokes:     maxJokes,
+		})
+	}, connectAttempts, connectInterval, logger)
 	if err != nil {
 		return fmt.Errorf("failed to initialize repository: %w", err)
 	}
 	defer repo.Close()
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	if os.Getenv("AUTO_SEED") == "true" {
+		seeded, err := seed.SeedIfEmpty(context.Background(), repo)
+		if err != nil {
+			return fmt.Errorf("failed to seed jokes: %w", err)
+		}
 
-	jokeHandler := handler.NewJokeHandler(repo, logger)
+		if seeded > 0 {
+			logger.Info("Seeded empty database with starter jokes", "count", seeded)
+		} else {
+			logger.Info("Skipped seeding, database already contains jokes")
+		}
+	}
 
-	r := chi.NewRouter()
+	if err := warmUp(context.Background(), repo, logger, startedAt); err != nil {
+		return err
+	}
 
-	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
+	flagRefreshInterval, err := envDuration("FEATURE_FLAG_REFRESH_INTERVAL", 30*time.Second)
+	if err != nil {
+		return err
+	}
 
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"}, // TODO: Update in production
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300,
-	}))
+	// DEGRADED_STARTUP=true starts the server even when optional
+	// subsystems fail to initialize.
+	degradedStartup := os.Getenv("DEGRADED_STARTUP") == "true"
 
-	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte("Hello from the Jokes API!"))
+	flags := featureflag.NewStore(repo)
+	err = initOptional("feature_flags", degradedStartup, logger, func() error {
+		if err := flags.Refresh(context.Background()); err != nil {
+			return fmt.Errorf("failed to load feature flags: %w", err)
+		}
+		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	var background workers
+	background.Go(func() { flags.Run(bgCtx, flagRefreshInterval, logger) })
+
+	viewFlushInterval, err := envDuration("VIEW_COUNT_FLUSH_INTERVAL", 10*time.Second)
+	if err != nil {
+		return err
+	}
+
+	viewCounter := views.NewCounter(repo, logger)
+	background.Go(func() { viewCounter.Run(bgCtx, viewFlushInterval) })
+
+	orphanCheckInterval, err := envDuration("ORPHAN_CHECK_INTERVAL", time.Hour)
+	if err != nil {
+		return err
+	}
+
+	checker := consistency.NewChecker(repo, logger)
+	background.Go(func() { checker.Run(bgCtx, orphanCheckInterval) })
+
+	backupDir := os.Getenv("BACKUP_DIR")
+	if backupDir != "" {
+		backupInterval, err := envDuration("BACKUP_INTERVAL", 24*time.Hour)
+		if err != nil {
+			return err
+		}
+		backupKeep, err := envInt("BACKUP_KEEP", 7)
+		if err != nil {
+			return err
+		}
+
+		backups := backup.NewWriter(repo, backupDir, backupKeep, logger)
+		background.Go(func() { backups.Run(bgCtx, backupInterval) })
+	}
+
+	var remoteSource *importer.RemoteSource
+	if url := os.Getenv("IMPORT_SOURCE_URL"); url != "" {
+		timeout, err := envDuration("IMPORT_SOURCE_TIMEOUT", 10*time.Second)
+		if err != nil {
+			return err
+		}
 
-	r.Get("/healthz", handler.HandleHealthz)
+		textField := os.Getenv("IMPORT_SOURCE_TEXT_FIELD")
+		if textField == "" {
+			textField = "joke"
+		}
+
+		remoteSource = importer.NewRemoteSource(url, os.Getenv("IMPORT_SOURCE_LIST_FIELD"), textField, timeout)
+	}
+
+	sanitizeMode := handler.SanitizeMode(os.Getenv("TEXT_SANITIZE"))
+	switch sanitizeMode {
+	case handler.SanitizeOff, handler.SanitizeStrip, handler.SanitizeReject:
+	default:
+		return fmt.Errorf("invalid TEXT_SANITIZE %q, expected strip or reject", sanitizeMode)
+	}
+
+	var unicodeForm norm.Form
+	switch form := os.Getenv("TEXT_NORMALIZATION"); form {
+	case "", "nfc":
+		unicodeForm = norm.NFC
+	case "nfd":
+		unicodeForm = norm.NFD
+	case "nfkc":
+		unicodeForm = norm.NFKC
+	case "nfkd":
+		unicodeForm = norm.NFKD
+	default:
+		return fmt.Errorf("invalid TEXT_NORMALIZATION %q, expected nfc, nfd, nfkc or nfkd", form)
+	}
+
+	profanityWords := strings.Split(os.Getenv("PROFANITY_WORDS"), ",")
+	if file := os.Getenv("PROFANITY_WORDS_FILE"); file != "" {
+		words, err := profanity.Load(file)
+		if err != nil {
+			return fmt.Errorf("failed to load PROFANITY_WORDS_FILE: %w", err)
+		}
+		profanityWords = append(profanityWords, words...)
+	}
+	profanityFilter := profanity.New(profanityWords)
+
+	profanityMode := handler.ProfanityMode(os.Getenv("PROFANITY_FILTER"))
+	switch profanityMode {
+	case handler.ProfanityOff, handler.ProfanityMask, handler.ProfanityReject:
+	default:
+		return fmt.Errorf("invalid PROFANITY_FILTER %q, expected mask or reject", profanityMode)
+	}
+
+	if profanityMode != handler.ProfanityOff && profanityFilter == nil {
+		return fmt.Errorf("PROFANITY_FILTER requires PROFANITY_WORDS or PROFANITY_WORDS_FILE to be set")
+	}
+
+	tellPause, err := envDuration("TELL_PAUSE", 2*time.Second)
+	if err != nil {
+		return err
+	}
+
+	accessLogSampleEvery, err := envInt("ACCESS_LOG_SAMPLE_EVERY", 1)
+	if err != nil {
+		return err
+	}
 
-	jokeRouter := chi.NewRouter()
-	jokeRouter.Get("/", jokeHandler.ListJokes)
-	jokeRouter.Get("/random", jokeHandler.GetRandomJoke)
-	jokeRouter.Get("/{id}", jokeHandler.GetJoke)
+	// Bodies that fail to decode are logged at debug level, up to this many
+	// bytes. Zero, the default, logs none.
+	logFailedBodyBytes, err := envInt("LOG_FAILED_BODY_BYTES", 0)
+	if err != nil {
+		return err
+	}
 
-	adminRouter := chi.NewRouter()
-	adminRouter.Use(internalMiddleware.AdminAuth(adminApiKey))
-	adminRouter.Post("/joke", jokeHandler.CreateJoke)
-	adminRouter.Put("/joke/{id}", jokeHandler.UpdateJoke)
-	adminRouter.Delete("/joke/{id}", jokeHandler.DeleteJoke)
+	readAPIKey := os.Getenv("READ_API_KEY")
+	var readProtectedPaths []string
+	for _, pattern := range strings.Split(os.Getenv("READ_PROTECTED_PATHS"), ",") {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid READ_PROTECTED_PATHS pattern %q", pattern)
+		}
+		readProtectedPaths = append(readProtectedPaths, pattern)
+	}
 
-	apiRouter := chi.NewRouter()
-	apiRouter.Mount("/admin", adminRouter)
-	apiRouter.Mount("/joke", jokeRouter)
+	if len(readProtectedPaths) > 0 && readAPIKey == "" {
+		return fmt.Errorf("READ_PROTECTED_PATHS requires READ_API_KEY to be set")
+	}
 
-	r.Mount("/api", apiRouter)
+	compressionLevel, err := envInt("RESPONSE_COMPRESSION_LEVEL", flate.DefaultCompression)
+	if err != nil {
+		return err
+	}
+	if compressionLevel > flate.BestCompression {
+		return fmt.Errorf("invalid RESPONSE_COMPRESSION_LEVEL %d, expected 1 to 9", compressionLevel)
+	}
 
-	srv := &http.Server{
-		Addr:    ":" + port,
-		Handler: r,
+	var responseCacheTTL time.Duration
+	if os.Getenv("RESPONSE_CACHE") == "true" {
+		responseCacheTTL, err = envDuration("RESPONSE_CACHE_TTL", 5*time.Second)
+		if err != nil {
+			return err
+		}
 	}
 
+	var jokeRepo repository.JokeRepository = repo
+
+	var breaker *repository.CircuitBreaker
+	if os.Getenv("CIRCUIT_BREAKER") == "true" {
+		failures, err := envInt("CIRCUIT_BREAKER_FAILURES", 5)
+		if err != nil {
+			return err
+		}
+
+		cooldown, err := envDuration("CIRCUIT_BREAKER_COOLDOWN", 30*time.Second)
+		if err != nil {
+			return err
+		}
+
+		breaker = repository.NewCircuitBreaker(failures, cooldown, logger)
+		jokeRepo = repository.NewCircuitBreakerJokeRepository(jokeRepo, breaker)
+	}
+
+	if os.Getenv("LOG_QUERY_TIMINGS") == "true" {
+		jokeRepo = repository.NewInstrumentedJokeRepository(jokeRepo, repository.LogObserver(logger))
+	}
+
+	// Report query timings in ?debug=true responses and the Server-Timing
+	// header. This is a no-op for requests that collect neither.
+	jokeRepo = repository.NewInstrumentedJokeRepository(jokeRepo, middleware.RecordQueryTiming)
+
+	// Pick random jokes from a cached list of IDs instead of scanning the
+	// table with ORDER BY RANDOM() on every request.
+	if os.Getenv("RANDOM_ID_CACHE") == "true" {
+		refreshInterval, err := envDuration("RANDOM_ID_CACHE_REFRESH_INTERVAL", time.Minute)
+		if err != nil {
+			return err
+		}
+
+		idCache := repository.NewRandomIDCacheJokeRepository(jokeRepo, logger)
+		background.Go(func() { idCache.Run(bgCtx, refreshInterval) })
+		jokeRepo = idCache
+	}
+
+	// Serve bursts of random joke requests from batches fetched with one
+	// query each.
+	if os.Getenv("RANDOM_BATCH_SIZE") != "" {
+		batchSize, err := envInt("RANDOM_BATCH_SIZE", 0)
+		if err != nil {
+			return err
+		}
+
+		batchMaxAge, err := envDuration("RANDOM_BATCH_MAX_AGE", 5*time.Second)
+		if err != nil {
+			return err
+		}
+
+		jokeRepo = repository.NewRandomBatchJokeRepository(jokeRepo, batchSize, batchMaxAge)
+	}
+
+	router := server.NewRouter(server.Deps{
+		Logger:                  logger,
+		Repo:                    jokeRepo,
+		FeatureFlagRepo:         repo,
+		MaintenanceRepo:         repo,
+		HealthRepo:              repo,
+		FeatureFlags:            flags,
+		Consistency:             checker,
+		AdminAPIKey:             adminApiKey,
+		AdminJWT:                adminJWT,
+		MaxURLLength:            maxURLLength,
+		BodyReadTimeout:         bodyReadTimeout,
+		MaxStreams:              maxStreams,
+		ContentSecurityPolicy:   csp,
+		CacheControl:            cacheControlPolicies,
+		JokeNotFound:            os.Getenv("JOKE_NOT_FOUND") == "true",
+		AllowOrigin:             reloadable.AllowOrigin,
+		CompressResponses:       os.Getenv("RESPONSE_COMPRESSION") == "true",
+		CompressionLevel:        compressionLevel,
+		AdminQueryKey:           os.Getenv("ADMIN_API_KEY_QUERY_PARAM") == "true",
+		ReadAPIKey:              readAPIKey,
+		ReadProtectedPaths:      readProtectedPaths,
+		AccessLogSampleEvery:    accessLogSampleEvery,
+		ServerTiming:            os.Getenv("SERVER_TIMING") == "true",
+		CircuitBreaker:          breaker,
+		ResponseCacheTTL:        responseCacheTTL,
+		RejectSuspiciousQueries: os.Getenv("REJECT_SUSPICIOUS_QUERIES") == "true",
+		StartedAt:               startedAt,
+		HandlerOptions: handler.Options{
+			BaseURL:       strings.TrimSuffix(os.Getenv("PUBLIC_BASE_URL"), "/"),
+			PublicIDs:     publicIDs,
+			RemoteSource:  remoteSource,
+			StrictParams:  os.Getenv("STRICT_QUERY_PARAMS") == "true",
+			Sanitize:      sanitizeMode,
+			Profanity:     profanityFilter,
+			ProfanityMode: profanityMode,
+			TellPause:     tellPause,
+			Views:         viewCounter,
+			UnicodeForm:   unicodeForm,
+
+			EmptyRandomFallback: strings.TrimSpace(os.Getenv("RANDOM_FALLBACK_JOKE")),
+			LogFailedBodyBytes:  logFailedBodyBytes,
+			PutCreatesMissing:   os.Getenv("PUT_CREATES_MISSING") == "true",
+			BackupDir:           backupDir,
+			MaxOffset:           maxOffset,
+
+			DuplicateSubmissionWindow: duplicateWindow,
+			NDJSONImportSkipInvalid:   os.Getenv("NDJSON_IMPORT_SKIP_INVALID") == "true",
+		},
+	})
+
+	srv := newHTTPServer(":"+port, router, maxHeaderBytes)
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	go func() {
+		for range hangup {
+			if err := reloadable.Reload(); err != nil {
+				logger.Error("Failed to reload configuration", slog.String("error", err.Error()))
+				continue
+			}
+			logger.Info("Reloaded configuration", "log_level", reloadable.LogLevel().Level())
+		}
+	}()
+
 	go func() {
 		log.Printf("Starting server on port: %s", ":"+port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -101,16 +492,68 @@ func run() error {
 		}
 	}()
 
-	<-stop
-	log.Println("Shutting down server...")
+	stopServer := &shutdown{
+		logger: logger,
+		run: func() error {
+			log.Println("Shutting down server...")
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			if err := srv.Shutdown(ctx); err != nil {
+				return fmt.Errorf("server forced to shutdown: %w", err)
+			}
+
+			// Let background workers finish what they are doing, within what
+			// is left of the shutdown timeout, before the database is closed.
+			stopBackground()
+			if err := background.Wait(ctx); err != nil {
+				return fmt.Errorf("background workers did not stop in time: %w", err)
+			}
+
+			log.Println("Server exited gracefully")
+			return nil
+		},
+	}
+
+	sig := <-stop
+	go func() {
+		for sig := range stop {
+			stopServer.handle(sig)
+		}
+	}()
+
+	return stopServer.handle(sig)
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// loadJWTVerifier configures bearer token admin auth from either an HMAC
+// secret or a PEM encoded public key file.
+func loadJWTVerifier() (*middleware.JWTVerifier, error) {
+	secret := os.Getenv("ADMIN_JWT_SECRET")
+	keyFile := os.Getenv("ADMIN_JWT_PUBLIC_KEY_FILE")
 
-	if err := srv.Shutdown(ctx); err != nil {
-		return fmt.Errorf("server forced to shutdown: %w", err)
+	var key any
+	switch {
+	case secret != "" && keyFile != "":
+		return nil, fmt.Errorf("set only one of ADMIN_JWT_SECRET and ADMIN_JWT_PUBLIC_KEY_FILE")
+	case secret != "":
+		key = []byte(secret)
+	case keyFile != "":
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ADMIN_JWT_PUBLIC_KEY_FILE: %w", err)
+		}
+
+		key, err = middleware.ParsePublicKeyPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ADMIN_JWT_PUBLIC_KEY_FILE: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("ADMIN_AUTH=%s requires ADMIN_JWT_SECRET or ADMIN_JWT_PUBLIC_KEY_FILE to be set", os.Getenv("ADMIN_AUTH"))
 	}
 
-	log.Println("Server exited gracefully")
-	return nil
+	return middleware.NewJWTVerifier(key, middleware.JWTOptions{
+		Issuer:   os.Getenv("ADMIN_JWT_ISSUER"),
+		Audience: os.Getenv("ADMIN_JWT_AUDIENCE"),
+	})
 }
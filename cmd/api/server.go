@@ -0,0 +1,13 @@
+package main
+
+import "net/http"
+
+// newHTTPServer returns the server for router. Requests whose headers exceed
+// maxHeaderBytes are answered with 431 Request Header Fields Too Large.
+func newHTTPServer(addr string, router http.Handler, maxHeaderBytes int) *http.Server {
+	return &http.Server{
+		Addr:           addr,
+		Handler:        router,
+		MaxHeaderBytes: maxHeaderBytes,
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxHeaderBytes(t *testing.T) {
+	t.Setenv("MAX_HEADER_BYTES", "1024")
+
+	maxHeaderBytes, err := envInt("MAX_HEADER_BYTES", http.DefaultMaxHeaderBytes)
+	if err != nil {
+		t.Fatalf("envInt: %v", err)
+	}
+
+	ts := httptest.NewUnstartedServer(nil)
+	ts.Config = newHTTPServer("", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), maxHeaderBytes)
+	ts.Start()
+	t.Cleanup(ts.Close)
+
+	send := func(headerSize int) int {
+		req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+		if err != nil {
+			t.Fatalf("building request: %v", err)
+		}
+		req.Header.Set("X-Padding", strings.Repeat("a", headerSize))
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("sending request: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := send(512); status != http.StatusOK {
+		t.Errorf("small headers: status = %d, want %d", status, http.StatusOK)
+	}
+	// net/http allows some slack on top of MaxHeaderBytes, so go well past it.
+	if status := send(16 << 10); status != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf("oversized headers: status = %d, want %d", status, http.StatusRequestHeaderFieldsTooLarge)
+	}
+}
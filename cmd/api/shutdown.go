@@ -0,0 +1,28 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"sync/atomic"
+)
+
+// shutdown runs the shutdown sequence for the first signal it is handed.
+// Signals that arrive while it is in progress, such as a second SIGTERM
+// from an impatient orchestrator, are logged and otherwise ignored, so they
+// can't interrupt it.
+type shutdown struct {
+	logger  *slog.Logger
+	run     func() error
+	started atomic.Bool
+}
+
+// handle runs the shutdown sequence and returns its error, or returns nil
+// right away if it has already started.
+func (s *shutdown) handle(sig os.Signal) error {
+	if !s.started.CompareAndSwap(false, true) {
+		s.logger.Warn("Shutdown already in progress, ignoring signal", slog.String("signal", sig.String()))
+		return nil
+	}
+
+	return s.run()
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestShutdownRunsOnce(t *testing.T) {
+	var logs bytes.Buffer
+	var runs atomic.Int32
+	release := make(chan struct{})
+
+	s := &shutdown{
+		logger: slog.New(slog.NewTextHandler(&logs, nil)),
+		run: func() error {
+			runs.Add(1)
+			<-release
+			return nil
+		},
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := s.handle(syscall.SIGTERM); err != nil {
+			t.Errorf("first handle: %v", err)
+		}
+	}()
+
+	// Wait for the first shutdown to be under way.
+	for runs.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A second signal mid-shutdown returns at once instead of running again.
+	if err := s.handle(syscall.SIGTERM); err != nil {
+		t.Errorf("second handle: %v", err)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if got := runs.Load(); got != 1 {
+		t.Errorf("shutdown ran %d times, want 1", got)
+	}
+	if !strings.Contains(logs.String(), "Shutdown already in progress") {
+		t.Errorf("second signal not logged: %q", logs.String())
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"log/slog"
+)
+
+// initOptional runs the initialization of a subsystem the API can serve
+// without, such as feature flags, which stay off until the next refresh.
+// Its error stops startup unless degrade is set; then it is logged as a
+// warning and startup carries on. Critical subsystems, the database above
+// all, are initialized directly so they always fail fast.
+func initOptional(name string, degrade bool, logger *slog.Logger, init func() error) error {
+	err := init()
+	if err == nil || !degrade {
+		return err
+	}
+
+	logger.Warn("Failed to initialize subsystem, starting without it",
+		slog.String("subsystem", name),
+		slog.String("error", err.Error()),
+	)
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestInitOptional(t *testing.T) {
+	failing := func() error { return errors.New("flags table missing") }
+
+	t.Run("fail fast", func(t *testing.T) {
+		var logs bytes.Buffer
+		err := initOptional("feature_flags", false, slog.New(slog.NewTextHandler(&logs, nil)), failing)
+		if err == nil || !strings.Contains(err.Error(), "flags table missing") {
+			t.Errorf("err = %v, want the init error", err)
+		}
+	})
+
+	t.Run("degraded", func(t *testing.T) {
+		var logs bytes.Buffer
+		if err := initOptional("feature_flags", true, slog.New(slog.NewTextHandler(&logs, nil)), failing); err != nil {
+			t.Fatalf("err = %v, want startup to continue", err)
+		}
+
+		out := logs.String()
+		if !strings.Contains(out, "level=WARN") || !strings.Contains(out, "subsystem=feature_flags") || !strings.Contains(out, "flags table missing") {
+			t.Errorf("log = %q, want a warning naming the subsystem and error", out)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		var logs bytes.Buffer
+		if err := initOptional("feature_flags", true, slog.New(slog.NewTextHandler(&logs, nil)), func() error { return nil }); err != nil {
+			t.Errorf("err = %v", err)
+		}
+		if logs.Len() > 0 {
+			t.Errorf("logged %q for a successful init", logs.String())
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type warmable interface {
+	Ping(ctx context.Context) error
+	CountJokes(ctx context.Context) (int, error)
+}
+
+// warmUp primes the database connections so the first request doesn't pay
+// for them, and logs readiness along with the time spent since startedAt.
+func warmUp(ctx context.Context, repo warmable, logger *slog.Logger, startedAt time.Time) error {
+	if err := repo.Ping(ctx); err != nil {
+		return fmt.Errorf("database is unreachable: %w", err)
+	}
+
+	count, err := repo.CountJokes(ctx)
+	if err != nil {
+		return fmt.Errorf("database is unreachable: %w", err)
+	}
+
+	logger.Info("Database ready", "jokes", count, "startup_duration", time.Since(startedAt))
+	return nil
+}
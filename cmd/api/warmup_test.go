@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestWarmUpLogsJokeCount(t *testing.T) {
+	repo, err := repository.NewSQLiteJokeRepository(filepath.Join(t.TempDir(), "jokes.db"), repository.Options{})
+	if err != nil {
+		t.Fatalf("creating repository: %v", err)
+	}
+	defer repo.Close()
+
+	for _, text := range []string{"one", "two"} {
+		if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: text}); err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+	}
+
+	var logs bytes.Buffer
+	if err := warmUp(context.Background(), repo, slog.New(slog.NewTextHandler(&logs, nil)), time.Now()); err != nil {
+		t.Fatalf("warmUp: %v", err)
+	}
+
+	if !strings.Contains(logs.String(), "jokes=2") || !strings.Contains(logs.String(), "startup_duration=") {
+		t.Errorf("readiness log = %q, want joke count and startup duration", logs.String())
+	}
+}
+
+func TestWarmUpFailsOnUnreachableDatabase(t *testing.T) {
+	repo, err := repository.NewSQLiteJokeRepository(filepath.Join(t.TempDir(), "jokes.db"), repository.Options{})
+	if err != nil {
+		t.Fatalf("creating repository: %v", err)
+	}
+	repo.Close()
+
+	var logs bytes.Buffer
+	err = warmUp(context.Background(), repo, slog.New(slog.NewTextHandler(&logs, nil)), time.Now())
+	if err == nil || !strings.Contains(err.Error(), "database is unreachable") {
+		t.Fatalf("warmUp error = %v, want database is unreachable", err)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("logged readiness for an unreachable database: %q", logs.String())
+	}
+}
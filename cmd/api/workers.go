@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// workers tracks background goroutines so shutdown can wait for them to
+// finish their current work.
+type workers struct {
+	wg sync.WaitGroup
+}
+
+// Go runs fn in a new tracked goroutine.
+func (w *workers) Go(fn func()) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		fn()
+	}()
+}
+
+// Wait blocks until all tracked goroutines have returned or ctx is done,
+// whichever comes first.
+func (w *workers) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
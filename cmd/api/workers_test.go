@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkersWaitForInFlightWork(t *testing.T) {
+	var background workers
+	var finished atomic.Bool
+
+	ctx, stop := context.WithCancel(context.Background())
+	background.Go(func() {
+		<-ctx.Done()
+		// Work that is still in flight when shutdown starts.
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(true)
+	})
+
+	stop()
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := background.Wait(waitCtx); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if !finished.Load() {
+		t.Error("Wait returned before the worker finished")
+	}
+}
+
+func TestWorkersWaitGivesUpAtDeadline(t *testing.T) {
+	var background workers
+	block := make(chan struct{})
+	defer close(block)
+
+	background.Go(func() { <-block })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := background.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+	"golang.org/x/text/unicode/norm"
+)
+
+// newFlagSet returns a flag set for a subcommand that reports errors
+// instead of exiting, so run can return them.
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: jokectl %s %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func listCommand(ctx context.Context, repo repository.JokeRepository, args []string, out io.Writer) error {
+	fs := newFlagSet("list", "[-limit n] [-offset n] [-json]")
+	limit := fs.Int("limit", 0, "maximum number of jokes to list, 0 for all")
+	offset := fs.Int("offset", 0, "number of jokes to skip")
+	asJSON := fs.Bool("json", false, "print one JSON object per line")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *limit < 0 || *offset < 0 {
+		return errors.New("limit and offset must not be negative")
+	}
+
+	enc := json.NewEncoder(out)
+	return repo.StreamJokes(ctx, *limit, *offset, func(joke *model.Joke) error {
+		if *asJSON {
+			return enc.Encode(joke)
+		}
+
+		_, err := fmt.Fprintf(out, "%d\t%s\n", joke.ID, strings.ReplaceAll(joke.Text, "\n", " "))
+		return err
+	})
+}
+
+func addCommand(ctx context.Context, repo repository.JokeRepository, args []string, out io.Writer) error {
+	fs := newFlagSet("add", "-text text | -setup text -punchline text")
+	text := fs.String("text", "", "joke text")
+	setup := fs.String("setup", "", "setup of a two-part joke")
+	punchline := fs.String("punchline", "", "punchline of a two-part joke")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	joke, err := newJoke(*text, *setup, *punchline)
+	if err != nil {
+		return err
+	}
+
+	results, err := repo.GetOrCreateJokes(ctx, []*model.Joke{joke})
+	if err != nil {
+		return fmt.Errorf("failed to add joke: %w", err)
+	}
+
+	if !results[0].Created {
+		fmt.Fprintf(out, "joke %d already exists\n", results[0].Joke.ID)
+		return nil
+	}
+
+	fmt.Fprintf(out, "added joke %d\n", results[0].Joke.ID)
+	return nil
+}
+
+// newJoke builds a joke from either text or a setup and punchline, the same
+// way the API does for create requests.
+func newJoke(text, setup, punchline string) (*model.Joke, error) {
+	text, setup, punchline = cleanText(text), cleanText(setup), cleanText(punchline)
+
+	switch {
+	case setup == "" && punchline == "":
+		if text == "" {
+			return nil, errors.New("joke text is required")
+		}
+		return &model.Joke{Text: text}, nil
+	case text != "":
+		return nil, errors.New("provide either -text or -setup and -punchline, not both")
+	case setup == "" || punchline == "":
+		return nil, errors.New("setup and punchline are both required")
+	}
+
+	return &model.Joke{Text: setup + " " + punchline, Setup: setup, Punchline: punchline}, nil
+}
+
+// cleanText trims text and brings it into NFC, the API's default
+// normalization form.
+func cleanText(text string) string {
+	return strings.TrimSpace(norm.NFC.String(text))
+}
+
+func deleteCommand(ctx context.Context, repo repository.JokeRepository, args []string, out io.Writer) error {
+	fs := newFlagSet("delete", "-id n")
+	id := fs.Int64("id", 0, "ID of the joke to delete")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *id <= 0 {
+		return errors.New("a positive -id is required")
+	}
+
+	if err := repo.DeleteJoke(ctx, *id); err != nil {
+		if errors.Is(err, repository.ErrJokeNotFound) {
+			return fmt.Errorf("joke %d not found", *id)
+		}
+		return fmt.Errorf("failed to delete joke: %w", err)
+	}
+
+	fmt.Fprintf(out, "deleted joke %d\n", *id)
+	return nil
+}
+
+func importCommand(ctx context.Context, repo repository.JokeRepository, args []string, out io.Writer) error {
+	fs := newFlagSet("import", "file")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: jokectl import file")
+		fmt.Fprintln(fs.Output(), "\nA .json file holds an array of joke texts; any other file one joke per line.")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	texts, err := readImportFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var inserted, skipped int
+	seen := make(map[string]bool, len(texts))
+
+	for _, text := range texts {
+		text = cleanText(text)
+		if text == "" || seen[text] {
+			skipped++
+			continue
+		}
+		seen[text] = true
+
+		exists, err := repo.JokeTextExists(ctx, text)
+		if err != nil {
+			return fmt.Errorf("failed to check for duplicate joke: %w", err)
+		}
+		if exists {
+			skipped++
+			continue
+		}
+
+		if _, err := repo.CreateJoke(ctx, &model.Joke{Text: text}); err != nil {
+			return fmt.Errorf("failed to import joke after %d inserted: %w", inserted, err)
+		}
+		inserted++
+	}
+
+	fmt.Fprintf(out, "inserted %d jokes, skipped %d\n", inserted, skipped)
+	return nil
+}
+
+// readImportFile reads joke texts from a JSON array of strings, the format
+// of the embedded seed jokes, or from a plain text file with one joke per
+// line.
+func readImportFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import file: %w", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var texts []string
+		if err := json.Unmarshal(data, &texts); err != nil {
+			return nil, fmt.Errorf("failed to decode import file, expected an array of strings: %w", err)
+		}
+		return texts, nil
+	}
+
+	return strings.Split(string(data), "\n"), nil
+}
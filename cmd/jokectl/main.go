@@ -0,0 +1,79 @@
+// Command jokectl manages the jokes database directly, without going
+// through the HTTP API, for cron jobs, migrations and one-off fixes.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+const usage = `Usage: jokectl [-db path] <command> [flags]
+
+Commands:
+  list     list jokes
+  add      add a joke
+  delete   delete a joke by ID
+  import   import jokes from a file
+
+Run "jokectl <command> -h" for the flags of a command.
+`
+
+// command is a jokectl subcommand. It parses its own flags from args.
+type command func(ctx context.Context, repo repository.JokeRepository, args []string, out io.Writer) error
+
+var commands = map[string]command{
+	"list":   listCommand,
+	"add":    addCommand,
+	"delete": deleteCommand,
+	"import": importCommand,
+}
+
+func main() {
+	err := run(context.Background(), os.Args[1:], os.Stdout, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jokectl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run parses the global flags, opens the database and runs the command
+// named in args.
+func run(ctx context.Context, args []string, out, errOut io.Writer) error {
+	fs := flag.NewFlagSet("jokectl", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fs.Usage = func() { fmt.Fprint(errOut, usage) }
+
+	dbPath := fs.String("db", "./jokes.db", "path to the SQLite database")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(errOut, "unknown command %q\n\n", fs.Arg(0))
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	repo, err := repository.NewSQLiteJokeRepository(*dbPath, repository.Options{})
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer repo.Close()
+
+	return cmd(ctx, repo, fs.Args()[1:], out)
+}
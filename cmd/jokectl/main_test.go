@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// runJokectl runs jokectl against the database at dbPath and returns its
+// output.
+func runJokectl(t *testing.T, dbPath string, args ...string) (string, error) {
+	t.Helper()
+
+	var out, errOut bytes.Buffer
+	err := run(context.Background(), append([]string{"-db", dbPath}, args...), &out, &errOut)
+	return out.String(), err
+}
+
+// jokeTexts opens the database at dbPath and returns the texts of all jokes.
+func jokeTexts(t *testing.T, dbPath string) []string {
+	t.Helper()
+
+	repo, err := repository.NewSQLiteJokeRepository(dbPath, repository.Options{})
+	if err != nil {
+		t.Fatalf("opening repository: %v", err)
+	}
+	defer repo.Close()
+
+	var texts []string
+	err = repo.StreamJokes(context.Background(), 0, 0, func(joke *model.Joke) error {
+		texts = append(texts, joke.Text)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("listing jokes: %v", err)
+	}
+
+	return texts
+}
+
+func TestAddListDelete(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "jokes.db")
+
+	if out, err := runJokectl(t, dbPath, "add", "-text", "  A plain joke "); err != nil || out != "added joke 1\n" {
+		t.Fatalf("add = %q, %v", out, err)
+	}
+	if out, err := runJokectl(t, dbPath, "add", "-text", "a PLAIN joke"); err != nil || out != "joke 1 already exists\n" {
+		t.Fatalf("add existing = %q, %v", out, err)
+	}
+	if _, err := runJokectl(t, dbPath, "add", "-setup", "Knock knock.", "-punchline", "Who's there?"); err != nil {
+		t.Fatalf("add two-part: %v", err)
+	}
+
+	want := []string{"A plain joke", "Knock knock. Who's there?"}
+	if got := jokeTexts(t, dbPath); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("jokes after add = %q, want %q", got, want)
+	}
+
+	out, err := runJokectl(t, dbPath, "list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if want := "1\tA plain joke\n2\tKnock knock. Who's there?\n"; out != want {
+		t.Errorf("list = %q, want %q", out, want)
+	}
+
+	out, err = runJokectl(t, dbPath, "list", "-json", "-offset", "1")
+	if err != nil {
+		t.Fatalf("list -json: %v", err)
+	}
+	if !strings.Contains(out, `"punchline":"Who's there?"`) || strings.Count(out, "\n") != 1 {
+		t.Errorf("list -json -offset 1 = %q, want only the two-part joke", out)
+	}
+
+	if _, err := runJokectl(t, dbPath, "delete", "-id", "1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if got := jokeTexts(t, dbPath); len(got) != 1 || got[0] != want[1] {
+		t.Errorf("jokes after delete = %q, want only %q", got, want[1])
+	}
+
+	if _, err := runJokectl(t, dbPath, "delete", "-id", "1"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("deleting a missing joke = %v, want not found", err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "jokes.db")
+
+	if _, err := runJokectl(t, dbPath, "add", "-text", "Already here"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	jsonFile := filepath.Join(dir, "jokes.json")
+	if err := os.WriteFile(jsonFile, []byte(`["Already here", "From JSON", "From JSON", " "]`), 0o644); err != nil {
+		t.Fatalf("writing import file: %v", err)
+	}
+
+	out, err := runJokectl(t, dbPath, "import", jsonFile)
+	if err != nil {
+		t.Fatalf("import json: %v", err)
+	}
+	if out != "inserted 1 jokes, skipped 3\n" {
+		t.Errorf("import json = %q", out)
+	}
+
+	textFile := filepath.Join(dir, "jokes.txt")
+	if err := os.WriteFile(textFile, []byte("From text one\n\nFrom text two\n"), 0o644); err != nil {
+		t.Fatalf("writing import file: %v", err)
+	}
+
+	if _, err := runJokectl(t, dbPath, "import", textFile); err != nil {
+		t.Fatalf("import text: %v", err)
+	}
+
+	want := "Already here|From JSON|From text one|From text two"
+	if got := strings.Join(jokeTexts(t, dbPath), "|"); got != want {
+		t.Errorf("jokes after import = %q, want %q", got, want)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "jokes.db")
+
+	for _, args := range [][]string{
+		{},
+		{"frobnicate"},
+		{"add"},
+		{"add", "-text", "x", "-setup", "y", "-punchline", "z"},
+		{"delete"},
+		{"import"},
+	} {
+		if _, err := runJokectl(t, dbPath, args...); err == nil {
+			t.Errorf("jokectl %q succeeded, want an error", args)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Package backup periodically writes all jokes to timestamped JSON files,
+// keeping only the most recent ones.
+package backup
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+const (
+	filePrefix = "jokes-"
+	fileSuffix = ".json"
+
+	// timeLayout sorts lexically in time order, which pruning relies on.
+	timeLayout = "20060102T150405Z"
+)
+
+// Store yields every joke for a backup.
+type Store interface {
+	EachJoke(ctx context.Context, fn func(*model.Joke) error) error
+}
+
+// Writer writes backups to dir and prunes all but the newest keep of them.
+type Writer struct {
+	store  Store
+	dir    string
+	keep   int
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+func NewWriter(store Store, dir string, keep int, logger *slog.Logger) *Writer {
+	return &Writer{
+		store:  store,
+		dir:    dir,
+		keep:   keep,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Backup writes all jokes to a new file, in the same format as the admin
+// export, and returns its path. The file only appears under its final name
+// once it is complete, so an interrupted backup never looks like a good
+// one. Older backups beyond the retention are deleted afterwards.
+func (b *Writer) Backup(ctx context.Context) (string, error) {
+	if err := os.MkdirAll(b.dir, 0o755); err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp(b.dir, ".backup-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	out := bufio.NewWriter(file)
+	if err := b.write(ctx, out); err != nil {
+		return "", err
+	}
+	if err := out.Flush(); err != nil {
+		return "", err
+	}
+	if err := file.Sync(); err != nil {
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(b.dir, filePrefix+b.now().UTC().Format(timeLayout)+fileSuffix)
+	if err := os.Rename(file.Name(), path); err != nil {
+		return "", err
+	}
+
+	if err := b.prune(); err != nil {
+		return path, fmt.Errorf("pruning old backups: %w", err)
+	}
+
+	return path, nil
+}
+
+// write writes all jokes to out as a JSON array.
+func (b *Writer) write(ctx context.Context, out io.Writer) error {
+	if _, err := io.WriteString(out, "["); err != nil {
+		return err
+	}
+
+	first := true
+	err := b.store.EachJoke(ctx, func(joke *model.Joke) error {
+		if !first {
+			if _, err := io.WriteString(out, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		data, err := json.Marshal(joke)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(out, "]\n")
+	return err
+}
+
+// prune deletes all but the newest keep backups. Other files in the
+// directory are left alone.
+func (b *Writer) prune() error {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasPrefix(name, filePrefix) && strings.HasSuffix(name, fileSuffix) {
+			backups = append(backups, name)
+		}
+	}
+
+	slices.Sort(backups)
+	for len(backups) > b.keep {
+		if err := os.Remove(filepath.Join(b.dir, backups[0])); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+// Run writes a backup every interval until ctx is cancelled. A backup that
+// is running when ctx is cancelled is abandoned.
+func (b *Writer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			path, err := b.Backup(ctx)
+			if path != "" {
+				var size int64
+				if info, err := os.Stat(path); err == nil {
+					size = info.Size()
+				}
+				b.logger.Info("Backed up jokes", "path", path, "bytes", size)
+			}
+			if err != nil && ctx.Err() == nil {
+				b.logger.Error("Failed to back up jokes", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+type fakeStore []*model.Joke
+
+func (s fakeStore) EachJoke(ctx context.Context, fn func(*model.Joke) error) error {
+	for _, joke := range s {
+		if err := fn(joke); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestBackupWritesJokes(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "backups")
+	store := fakeStore{
+		{ID: 1, Text: "first joke"},
+		{ID: 2, Text: "second joke"},
+	}
+
+	w := NewWriter(store, dir, 3, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	w.now = func() time.Time { return time.Date(2024, 5, 15, 12, 30, 0, 0, time.UTC) }
+
+	path, err := w.Backup(context.Background())
+	if err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	if want := filepath.Join(dir, "jokes-20240515T123000Z.json"); path != want {
+		t.Errorf("path = %q, want %q", path, want)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading backup: %v", err)
+	}
+
+	var jokes []model.Joke
+	if err := json.Unmarshal(data, &jokes); err != nil {
+		t.Fatalf("decoding backup: %v", err)
+	}
+	if len(jokes) != 2 || jokes[0].ID != 1 || jokes[0].Text != "first joke" || jokes[1].ID != 2 || jokes[1].Text != "second joke" {
+		t.Errorf("backup = %+v, want both jokes", jokes)
+	}
+
+	// No temporary files are left behind.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("reading backup dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("backup dir has %d entries, want 1", len(entries))
+	}
+}
+
+func TestBackupPrunesOldBackups(t *testing.T) {
+	dir := t.TempDir()
+
+	// Files that aren't backups must survive pruning.
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep me"), 0o644); err != nil {
+		t.Fatalf("writing unrelated file: %v", err)
+	}
+
+	w := NewWriter(fakeStore{{ID: 1, Text: "joke"}}, dir, 2, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	at := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		w.now = func() time.Time { return at.Add(time.Duration(i) * time.Hour) }
+		if _, err := w.Backup(context.Background()); err != nil {
+			t.Fatalf("Backup %d: %v", i, err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("reading backup dir: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	want := []string{"jokes-20240515T020000Z.json", "jokes-20240515T030000Z.json", "notes.txt"}
+	if !slices.Equal(names, want) {
+		t.Errorf("backup dir = %v, want %v", names, want)
+	}
+}
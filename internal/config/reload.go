@@ -0,0 +1,86 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/joho/godotenv"
+)
+
+// Reloadable holds the settings that can be changed while the server is
+// running by editing the env file and sending SIGHUP. Secrets and the
+// database path are deliberately not part of it; they require a restart.
+type Reloadable struct {
+	envFile     string
+	logLevel    slog.LevelVar
+	corsOrigins atomic.Pointer[[]string]
+}
+
+// NewReloadable loads the initial settings. Values from envFile take
+// precedence over the process environment; a missing file is not an error.
+func NewReloadable(envFile string) (*Reloadable, error) {
+	r := &Reloadable{envFile: envFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload re-reads the settings and applies them. Nothing is applied unless
+// all settings are valid.
+func (r *Reloadable) Reload() error {
+	lookup := os.Getenv
+
+	values, err := godotenv.Read(r.envFile)
+	if err == nil {
+		lookup = func(key string) string {
+			if v, ok := values[key]; ok {
+				return v
+			}
+			return os.Getenv(key)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error reading %s: %w", r.envFile, err)
+	}
+
+	var level slog.Level
+	if v := lookup("LOG_LEVEL"); v != "" {
+		if err := level.UnmarshalText([]byte(v)); err != nil {
+			return fmt.Errorf("invalid LOG_LEVEL: %q", v)
+		}
+	}
+
+	origins := []string{"*"}
+	if v := lookup("CORS_ALLOWED_ORIGINS"); v != "" {
+		origins = origins[:0]
+		for _, origin := range strings.Split(v, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				origins = append(origins, origin)
+			}
+		}
+	}
+
+	r.logLevel.Set(level)
+	r.corsOrigins.Store(&origins)
+
+	return nil
+}
+
+// LogLevel is the level to pass to slog handlers; it follows reloads.
+func (r *Reloadable) LogLevel() *slog.LevelVar {
+	return &r.logLevel
+}
+
+// AllowOrigin reports whether CORS requests from origin are allowed.
+func (r *Reloadable) AllowOrigin(origin string) bool {
+	origins := *r.corsOrigins.Load()
+
+	return slices.Contains(origins, "*") || slices.Contains(origins, origin)
+}
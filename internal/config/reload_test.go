@@ -0,0 +1,73 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeEnvFile(t *testing.T, path, contents string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("writing env file: %v", err)
+	}
+}
+
+func TestReloadableReload(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "")
+
+	envFile := filepath.Join(t.TempDir(), ".env")
+	writeEnvFile(t, envFile, "LOG_LEVEL=warn\nCORS_ALLOWED_ORIGINS=https://a.example, https://b.example\n")
+
+	r, err := NewReloadable(envFile)
+	if err != nil {
+		t.Fatalf("NewReloadable: %v", err)
+	}
+	if level := r.LogLevel().Level(); level != slog.LevelWarn {
+		t.Errorf("log level = %v, want %v", level, slog.LevelWarn)
+	}
+	if !r.AllowOrigin("https://b.example") || r.AllowOrigin("https://c.example") {
+		t.Error("origins not loaded from the env file")
+	}
+
+	// What SIGHUP does: pick up the edited file.
+	writeEnvFile(t, envFile, "LOG_LEVEL=debug\nCORS_ALLOWED_ORIGINS=https://c.example\n")
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if level := r.LogLevel().Level(); level != slog.LevelDebug {
+		t.Errorf("log level after reload = %v, want %v", level, slog.LevelDebug)
+	}
+	if r.AllowOrigin("https://a.example") || !r.AllowOrigin("https://c.example") {
+		t.Error("origins not replaced on reload")
+	}
+
+	// An invalid file is rejected as a whole.
+	writeEnvFile(t, envFile, "LOG_LEVEL=loud\nCORS_ALLOWED_ORIGINS=https://d.example\n")
+	if err := r.Reload(); err == nil {
+		t.Fatal("Reload accepted an invalid LOG_LEVEL")
+	}
+	if level := r.LogLevel().Level(); level != slog.LevelDebug {
+		t.Errorf("log level after failed reload = %v, want %v", level, slog.LevelDebug)
+	}
+	if r.AllowOrigin("https://d.example") || !r.AllowOrigin("https://c.example") {
+		t.Error("origins changed by a failed reload")
+	}
+
+	// Without the file the process environment and defaults apply.
+	if err := os.Remove(envFile); err != nil {
+		t.Fatalf("removing env file: %v", err)
+	}
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload without env file: %v", err)
+	}
+	if level := r.LogLevel().Level(); level != slog.LevelInfo {
+		t.Errorf("default log level = %v, want %v", level, slog.LevelInfo)
+	}
+	if !r.AllowOrigin("https://anything.example") {
+		t.Error("default should allow all origins")
+	}
+}
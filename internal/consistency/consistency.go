@@ -0,0 +1,56 @@
+package consistency
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// Checker removes orphaned join table rows, either periodically via Run or
+// on demand via Check.
+type Checker struct {
+	repo   repository.MaintenanceRepository
+	logger *slog.Logger
+}
+
+func NewChecker(repo repository.MaintenanceRepository, logger *slog.Logger) *Checker {
+	return &Checker{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Check deletes orphaned rows and returns how many were deleted per table.
+func (c *Checker) Check(ctx context.Context) (map[string]int64, error) {
+	deleted, err := c.repo.DeleteOrphanedRows(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for table, n := range deleted {
+		if n > 0 {
+			c.logger.Warn("Deleted orphaned rows", "table", table, "count", n)
+		}
+	}
+
+	return deleted, nil
+}
+
+// Run checks every interval until ctx is cancelled.
+func (c *Checker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := c.Check(ctx); err != nil {
+				c.logger.Error("Failed to delete orphaned rows", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package featureflag
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// Store keeps an in-memory copy of the feature flags so checking a flag on
+// every request doesn't hit the database. Unknown flags are disabled.
+type Store struct {
+	repo  repository.FeatureFlagRepository
+	mu    sync.RWMutex
+	flags map[string]bool
+}
+
+func NewStore(repo repository.FeatureFlagRepository) *Store {
+	return &Store{
+		repo:  repo,
+		flags: make(map[string]bool),
+	}
+}
+
+func (s *Store) Enabled(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.flags[name]
+}
+
+// Refresh reloads all flags from the repository.
+func (s *Store) Refresh(ctx context.Context) error {
+	flags, err := s.repo.ListFeatureFlags(ctx)
+	if err != nil {
+		return err
+	}
+
+	next := make(map[string]bool, len(flags))
+	for _, flag := range flags {
+		next[flag.Name] = flag.Enabled
+	}
+
+	s.mu.Lock()
+	s.flags = next
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Run refreshes the flags every interval until ctx is cancelled.
+func (s *Store) Run(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil {
+				logger.Error("Failed to refresh feature flags", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
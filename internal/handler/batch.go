@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// Statuses of an applied batch item.
+const (
+	batchStatusCreated  = "created"
+	batchStatusExisting = "existing"
+)
+
+// BatchResult is the response shape shared by batch endpoints. Every item
+// of the request ends up in exactly one of the two lists.
+type BatchResult struct {
+	Succeeded []BatchItem    `json:"succeeded"`
+	Failed    []BatchFailure `json:"failed"`
+}
+
+// BatchItem is an item of a batch request that was applied.
+type BatchItem struct {
+	// ID is the item's position in the request: its index in a JSON array,
+	// or its line number in an NDJSON stream.
+	ID     int         `json:"id"`
+	Status string      `json:"status"`
+	Joke   *model.Joke `json:"joke"`
+}
+
+// BatchFailure is an item of a batch request that was not applied.
+type BatchFailure struct {
+	// ID is the item's position in the request, like BatchItem.ID.
+	ID    int    `json:"id"`
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// newBatchResult returns a BatchResult that renders empty lists rather
+// than null.
+func newBatchResult() BatchResult {
+	return BatchResult{Succeeded: []BatchItem{}, Failed: []BatchFailure{}}
+}
+
+// succeed records an applied item.
+func (b *BatchResult) succeed(id int, result repository.UpsertResult) {
+	status := batchStatusExisting
+	if result.Created {
+		status = batchStatusCreated
+	}
+
+	b.Succeeded = append(b.Succeeded, BatchItem{ID: id, Status: status, Joke: result.Joke})
+}
+
+// fail records an item that was not applied.
+func (b *BatchResult) fail(id int, code, message string) {
+	b.Failed = append(b.Failed, BatchFailure{ID: id, Error: message, Code: code})
+}
+
+// status is the overall status of a batch response: 207 Multi-Status when
+// some items failed, 200 otherwise.
+func (b *BatchResult) status() int {
+	if len(b.Failed) > 0 {
+		return http.StatusMultiStatus
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Error codes returned alongside the message when a request body can't be
+// decoded, so clients can tell a missing body from a broken one.
+const (
+	errCodeEmptyBody   = "empty_body"
+	errCodeInvalidJSON = "invalid_json"
+	errCodeBodyTimeout = "body_timeout"
+)
+
+// decodeJSONBody decodes the request body into dst. On failure it writes a
+// 400 response and returns false.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst any) bool {
+	return decodeJSONBodyLogged(w, r, dst, nil, 0)
+}
+
+// decodeJSONBodyLogged is decodeJSONBody, but when the body can't be decoded
+// it also logs up to logBytes bytes of it at debug level, so it's possible to
+// see what a client actually sent. The body is captured while the decoder
+// reads it, so nothing is read twice. A logBytes of 0 logs nothing.
+func decodeJSONBodyLogged(w http.ResponseWriter, r *http.Request, dst any, logger *slog.Logger, logBytes int) bool {
+	body := io.Reader(r.Body)
+
+	var captured *cappedBuffer
+	if logger != nil && logBytes > 0 {
+		captured = &cappedBuffer{limit: logBytes}
+		body = io.TeeReader(r.Body, captured)
+	}
+
+	err := json.NewDecoder(body).Decode(dst)
+	if err == nil {
+		return true
+	}
+
+	if captured != nil {
+		// The decoder stops at the first error; read on so the log shows
+		// as much of the body as the limit allows.
+		io.Copy(io.Discard, io.LimitReader(body, int64(logBytes)))
+
+		logger.Debug("Failed to decode request body",
+			slog.String("error", err.Error()),
+			slog.String("body", captured.buf.String()),
+			slog.Bool("body_truncated", captured.truncated),
+		)
+	}
+
+	switch {
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		// The client didn't send the body within the read timeout.
+		w.Header().Set("Connection", "close")
+		respondWithErrorCode(w, r, http.StatusRequestTimeout, errCodeBodyTimeout, "Timed out reading the request body")
+	case errors.Is(err, io.EOF):
+		// Decode reports io.EOF only when the body holds nothing but
+		// whitespace; a truncated document is io.ErrUnexpectedEOF.
+		respondWithErrorCode(w, r, http.StatusBadRequest, errCodeEmptyBody, "request body is required")
+	default:
+		respondWithErrorCode(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid request payload")
+	}
+
+	return false
+}
+
+// cappedBuffer keeps the first limit bytes written to it and drops the rest.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); len(p) > room {
+		b.buf.Write(p[:room])
+		b.truncated = true
+	} else {
+		b.buf.Write(p)
+	}
+
+	// Report everything as written so the tee keeps going.
+	return len(p), nil
+}
+
+func respondWithErrorCode(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	respondWithJSON(w, status, ErrorResponse{
+		Code:      code,
+		Error:     message,
+		RequestID: middleware.GetReqID(r.Context()),
+	})
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSONBodyLogsFailedBodies(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	t.Run("decode error", func(t *testing.T) {
+		logs.Reset()
+
+		body := `{"text": "this body is cut off` + strings.Repeat(" and goes on", 100)
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/joke", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+
+		var dst CreateJokeRequest
+		if decodeJSONBodyLogged(rec, req, &dst, logger, 32) {
+			t.Fatal("decoding succeeded, want an error")
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+		}
+
+		got := logs.String()
+		if !strings.Contains(got, "level=DEBUG") || !strings.Contains(got, "Failed to decode request body") {
+			t.Fatalf("logs = %q, want a debug entry for the failed body", got)
+		}
+		if want := `body="` + strings.ReplaceAll(body[:32], `"`, `\"`) + `"`; !strings.Contains(got, want) {
+			t.Errorf("logs = %q, want them to contain %s", got, want)
+		}
+		if !strings.Contains(got, "body_truncated=true") {
+			t.Errorf("logs = %q, want body_truncated=true", got)
+		}
+		if strings.Contains(got, body[:33]) {
+			t.Errorf("logs = %q, want the body cut at 32 bytes", got)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		logs.Reset()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/joke", strings.NewReader(`{"text": "`+strings.Repeat("long joke ", 20)+`"}`))
+		rec := httptest.NewRecorder()
+
+		var dst CreateJokeRequest
+		if !decodeJSONBodyLogged(rec, req, &dst, logger, 32) {
+			t.Fatalf("decoding failed: %s", rec.Body.String())
+		}
+
+		// Capturing must not cut the body short for the handler.
+		if want := strings.Repeat("long joke ", 20); dst.Text != want {
+			t.Errorf("text = %q, want %q", dst.Text, want)
+		}
+		if logs.Len() != 0 {
+			t.Errorf("logs = %q, want none", logs.String())
+		}
+	})
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+)
+
+// FindJokesByText handles GET /api/admin/joke/by-text. It lists every joke
+// whose text matches ?text= ignoring case and whitespace differences, as
+// an empty array when there are none.
+func (h *JokeHandler) FindJokesByText(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r, "text", "time_format") || !allowTimeFormat(w, r) {
+		return
+	}
+
+	text := r.URL.Query().Get("text")
+	if strings.TrimSpace(text) == "" {
+		respondWithError(w, r, http.StatusBadRequest, "Query parameter text is required")
+		return
+	}
+
+	jokes, err := h.repo.FindJokesByText(r.Context(), text)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve jokes")
+		return
+	}
+
+	applyTimeFormat(r, jokes...)
+	respondWithJSON(w, http.StatusOK, jokes)
+}
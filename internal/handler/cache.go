@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// jokeETag derives a strong ETag from the joke's identity and last update.
+func jokeETag(joke *model.Joke) string {
+	return fmt.Sprintf(`"%s-%d"`, jokeRef(joke), joke.UpdatedAt.UnixNano())
+}
+
+// setJokeCacheHeaders sets the validators clients need to cache a single joke.
+func setJokeCacheHeaders(w http.ResponseWriter, joke *model.Joke) {
+	w.Header().Set("ETag", jokeETag(joke))
+	w.Header().Set("Last-Modified", joke.UpdatedAt.UTC().Format(http.TimeFormat))
+}
+
+// notModified reports whether the request's preconditions match a
+// representation with the given ETag and modification time. As RFC 9110
+// asks, If-Modified-Since is ignored when If-None-Match is present.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || modified.IsZero() {
+		return false
+	}
+	// Last-Modified only has second precision.
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// respondNotModified answers a conditional GET whose preconditions matched,
+// reporting whether the response is complete. By default that is a 304.
+// Clients that would rather not handle 304s send Prefer: no-304 and get a
+// 200 instead: with the full body, for which false is returned so the
+// caller writes it, or with an empty one when they also prefer
+// return=minimal.
+func respondNotModified(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Add("Vary", "Prefer")
+
+	if !hasPreference(r, "no-304") {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+
+	if value, _ := preferenceValue(r, "return"); strings.EqualFold(value, "minimal") {
+		w.Header().Set("Preference-Applied", "no-304, return=minimal")
+		w.WriteHeader(http.StatusOK)
+		return true
+	}
+
+	w.Header().Set("Preference-Applied", "no-304")
+	return false
+}
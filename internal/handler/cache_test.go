@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestWriteValidators(t *testing.T) {
+	h, _ := newTestJokeHandler(t, Options{})
+
+	rec := serveRoute(h.CreateJoke, http.MethodPost, "/joke", "/joke", `{"text":"knock knock"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, want %d (body %s)", rec.Code, http.StatusCreated, rec.Body)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/api/joke/1" {
+		t.Errorf("Location = %q, want /api/joke/1", loc)
+	}
+	created := rec.Header().Get("ETag")
+	if created == "" {
+		t.Fatal("create response has no ETag")
+	}
+	if _, err := http.ParseTime(rec.Header().Get("Last-Modified")); err != nil {
+		t.Errorf("create Last-Modified %q: %v", rec.Header().Get("Last-Modified"), err)
+	}
+
+	rec = serveRoute(h.UpdateJoke, http.MethodPut, "/joke/{id}", "/joke/1", `{"text":"who's there?"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, want %d (body %s)", rec.Code, http.StatusOK, rec.Body)
+	}
+	if updated := rec.Header().Get("ETag"); updated == "" || updated == created {
+		t.Errorf("update ETag = %q, want a new one (created %q)", updated, created)
+	}
+	if _, err := http.ParseTime(rec.Header().Get("Last-Modified")); err != nil {
+		t.Errorf("update Last-Modified %q: %v", rec.Header().Get("Last-Modified"), err)
+	}
+}
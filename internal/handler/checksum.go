@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+type ChecksumResponse struct {
+	Checksum string `json:"checksum"`
+	Count    int    `json:"count"`
+}
+
+// GetJokesChecksum handles GET /api/joke/checksum. Clients that cache the
+// whole collection compare the checksum with the one they synced against
+// to find out whether anything changed. The checksum doubles as the ETag.
+func (h *JokeHandler) GetJokesChecksum(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r) {
+		return
+	}
+
+	checksum, count, err := h.repo.JokesChecksum(r.Context())
+	if err != nil {
+		h.log(r).Error("Failed to compute jokes checksum", slog.String("error", err.Error()))
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to compute checksum")
+		return
+	}
+
+	w.Header().Set("ETag", `"`+checksum+`"`)
+	w.Header().Set("Cache-Control", "no-cache")
+	respondWithJSON(w, http.StatusOK, ChecksumResponse{Checksum: checksum, Count: count})
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// cleanScanPageSize is how many jokes are read from the repository at a time
+// while looking for a clean one.
+const cleanScanPageSize = 500
+
+// getCleanJoke picks a random joke that contains no words from the
+// profanity list and, if maxLength is positive, is at most maxLength
+// characters long. Stored text may be compressed, so the database can't do
+// the filtering; instead all jokes are scanned once, keeping a uniformly
+// random clean one (reservoir sampling).
+func (h *JokeHandler) getCleanJoke(w http.ResponseWriter, r *http.Request, maxLength int) {
+	var (
+		picked *model.Joke
+		clean  int
+	)
+
+	for offset := 0; ; offset += cleanScanPageSize {
+		jokes, err := h.repo.ListJokes(r.Context(), cleanScanPageSize, offset)
+		if err != nil {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve random joke")
+			return
+		}
+
+		for _, joke := range jokes {
+			if h.opts.Profanity.Contains(joke.Text) {
+				continue
+			}
+			if maxLength > 0 && utf8.RuneCountInString(joke.Text) > maxLength {
+				continue
+			}
+
+			clean++
+			if rand.IntN(clean) == 0 {
+				picked = joke
+			}
+		}
+
+		if len(jokes) < cleanScanPageSize {
+			break
+		}
+	}
+
+	if picked == nil {
+		respondWithError(w, r, http.StatusNotFound, "No clean jokes available")
+		return
+	}
+
+	applyTimeFormat(r, picked)
+	respondWithJSON(w, http.StatusOK, picked)
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+type CollectionHandler struct {
+	repo   repository.JokeRepository
+	logger *slog.Logger
+	opts   Options
+}
+
+func NewCollectionHandler(repo repository.JokeRepository, logger *slog.Logger, opts Options) *CollectionHandler {
+	return &CollectionHandler{
+		repo:   repo,
+		logger: logger,
+		opts:   opts,
+	}
+}
+
+type CollectionResponse struct {
+	*model.Collection
+	JokeListResponse
+}
+
+type CreateCollectionRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (h *CollectionHandler) log(r *http.Request) *slog.Logger {
+	return requestLogger(h.logger, r)
+}
+
+// GetCollection handles GET /api/collections/{id}. The collection's jokes
+// are paged like GET /api/joke/.
+func (h *CollectionHandler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	if !allowQueryParams(w, r, h.opts, "limit", "offset", "time_format") || !allowTimeFormat(w, r) {
+		return
+	}
+
+	id, ok := h.collectionID(w, r)
+	if !ok {
+		return
+	}
+
+	collection, err := h.repo.GetCollection(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrCollectionNotFound) {
+			respondWithError(w, r, http.StatusNotFound, "Collection not found")
+			return
+		}
+
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve collection")
+		return
+	}
+
+	limit, offset := parsePagination(w, r)
+	if !allowOffset(w, r, offset, h.opts.MaxOffset) {
+		return
+	}
+
+	jokes, err := h.repo.ListCollectionJokes(r.Context(), id, limit, offset)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve collection jokes")
+		return
+	}
+
+	total, err := h.repo.CountCollectionJokes(r.Context(), id)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to count collection jokes")
+		return
+	}
+
+	hasMore := offset+len(jokes) < total
+	setPaginationLinks(w, r, limit, offset, &total, hasMore)
+
+	response := CollectionResponse{
+		Collection: collection,
+		JokeListResponse: JokeListResponse{
+			Jokes:      jokes,
+			Total:      &total,
+			TotalPages: totalPages(total, limit),
+			Limit:      limit,
+			Offset:     offset,
+			HasMore:    hasMore,
+			OutOfRange: outOfRange(total, offset),
+		},
+	}
+	if err := markEmptyCollection(r.Context(), h.repo, &response.JokeListResponse, -1); err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to count jokes")
+		return
+	}
+
+	applyTimeFormat(r, jokes...)
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// CreateCollection handles POST /api/admin/collections
+func (h *CollectionHandler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	var req CreateCollectionRequest
+
+	if !decodeJSONBodyLogged(w, r, &req, requestLogger(h.logger, r), h.opts.LogFailedBodyBytes) {
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		respondWithError(w, r, http.StatusBadRequest, "Collection name is required")
+		return
+	}
+
+	id, err := h.repo.CreateCollection(r.Context(), &model.Collection{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		respondWithWriteError(w, r, h.log(r), err, "Failed to create collection")
+		return
+	}
+
+	collection, err := h.repo.GetCollection(r.Context(), id)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Collection created but failed to retrieve")
+		return
+	}
+
+	w.Header().Set("Location", "/api/collections/"+strconv.FormatInt(id, 10))
+	respondWithJSON(w, http.StatusCreated, collection)
+}
+
+// AddJoke handles PUT /api/admin/collections/{id}/jokes/{jokeID}
+func (h *CollectionHandler) AddJoke(w http.ResponseWriter, r *http.Request) {
+	collectionID, jokeID, ok := h.collectionJokeIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.AddJokeToCollection(r.Context(), collectionID, jokeID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrCollectionNotFound):
+			respondWithError(w, r, http.StatusNotFound, "Collection not found")
+		case errors.Is(err, repository.ErrJokeNotFound):
+			respondWithError(w, r, http.StatusNotFound, "Joke not found")
+		default:
+			respondWithWriteError(w, r, h.log(r), err, "Failed to add joke to collection")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveJoke handles DELETE /api/admin/collections/{id}/jokes/{jokeID}
+func (h *CollectionHandler) RemoveJoke(w http.ResponseWriter, r *http.Request) {
+	collectionID, jokeID, ok := h.collectionJokeIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.RemoveJokeFromCollection(r.Context(), collectionID, jokeID); err != nil {
+		if errors.Is(err, repository.ErrJokeNotFound) {
+			respondWithError(w, r, http.StatusNotFound, "Joke not found in collection")
+			return
+		}
+
+		h.log(r).Error("Failed to remove joke from collection", slog.String("error", err.Error()))
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to remove joke from collection")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CollectionHandler) collectionID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid collection ID")
+		return 0, false
+	}
+
+	return id, true
+}
+
+func (h *CollectionHandler) collectionJokeIDs(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	collectionID, ok := h.collectionID(w, r)
+	if !ok {
+		return 0, 0, false
+	}
+
+	jokeID, err := resolveJokeID(r.Context(), h.repo, h.opts, chi.URLParam(r, "jokeID"))
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidJokeID):
+			respondWithError(w, r, http.StatusBadRequest, "Invalid joke ID")
+		case errors.Is(err, repository.ErrJokeNotFound):
+			respondWithError(w, r, http.StatusNotFound, "Joke not found")
+		default:
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve joke")
+		}
+		return 0, 0, false
+	}
+
+	return collectionID, jokeID, true
+}
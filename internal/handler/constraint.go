@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// Error codes returned when a write is rejected by a database constraint.
+const (
+	errCodeUniqueViolation     = "unique_violation"
+	errCodeForeignKeyViolation = "foreign_key_violation"
+	errCodeNotNullViolation    = "not_null_violation"
+	errCodeConstraintViolation = "constraint_violation"
+	errCodeJokeLimitReached    = "joke_limit_reached"
+)
+
+// respondWithWriteError reports a failed repository write. Constraint
+// violations are the client's doing and get a 4xx with a specific code, and
+// a full collection gets a 507; anything else is logged and answered with a
+// 500 carrying message.
+func respondWithWriteError(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error, message string) {
+	if errors.Is(err, repository.ErrJokeLimitReached) {
+		respondWithErrorCode(w, r, http.StatusInsufficientStorage, errCodeJokeLimitReached, "The joke limit has been reached")
+		return
+	}
+
+	var constraintErr *repository.ConstraintError
+	if !errors.As(err, &constraintErr) {
+		logger.Error(message, slog.String("error", err.Error()))
+		respondWithError(w, r, http.StatusInternalServerError, message)
+		return
+	}
+
+	switch constraintErr.Kind {
+	case repository.ConstraintUnique:
+		respondWithErrorCode(w, r, http.StatusConflict, errCodeUniqueViolation, "A record with the same unique value already exists")
+	case repository.ConstraintForeignKey:
+		respondWithErrorCode(w, r, http.StatusUnprocessableEntity, errCodeForeignKeyViolation, "A referenced record does not exist")
+	case repository.ConstraintNotNull:
+		respondWithErrorCode(w, r, http.StatusBadRequest, errCodeNotNullViolation, "A required value is missing")
+	default:
+		respondWithErrorCode(w, r, http.StatusUnprocessableEntity, errCodeConstraintViolation, "The request violates a data constraint")
+	}
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// conflictingRepository fails every create with a unique violation.
+type conflictingRepository struct {
+	repository.JokeRepository
+}
+
+func (conflictingRepository) GetOrCreateJokes(ctx context.Context, jokes []*model.Joke) ([]repository.UpsertResult, error) {
+	return nil, &repository.ConstraintError{
+		Kind: repository.ConstraintUnique,
+		Err:  errors.New("UNIQUE constraint failed: jokes.text_hash"),
+	}
+}
+
+func TestCreateJokeUniqueViolation(t *testing.T) {
+	h := NewJokeHandler(conflictingRepository{}, slog.New(slog.NewTextHandler(io.Discard, nil)), Options{})
+
+	rec := httptest.NewRecorder()
+	h.CreateJoke(rec, httptest.NewRequest(http.MethodPost, "/api/admin/joke", strings.NewReader(`{"text":"once"}`)))
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	var resp ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding error response: %v", err)
+	}
+	if resp.Code != errCodeUniqueViolation {
+		t.Errorf("code = %q, want %q", resp.Code, errCodeUniqueViolation)
+	}
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// ResetJokeCounters handles POST /api/admin/joke/{id}/reset-counters,
+// zeroing the joke's counters, currently its view count, and returning the
+// joke as updated.
+func (h *JokeHandler) ResetJokeCounters(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r) {
+		return
+	}
+
+	id, ok := h.jokeID(w, r)
+	if !ok {
+		return
+	}
+
+	joke, err := h.repo.ResetJokeCounters(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrJokeNotFound) {
+			respondWithError(w, r, http.StatusNotFound, "Joke not found")
+			return
+		}
+
+		respondWithWriteError(w, r, h.log(r), err, "Failed to reset joke counters")
+		return
+	}
+
+	setJokeCacheHeaders(w, joke)
+	respondWithJSON(w, http.StatusOK, joke)
+}
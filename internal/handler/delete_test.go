@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func TestDeleteJoke(t *testing.T) {
+	h, repo := newTestJokeHandler(t, Options{})
+
+	id, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "A short-lived joke"})
+	if err != nil {
+		t.Fatalf("seeding joke: %v", err)
+	}
+
+	deleteJoke := func(target string) int {
+		return serveRoute(h.DeleteJoke, http.MethodDelete, "/joke/{id}", target, "").Code
+	}
+	target := fmt.Sprintf("/joke/%d", id)
+
+	if status := deleteJoke(target); status != http.StatusNoContent {
+		t.Fatalf("delete: status = %d, want %d", status, http.StatusNoContent)
+	}
+
+	// Deleting it again is 404 unless the client asks for idempotency.
+	if status := deleteJoke(target); status != http.StatusNotFound {
+		t.Errorf("repeated delete: status = %d, want %d", status, http.StatusNotFound)
+	}
+	if status := deleteJoke(target + "?idempotent=true"); status != http.StatusNoContent {
+		t.Errorf("idempotent delete: status = %d, want %d", status, http.StatusNoContent)
+	}
+	if status := deleteJoke("/joke/9999?idempotent=false"); status != http.StatusNotFound {
+		t.Errorf("idempotent=false: status = %d, want %d", status, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+const (
+	// defaultDistributionDraws is the number of draws when ?draws is omitted.
+	defaultDistributionDraws = 1000
+
+	// maxDistributionDraws bounds the draws, each of which is a query.
+	maxDistributionDraws = 10000
+)
+
+type DistributionResponse struct {
+	Draws  int            `json:"draws"`
+	Counts map[string]int `json:"counts"`
+}
+
+// GetRandomDistribution handles GET /api/admin/random/distribution. It
+// draws ?draws random jokes the same way GET /api/joke/random does and
+// reports how often each joke ID came up, to help spot skew in the
+// selection.
+func (h *JokeHandler) GetRandomDistribution(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r, "draws") {
+		return
+	}
+
+	draws := defaultDistributionDraws
+	if v := r.URL.Query().Get("draws"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 || parsed > maxDistributionDraws {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid draws parameter, expected 1 to "+strconv.Itoa(maxDistributionDraws))
+			return
+		}
+		draws = parsed
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < draws; i++ {
+		joke, err := h.repo.GetRandomJoke(r.Context())
+		if err != nil {
+			if errors.Is(err, repository.ErrNoJokes) {
+				respondWithError(w, r, http.StatusNotFound, "No jokes available")
+				return
+			}
+
+			h.log(r).Error("Failed to draw random joke", slog.String("error", err.Error()), "draw", i)
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to draw random jokes")
+			return
+		}
+
+		counts[jokeRef(joke)]++
+	}
+
+	respondWithJSON(w, http.StatusOK, DistributionResponse{Draws: draws, Counts: counts})
+}
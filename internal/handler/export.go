@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// ExportJokes handles GET /api/admin/jokes/export. The export is written to
+// a temporary file first so it can be served with Range support, which lets
+// clients resume interrupted downloads. The ETag is the hash of the content,
+// so If-Range only resumes when the data hasn't changed in between.
+//
+// ?created_after and ?created_before limit the export to the jokes created
+// in that range, taking the same values as on the joke list.
+func (h *JokeHandler) ExportJokes(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r, "created_after", "created_before") {
+		return
+	}
+
+	from, to, err := createdRange(r, h.opts.Now())
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid "+err.Error())
+		return
+	}
+
+	file, err := os.CreateTemp("", "jokes-export-*.json")
+	if err != nil {
+		h.log(r).Error("Failed to create export file", slog.String("error", err.Error()))
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to export jokes")
+		return
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	hash := sha256.New()
+	if err := h.writeExport(r, io.MultiWriter(file, hash), from, to); err != nil {
+		h.log(r).Error("Failed to write export", slog.String("error", err.Error()))
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to export jokes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="jokes.json"`)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(hash.Sum(nil))+`"`)
+
+	// ServeContent handles Range, If-Range and Accept-Ranges.
+	http.ServeContent(w, r, "jokes.json", time.Time{}, file)
+}
+
+// writeExport writes the jokes created in [from, to) to out as a JSON array.
+// A zero bound leaves that side open.
+func (h *JokeHandler) writeExport(r *http.Request, out io.Writer, from, to time.Time) error {
+	if _, err := io.WriteString(out, "["); err != nil {
+		return err
+	}
+
+	first := true
+	err := h.repo.EachJoke(r.Context(), func(joke *model.Joke) error {
+		if !from.IsZero() && joke.CreatedAt.Before(from) || !to.IsZero() && !joke.CreatedAt.Before(to) {
+			return nil
+		}
+
+		if !first {
+			if _, err := io.WriteString(out, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		data, err := json.Marshal(joke)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(out, "]\n")
+	return err
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"regexp"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/treboc/huhu-api/internal/featureflag"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+var featureFlagName = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+
+type FeatureFlagHandler struct {
+	repo   repository.FeatureFlagRepository
+	store  *featureflag.Store
+	logger *slog.Logger
+}
+
+func NewFeatureFlagHandler(repo repository.FeatureFlagRepository, store *featureflag.Store, logger *slog.Logger) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		repo:   repo,
+		store:  store,
+		logger: logger,
+	}
+}
+
+func (h *FeatureFlagHandler) log(r *http.Request) *slog.Logger {
+	return requestLogger(h.logger, r)
+}
+
+// ListFeatureFlags handles GET /api/admin/features
+func (h *FeatureFlagHandler) ListFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	flags, err := h.repo.ListFeatureFlags(r.Context())
+	if err != nil {
+		h.log(r).Error("Failed to list feature flags", slog.String("error", err.Error()))
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve feature flags")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, flags)
+}
+
+type SetFeatureFlagRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// SetFeatureFlag handles PUT /api/admin/features/{name}
+func (h *FeatureFlagHandler) SetFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if !featureFlagName.MatchString(name) {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid feature flag name")
+		return
+	}
+
+	var req SetFeatureFlagRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.Enabled == nil {
+		respondWithError(w, r, http.StatusBadRequest, "Field enabled is required")
+		return
+	}
+
+	if err := h.repo.SetFeatureFlag(r.Context(), name, *req.Enabled); err != nil {
+		respondWithWriteError(w, r, h.log(r), err, "Failed to set feature flag")
+		return
+	}
+
+	// Apply the change on this instance right away instead of waiting for the next refresh.
+	if err := h.store.Refresh(r.Context()); err != nil {
+		h.log(r).Error("Failed to refresh feature flags", slog.String("error", err.Error()))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
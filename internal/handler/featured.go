@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// ListFeaturedJokes handles GET /api/joke/featured
+func (h *JokeHandler) ListFeaturedJokes(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r, "limit", "offset", "time_format") || !allowTimeFormat(w, r) {
+		return
+	}
+
+	limit, offset := parsePagination(w, r)
+	if !allowOffset(w, r, offset, h.opts.MaxOffset) {
+		return
+	}
+
+	jokes, err := h.repo.ListFeaturedJokes(r.Context(), limit, offset)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve featured jokes")
+		return
+	}
+
+	total, err := h.repo.CountFeaturedJokes(r.Context())
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to count featured jokes")
+		return
+	}
+
+	response := JokeListResponse{
+		Jokes:      jokes,
+		Total:      &total,
+		TotalPages: totalPages(total, limit),
+		Limit:      limit,
+		Offset:     offset,
+		HasMore:    offset+len(jokes) < total,
+		OutOfRange: outOfRange(total, offset),
+	}
+	if err := markEmptyCollection(r.Context(), h.repo, &response, -1); err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to count jokes")
+		return
+	}
+
+	applyTimeFormat(r, jokes...)
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// FeatureJoke handles PUT /api/admin/joke/{id}/featured
+func (h *JokeHandler) FeatureJoke(w http.ResponseWriter, r *http.Request) {
+	h.setFeatured(w, r, true)
+}
+
+// UnfeatureJoke handles DELETE /api/admin/joke/{id}/featured
+func (h *JokeHandler) UnfeatureJoke(w http.ResponseWriter, r *http.Request) {
+	h.setFeatured(w, r, false)
+}
+
+func (h *JokeHandler) setFeatured(w http.ResponseWriter, r *http.Request, featured bool) {
+	if !h.allowParams(w, r, "time_format") || !allowTimeFormat(w, r) {
+		return
+	}
+
+	id, ok := h.jokeID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.SetJokeFeatured(r.Context(), id, featured); err != nil {
+		if errors.Is(err, repository.ErrJokeNotFound) {
+			respondWithError(w, r, http.StatusNotFound, "Joke not found")
+			return
+		}
+
+		respondWithWriteError(w, r, h.log(r), err, "Failed to update featured status")
+		return
+	}
+
+	joke, err := h.repo.GetJoke(r.Context(), id)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Joke updated but failed to retrieve")
+		return
+	}
+
+	applyTimeFormat(r, joke)
+	setJokeCacheHeaders(w, joke)
+	respondWithJSON(w, http.StatusOK, joke)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func TestFeaturedJokes(t *testing.T) {
+	h, repo := newTestJokeHandler(t, Options{})
+
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		id, err := repo.CreateJoke(context.Background(), &model.Joke{Text: fmt.Sprintf("joke %d", i)})
+		if err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	featured := func() []int64 {
+		t.Helper()
+
+		rec := serveRoute(h.ListFeaturedJokes, http.MethodGet, "/joke/featured", "/joke/featured", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("featured list: status = %d, want %d", rec.Code, http.StatusOK)
+		}
+
+		var list JokeListResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+			t.Fatalf("decoding featured list: %v", err)
+		}
+		got := make([]int64, 0, len(list.Jokes))
+		for _, joke := range list.Jokes {
+			if !joke.Featured {
+				t.Errorf("joke %d listed as featured without the flag", joke.ID)
+			}
+			got = append(got, joke.ID)
+		}
+		if list.Total == nil || *list.Total != len(got) {
+			t.Errorf("total = %v, want %d", list.Total, len(got))
+		}
+		return got
+	}
+
+	setFeatured := func(fn http.HandlerFunc, method string, id int64) (int, string) {
+		rec := serveRoute(fn, method, "/joke/{id}/featured", fmt.Sprintf("/joke/%d/featured", id), "")
+		return rec.Code, rec.Body.String()
+	}
+
+	if got := featured(); len(got) != 0 {
+		t.Fatalf("featured before any toggle = %v, want none", got)
+	}
+
+	for _, id := range []int64{ids[0], ids[2]} {
+		status, body := setFeatured(h.FeatureJoke, http.MethodPut, id)
+		if status != http.StatusOK || !strings.Contains(body, `"featured":true`) {
+			t.Fatalf("feature joke %d: status = %d, body %s", id, status, body)
+		}
+	}
+	if got, want := featured(), []int64{ids[0], ids[2]}; !slices.Equal(slices.Sorted(slices.Values(got)), want) {
+		t.Errorf("featured = %v, want %v", got, want)
+	}
+
+	status, body := setFeatured(h.UnfeatureJoke, http.MethodDelete, ids[0])
+	if status != http.StatusOK || !strings.Contains(body, `"featured":false`) {
+		t.Fatalf("unfeature joke: status = %d, body %s", status, body)
+	}
+	if got, want := featured(), []int64{ids[2]}; !slices.Equal(got, want) {
+		t.Errorf("featured after unfeaturing = %v, want %v", got, want)
+	}
+
+	if status, _ := setFeatured(h.FeatureJoke, http.MethodPut, 9999); status != http.StatusNotFound {
+		t.Errorf("feature missing joke: status = %d, want %d", status, http.StatusNotFound)
+	}
+}
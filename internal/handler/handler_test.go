@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// newTestJokeHandler returns a JokeHandler with opts on top of a fresh
+// SQLite database.
+func newTestJokeHandler(t *testing.T, opts Options) (*JokeHandler, *repository.SQLiteJokeRepository) {
+	t.Helper()
+
+	repo, err := repository.NewSQLiteJokeRepository(filepath.Join(t.TempDir(), "jokes.db"), repository.Options{})
+	if err != nil {
+		t.Fatalf("creating repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	return NewJokeHandler(repo, slog.New(slog.NewTextHandler(io.Discard, nil)), opts), repo
+}
+
+// serveRoute serves a single request with fn mounted at pattern, so that
+// URL parameters resolve as they do in the real router.
+func serveRoute(fn http.HandlerFunc, method, pattern, target, body string) *httptest.ResponseRecorder {
+	r := chi.NewRouter()
+	r.Method(method, pattern, fn)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+
+	return rec
+}
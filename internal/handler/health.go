@@ -1,8 +1,98 @@
 package handler
 
-import "net/http"
+import (
+	"context"
+	"log/slog"
+	"net/http"
 
-func HandleHealthz(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("OK"))
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// HealthRepository is what the verbose health check probes.
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	ListAppliedMigrations(ctx context.Context) ([]*model.SchemaMigration, error)
+	CountJokes(ctx context.Context) (int, error)
+}
+
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthError    = "error"
+)
+
+type HealthResponse struct {
+	Status string       `json:"status"`
+	Checks HealthChecks `json:"checks"`
+}
+
+// HealthChecks are the individual results of a verbose health check. The
+// database and migrations checks are critical. The joke count is
+// informational and is null when it could not be read.
+type HealthChecks struct {
+	Database   string `json:"database"`
+	Migrations string `json:"migrations"`
+	JokesCount *int   `json:"jokes_count"`
+}
+
+// HandleHealthz returns the handler for GET /healthz. It answers a plain
+// "OK" unless ?verbose=true asks for the individual checks against repo,
+// in which case a failing critical check turns the response into a 503.
+// Without a repo, verbose requests get the plain answer as well.
+func HandleHealthz(repo HealthRepository, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if repo == nil || r.URL.Query().Get("verbose") != "true" {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("OK"))
+			return
+		}
+
+		resp := checkHealth(r.Context(), repo, requestLogger(logger, r))
+
+		status := http.StatusOK
+		if resp.Status == HealthError {
+			status = http.StatusServiceUnavailable
+		}
+
+		respondWithJSON(w, status, resp)
+	}
+}
+
+// checkHealth runs every check, even after one has failed, so the response
+// shows the full picture.
+func checkHealth(ctx context.Context, repo HealthRepository, logger *slog.Logger) HealthResponse {
+	resp := HealthResponse{Status: HealthOK}
+
+	resp.Checks.Database = HealthOK
+	if err := repo.Ping(ctx); err != nil {
+		logger.Error("Health check failed", "check", "database", slog.String("error", err.Error()))
+		resp.Checks.Database = HealthError
+		resp.Status = HealthError
+	}
+
+	migrations, err := repo.ListAppliedMigrations(ctx)
+	switch {
+	case err != nil:
+		logger.Error("Health check failed", "check", "migrations", slog.String("error", err.Error()))
+		resp.Checks.Migrations = HealthError
+		resp.Status = HealthError
+	case len(migrations) == 0 || migrations[len(migrations)-1].Version < repository.LatestMigrationVersion():
+		resp.Checks.Migrations = "pending"
+		resp.Status = HealthError
+	default:
+		resp.Checks.Migrations = "applied"
+	}
+
+	count, err := repo.CountJokes(ctx)
+	if err != nil {
+		logger.Error("Health check failed", "check", "jokes_count", slog.String("error", err.Error()))
+		if resp.Status == HealthOK {
+			resp.Status = HealthDegraded
+		}
+	} else {
+		resp.Checks.JokesCount = &count
+	}
+
+	return resp
 }
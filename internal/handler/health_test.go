@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+type stubHealthRepo struct {
+	pingErr    error
+	version    int
+	countErr   error
+	jokesCount int
+}
+
+func (s *stubHealthRepo) Ping(ctx context.Context) error {
+	return s.pingErr
+}
+
+func (s *stubHealthRepo) ListAppliedMigrations(ctx context.Context) ([]*model.SchemaMigration, error) {
+	return []*model.SchemaMigration{{Version: s.version}}, nil
+}
+
+func (s *stubHealthRepo) CountJokes(ctx context.Context) (int, error) {
+	return s.jokesCount, s.countErr
+}
+
+func TestHandleHealthzVerbose(t *testing.T) {
+	latest := repository.LatestMigrationVersion()
+
+	tests := []struct {
+		name           string
+		repo           *stubHealthRepo
+		wantCode       int
+		wantStatus     string
+		wantDatabase   string
+		wantMigrations string
+	}{
+		{
+			name:           "healthy",
+			repo:           &stubHealthRepo{version: latest, jokesCount: 3},
+			wantCode:       http.StatusOK,
+			wantStatus:     HealthOK,
+			wantDatabase:   HealthOK,
+			wantMigrations: "applied",
+		},
+		{
+			name:           "database down",
+			repo:           &stubHealthRepo{pingErr: errors.New("disk I/O error"), version: latest},
+			wantCode:       http.StatusServiceUnavailable,
+			wantStatus:     HealthError,
+			wantDatabase:   HealthError,
+			wantMigrations: "applied",
+		},
+		{
+			name:           "pending migrations",
+			repo:           &stubHealthRepo{version: latest - 1},
+			wantCode:       http.StatusServiceUnavailable,
+			wantStatus:     HealthError,
+			wantDatabase:   HealthOK,
+			wantMigrations: "pending",
+		},
+		{
+			name:           "count unavailable",
+			repo:           &stubHealthRepo{version: latest, countErr: errors.New("busy")},
+			wantCode:       http.StatusOK,
+			wantStatus:     HealthDegraded,
+			wantDatabase:   HealthOK,
+			wantMigrations: "applied",
+		},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			HandleHealthz(tt.repo, logger)(rec, httptest.NewRequest(http.MethodGet, "/healthz?verbose=true", nil))
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("status code = %d, want %d", rec.Code, tt.wantCode)
+			}
+
+			var resp HealthResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if resp.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", resp.Status, tt.wantStatus)
+			}
+			if resp.Checks.Database != tt.wantDatabase {
+				t.Errorf("database = %q, want %q", resp.Checks.Database, tt.wantDatabase)
+			}
+			if resp.Checks.Migrations != tt.wantMigrations {
+				t.Errorf("migrations = %q, want %q", resp.Checks.Migrations, tt.wantMigrations)
+			}
+			if (resp.Checks.JokesCount == nil) != (tt.repo.countErr != nil) {
+				t.Errorf("jokes_count = %v with count error %v", resp.Checks.JokesCount, tt.repo.countErr)
+			}
+		})
+	}
+}
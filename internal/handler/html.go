@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"bytes"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// jokesPageTemplate renders GET /jokes. html/template escapes the joke text
+// for its context, so stored text can't inject markup or scripts.
+var jokesPageTemplate = template.Must(template.New("jokes").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Jokes</title>
+</head>
+<body>
+<h1>Jokes</h1>
+{{if .Jokes}}<ol start="{{.Start}}">
+{{range .Jokes}}<li><a href="/api/joke/{{.Ref}}">{{.Text}}</a></li>
+{{end}}</ol>
+{{else}}<p>No jokes here.</p>
+{{end}}<nav>
+{{if .PrevURL}}<a href="{{.PrevURL}}" rel="prev">Previous</a>
+{{end}}{{if .NextURL}}<a href="{{.NextURL}}" rel="next">Next</a>
+{{end}}</nav>
+</body>
+</html>
+`))
+
+type jokesPage struct {
+	Jokes   []jokesPageItem
+	Start   int
+	PrevURL string
+	NextURL string
+}
+
+type jokesPageItem struct {
+	Ref  string
+	Text string
+}
+
+// ListJokesHTML handles GET /jokes, a plain HTML page of jokes with previous
+// and next links that works without JavaScript.
+func (h *JokeHandler) ListJokesHTML(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r, "limit", "offset") {
+		return
+	}
+
+	limit, offset := parsePagination(w, r)
+	if !allowOffset(w, r, offset, h.opts.MaxOffset) {
+		return
+	}
+
+	jokes, err := h.repo.ListJokes(r.Context(), limit, offset)
+	if err != nil {
+		h.log(r).Error("Failed to list jokes", slog.String("error", err.Error()))
+		http.Error(w, "Failed to retrieve jokes", http.StatusInternalServerError)
+		return
+	}
+
+	total, err := h.repo.CountJokes(r.Context())
+	if err != nil {
+		h.log(r).Error("Failed to count jokes", slog.String("error", err.Error()))
+		http.Error(w, "Failed to count jokes", http.StatusInternalServerError)
+		return
+	}
+
+	page := jokesPage{
+		Jokes: make([]jokesPageItem, 0, len(jokes)),
+		Start: offset + 1,
+	}
+	for _, joke := range jokes {
+		page.Jokes = append(page.Jokes, jokesPageItem{Ref: jokeRef(joke), Text: joke.Text})
+	}
+
+	pageURL := func(offset int) string {
+		query := r.URL.Query()
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set("offset", strconv.Itoa(offset))
+		return r.URL.Path + "?" + query.Encode()
+	}
+	if offset > 0 {
+		page.PrevURL = pageURL(max(offset-limit, 0))
+	}
+	if offset+len(jokes) < total {
+		page.NextURL = pageURL(offset + limit)
+	}
+
+	var out bytes.Buffer
+	if err := jokesPageTemplate.Execute(&out, page); err != nil {
+		h.log(r).Error("Failed to render jokes page", slog.String("error", err.Error()))
+		http.Error(w, "Failed to render jokes", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(out.Bytes())
+}
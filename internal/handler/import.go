@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/treboc/huhu-api/internal/importer"
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+type ImportResponse struct {
+	Inserted int `json:"inserted"`
+	Skipped  int `json:"skipped"`
+}
+
+// ImportRemoteJokes handles POST /api/admin/jokes/import/remote. Either
+// every new joke is imported or, on error, none is.
+func (h *JokeHandler) ImportRemoteJokes(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r) {
+		return
+	}
+
+	if h.opts.RemoteSource == nil {
+		respondWithError(w, r, http.StatusNotImplemented, "Remote import is not configured")
+		return
+	}
+
+	texts, err := h.opts.RemoteSource.Fetch(r.Context())
+	if err != nil {
+		h.log(r).Error("Failed to fetch remote jokes", slog.String("error", err.Error()))
+
+		if errors.Is(err, importer.ErrUpstreamTimeout) {
+			respondWithError(w, r, http.StatusGatewayTimeout, "Upstream joke source timed out")
+			return
+		}
+
+		respondWithError(w, r, http.StatusBadGateway, "Failed to fetch jokes from upstream source")
+		return
+	}
+
+	var response ImportResponse
+	jokes := make([]*model.Joke, 0, len(texts))
+
+	for _, text := range texts {
+		text, err := h.prepareText(text)
+		if err != nil {
+			response.Skipped++
+			continue
+		}
+
+		jokes = append(jokes, &model.Joke{Text: text})
+	}
+
+	// GetOrCreateJokes skips jokes whose normalized text is already stored
+	// or repeated earlier in the batch, and stores the rest in a single
+	// transaction, so a failed import leaves no partial batch behind.
+	results, err := h.repo.GetOrCreateJokes(r.Context(), jokes)
+	if err != nil {
+		respondWithWriteError(w, r, h.log(r), err, "Failed to import jokes")
+		return
+	}
+
+	for _, result := range results {
+		if result.Created {
+			response.Inserted++
+		} else {
+			response.Skipped++
+		}
+	}
+
+	h.log(r).Info("Imported remote jokes", "inserted", response.Inserted, "skipped", response.Skipped)
+	respondWithJSON(w, http.StatusOK, response)
+}
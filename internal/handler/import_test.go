@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/importer"
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func TestImportRemoteJokes(t *testing.T) {
+	var upstreamStatus atomic.Int32
+	upstreamStatus.Store(http.StatusOK)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			<-r.Context().Done()
+		default:
+			if status := int(upstreamStatus.Load()); status != http.StatusOK {
+				w.WriteHeader(status)
+				return
+			}
+			fmt.Fprint(w, `{"data":{"jokes":[{"text":"Remote joke"},{"text":"Known joke"},{"text":"known  JOKE"},{"text":"Remote joke"},{"text":"  "},{"title":"no text"}]}}`)
+		}
+	}))
+	t.Cleanup(upstream.Close)
+
+	importRemote := func(h *JokeHandler) *httptest.ResponseRecorder {
+		return serveRoute(h.ImportRemoteJokes, http.MethodPost, "/jokes/import/remote", "/jokes/import/remote", "")
+	}
+
+	h, repo := newTestJokeHandler(t, Options{
+		RemoteSource: importer.NewRemoteSource(upstream.URL+"/jokes", "data.jokes", "text", time.Second),
+	})
+	if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "Known joke"}); err != nil {
+		t.Fatalf("seeding joke: %v", err)
+	}
+
+	rec := importRemote(h)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (body %s)", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	var result ImportResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	// Blank and missing texts never leave the importer; the known joke,
+	// its case and whitespace variant and the repeated one are skipped.
+	if want := (ImportResponse{Inserted: 1, Skipped: 3}); result != want {
+		t.Errorf("result = %+v, want %+v", result, want)
+	}
+	if count, err := repo.CountJokes(context.Background()); err != nil || count != 2 {
+		t.Errorf("CountJokes = %d, %v, want 2", count, err)
+	}
+
+	upstreamStatus.Store(http.StatusInternalServerError)
+	if rec := importRemote(h); rec.Code != http.StatusBadGateway {
+		t.Errorf("failing upstream: status = %d, want %d", rec.Code, http.StatusBadGateway)
+	}
+
+	slow, _ := newTestJokeHandler(t, Options{
+		RemoteSource: importer.NewRemoteSource(upstream.URL+"/slow", "data.jokes", "text", 50*time.Millisecond),
+	})
+	if rec := importRemote(slow); rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("slow upstream: status = %d, want %d", rec.Code, http.StatusGatewayTimeout)
+	}
+
+	unconfigured, _ := newTestJokeHandler(t, Options{})
+	if rec := importRemote(unconfigured); rec.Code != http.StatusNotImplemented {
+		t.Errorf("no source: status = %d, want %d", rec.Code, http.StatusNotImplemented)
+	}
+}
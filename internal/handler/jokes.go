@@ -1,43 +1,213 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/treboc/huhu-api/internal/importer"
 	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/profanity"
 	"github.com/treboc/huhu-api/internal/repository"
+	"golang.org/x/text/unicode/norm"
 )
 
 type JokeHandler struct {
-	repo   repository.JokeRepository
-	logger *slog.Logger
+	repo     repository.JokeRepository
+	logger   *slog.Logger
+	opts     Options
+	throttle *submissionThrottle
 }
 
-func NewJokeHandler(repo repository.JokeRepository, logger *slog.Logger) *JokeHandler {
-	return &JokeHandler{
+// Options holds the optional, deployment specific settings of a JokeHandler.
+type Options struct {
+	// BaseURL is the public origin (e.g. https://jokes.example.com) used to
+	// build canonical joke URLs. When empty it is derived from the request.
+	BaseURL string
+
+	// PublicIDs makes routes address jokes by their UUID instead of the
+	// internal integer ID.
+	PublicIDs bool
+
+	// RemoteSource is the upstream API jokes are imported from. Remote
+	// imports are disabled when it is nil.
+	RemoteSource *importer.RemoteSource
+
+	// StrictParams rejects requests with query parameters the endpoint
+	// doesn't know instead of ignoring them.
+	StrictParams bool
+
+	// Sanitize strips or rejects HTML in joke text on write.
+	Sanitize SanitizeMode
+
+	// Profanity is the word list ProfanityMode applies to on write. It also
+	// backs ?clean=true on the random endpoint.
+	Profanity *profanity.Filter
+
+	// ProfanityMode masks or rejects flagged words in joke text on write.
+	ProfanityMode ProfanityMode
+
+	// TellPause is how long the tell endpoint waits between the setup and
+	// the punchline.
+	TellPause time.Duration
+
+	// Views, when set, counts every GET of a joke by ID and notes it as
+	// the joke's last access for the stale report.
+	Views ViewRecorder
+
+	// UnicodeForm is the normalization form joke text is stored in, so
+	// visually identical texts are stored as the same bytes. The zero value
+	// is NFC.
+	UnicodeForm norm.Form
+
+	// Now is the clock date-relative filters are computed from. It defaults
+	// to time.Now.
+	Now func() time.Time
+
+	// EmptyRandomFallback, when set, is returned as a placeholder joke with
+	// 200 by GET /api/joke/random and GET /api/surprise while there are no
+	// jokes, instead of 404.
+	EmptyRandomFallback string
+
+	// LogFailedBodyBytes logs up to this many bytes of request bodies that
+	// fail to decode, at debug level. Zero disables it.
+	LogFailedBodyBytes int
+
+	// PutCreatesMissing makes PUT /api/admin/joke/{id} create a joke that
+	// doesn't exist at that ID instead of answering 404. Clients can ask
+	// for this per request with Prefer: create-if-missing. It never applies
+	// with PublicIDs, where clients can't choose IDs.
+	PutCreatesMissing bool
+
+	// MaxOffset rejects list requests with a larger ?offset. Zero allows any
+	// offset.
+	MaxOffset int
+
+	// DuplicateSubmissionWindow refuses to create a joke with 429 when the
+	// same normalized text was submitted less than this long ago, whether
+	// or not that submission succeeded. Zero disables the check.
+	DuplicateSubmissionWindow time.Duration
+
+	// NDJSONImportSkipInvalid makes NDJSON imports skip malformed lines,
+	// listing them in the response, instead of stopping at the first one.
+	NDJSONImportSkipInvalid bool
+
+	// BackupDir is the directory backups are written to and restored from.
+	// Restores are disabled when it is empty.
+	BackupDir string
+}
+
+// ViewRecorder counts joke views and access times, typically buffering
+// them for a batched write.
+type ViewRecorder interface {
+	Record(id int64)
+}
+
+func NewJokeHandler(repo repository.JokeRepository, logger *slog.Logger, opts Options) *JokeHandler {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	h := &JokeHandler{
 		repo:   repo,
 		logger: logger,
+		opts:   opts,
 	}
+	if opts.DuplicateSubmissionWindow > 0 {
+		h.throttle = newSubmissionThrottle(opts.DuplicateSubmissionWindow)
+	}
+
+	return h
 }
 
 type JokeListResponse struct {
-	Jokes  []*model.Joke `json:"jokes"`
-	Total  int           `json:"total"`
-	Limit  int           `json:"limit"`
-	Offset int           `json:"offset"`
+	Jokes      []*model.Joke `json:"jokes"`
+	Total      *int          `json:"total,omitempty"`
+	TotalPages *int          `json:"total_pages,omitempty"`
+	Limit      int           `json:"limit"`
+	Offset     int           `json:"offset"`
+	HasMore    bool          `json:"has_more"`
+
+	// OutOfRange flags an offset at or past the end of a non-empty list,
+	// when the total is known.
+	OutOfRange bool `json:"out_of_range,omitempty"`
+
+	// EmptyCollection tells an empty page of a collection without any
+	// jokes apart from a page that just has no matches, and comes with a
+	// Message for first-run clients.
+	EmptyCollection bool   `json:"empty_collection,omitempty"`
+	Message         string `json:"message,omitempty"`
+}
+
+// emptyCollectionMessage points clients of a fresh deployment at how to add
+// the first joke.
+const emptyCollectionMessage = "There are no jokes yet. Add the first one with POST /api/admin/joke."
+
+// markEmptyCollection flags resp when it is empty because there are no jokes
+// at all. A total of -1 means the number of jokes isn't known yet.
+func markEmptyCollection(ctx context.Context, repo repository.JokeRepository, resp *JokeListResponse, total int) error {
+	if len(resp.Jokes) > 0 {
+		return nil
+	}
+
+	if total < 0 {
+		count, err := repo.CountJokes(ctx)
+		if err != nil {
+			return err
+		}
+		total = count
+	}
+
+	if total == 0 {
+		resp.EmptyCollection = true
+		resp.Message = emptyCollectionMessage
+	}
+
+	return nil
+}
+
+// outOfRange reports whether offset skips past all of total items.
+func outOfRange(total, offset int) bool {
+	return total > 0 && offset >= total
 }
 
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Code      string `json:"code,omitempty"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
-func (h *JokeHandler) ListJokes(w http.ResponseWriter, r *http.Request) {
-	limit := 10 // Default limit
-	offset := 0 // Default offset
+// maxPreferredPageSize caps the page size a client can ask for with the
+// Prefer header.
+const maxPreferredPageSize = 100
+
+// defaultPageSize is the page size when neither ?limit nor Prefer sets one.
+const defaultPageSize = 10
+
+// errCodeOffsetTooLarge marks list requests rejected by Options.MaxOffset.
+const errCodeOffsetTooLarge = "offset_too_large"
+
+// parsePagination reads the limit and offset query parameters, falling back
+// to the defaults for missing or invalid values. Without ?limit, an RFC 7240
+// "Prefer: max-count=N" header sets the page size, capped at
+// maxPreferredPageSize, and the applied size is echoed in the
+// Preference-Applied response header.
+func parsePagination(w http.ResponseWriter, r *http.Request) (limit, offset int) {
+	limit = defaultPageSize
+	offset = 0 // Default offset
+
+	w.Header().Add("Vary", "Prefer")
 
 	limitParam := r.URL.Query().Get("limit")
 	if limitParam != "" {
@@ -45,6 +215,9 @@ func (h *JokeHandler) ListJokes(w http.ResponseWriter, r *http.Request) {
 		if err == nil && parsedLimit > 0 {
 			limit = parsedLimit
 		}
+	} else if preferred := preferredMaxCount(r); preferred > 0 {
+		limit = min(preferred, maxPreferredPageSize)
+		w.Header().Set("Preference-Applied", "max-count="+strconv.Itoa(limit))
 	}
 
 	offsetParam := r.URL.Query().Get("offset")
@@ -55,34 +228,264 @@ func (h *JokeHandler) ListJokes(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	jokes, err := h.repo.ListJokes(r.Context(), limit, offset)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve jokes")
+	return limit, offset
+}
+
+// allowOffset answers 400 and returns false when offset is beyond
+// maxOffset. SQLite reads and discards every row before the offset, so deep
+// pages cost as much as listing everything up to them. A maxOffset of zero
+// allows any offset.
+func allowOffset(w http.ResponseWriter, r *http.Request, offset, maxOffset int) bool {
+	if maxOffset > 0 && offset > maxOffset {
+		respondWithErrorCode(w, r, http.StatusBadRequest, errCodeOffsetTooLarge,
+			"The offset must not exceed "+strconv.Itoa(maxOffset)+". Narrow the list with filters such as period or regex instead of paging this deep")
+		return false
+	}
+	return true
+}
+
+// hasPreference reports whether the request's Prefer headers include the
+// named preference.
+func hasPreference(r *http.Request, name string) bool {
+	for _, header := range r.Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			preference, _, _ = strings.Cut(preference, ";")
+			token, _, _ := strings.Cut(strings.TrimSpace(preference), "=")
+			if strings.EqualFold(strings.TrimSpace(token), name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// preferenceValue returns the value of the named preference in the
+// request's Prefer headers, unquoted, and whether it is there at all.
+func preferenceValue(r *http.Request, name string) (string, bool) {
+	for _, header := range r.Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			preference, _, _ = strings.Cut(preference, ";")
+			token, value, _ := strings.Cut(strings.TrimSpace(preference), "=")
+			if strings.EqualFold(strings.TrimSpace(token), name) {
+				return strings.Trim(strings.TrimSpace(value), `"`), true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// preferredMaxCount returns the max-count preference of the request's
+// Prefer headers, or 0 if there is no valid one. Unknown preferences and
+// preference parameters are ignored, as RFC 7240 asks.
+func preferredMaxCount(r *http.Request) int {
+	for _, header := range r.Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			preference, _, _ = strings.Cut(preference, ";")
+			name, value, _ := strings.Cut(strings.TrimSpace(preference), "=")
+			if !strings.EqualFold(strings.TrimSpace(name), "max-count") {
+				continue
+			}
+
+			count, err := strconv.Atoi(strings.Trim(strings.TrimSpace(value), `"`))
+			if err == nil && count > 0 {
+				return count
+			}
+		}
+	}
+
+	return 0
+}
+
+// totalPages returns how many pages of limit items it takes to list total
+// items.
+func totalPages(total, limit int) *int {
+	pages := (total + limit - 1) / limit
+	return &pages
+}
+
+// ListJokes handles GET /api/joke/. Jokes are ordered by ID unless
+// ?sort=random&seed= asks for a shuffle that stays the same across pages
+// requested with the same seed, or ?sort=stable for creation order.
+// ?format=ndjson streams the jokes as newline delimited JSON instead of a
+// single list object.
+// ?period=today|week|month only lists jokes created in the current UTC
+// day, week (starting Monday) or month. ?regex= only lists jokes whose text
+// matches the regular expression. ?created_after= and ?created_before= bound
+// the creation time, absolute or relative to now (see parseTimeExpression),
+// and narrow a period when combined with one. All of these filters combine,
+// and the total counts the jokes matching all of them.
+func (h *JokeHandler) ListJokes(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r, "limit", "offset", "include_total", "sort", "seed", "format", "period", "regex", "created_after", "created_before", "time_format") || !allowTimeFormat(w, r) {
+		return
+	}
+
+	period := r.URL.Query().Get("period")
+	regex := r.URL.Query().Get("regex")
+	created := r.URL.Query().Get("created_after") != "" || r.URL.Query().Get("created_before") != ""
+	for _, name := range []string{"period", "regex", "created_after", "created_before"} {
+		if r.URL.Query().Get(name) == "" {
+			continue
+		}
+		if r.URL.Query().Get("format") == "ndjson" {
+			respondWithError(w, r, http.StatusBadRequest, "format=ndjson can't be combined with "+name)
+			return
+		}
+		if sort := r.URL.Query().Get("sort"); sort == "random" || sort == "stable" {
+			respondWithError(w, r, http.StatusBadRequest, "sort="+sort+" can't be combined with "+name)
+			return
+		}
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+	case "ndjson":
+		if sort := r.URL.Query().Get("sort"); sort != "" && sort != "id" {
+			respondWithError(w, r, http.StatusBadRequest, "format=ndjson only supports the default sort")
+			return
+		}
+
+		h.streamJokesNDJSON(w, r)
+		return
+	default:
+		respondWithError(w, r, http.StatusBadRequest, "Invalid format parameter, expected json or ndjson")
+		return
+	}
+
+	limit, offset := parsePagination(w, r)
+	if !allowOffset(w, r, offset, h.opts.MaxOffset) {
+		return
+	}
+
+	switch r.URL.Query().Get("sort") {
+	case "", "id":
+	case "random":
+		seed := r.URL.Query().Get("seed")
+		if seed == "" {
+			respondWithError(w, r, http.StatusBadRequest, "sort=random requires a seed parameter")
+			return
+		}
+
+		h.listShuffledJokes(w, r, seed, limit, offset)
+		return
+	case "stable":
+		h.listStableJokes(w, r, limit, offset)
+		return
+	default:
+		respondWithError(w, r, http.StatusBadRequest, "Invalid sort parameter, expected id, random or stable")
+		return
+	}
+
+	if period != "" || regex != "" || created {
+		filter, ok := h.listFilter(w, r)
+		if !ok {
+			return
+		}
+
+		h.listFilteredJokes(w, r, filter, limit, offset)
+		return
+	}
+
+	if r.URL.Query().Get("include_total") != "false" {
+		jokes, err := h.repo.ListJokes(r.Context(), limit, offset)
+		if err != nil {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve jokes")
+			return
+		}
+
+		total, err := h.repo.CountJokes(r.Context())
+		if err != nil {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to count jokes")
+			return
+		}
+
+		hasMore := offset+len(jokes) < total
+		setPaginationLinks(w, r, limit, offset, &total, hasMore)
+
+		response := JokeListResponse{
+			Jokes:      jokes,
+			Total:      &total,
+			TotalPages: totalPages(total, limit),
+			Limit:      limit,
+			Offset:     offset,
+			HasMore:    hasMore,
+			OutOfRange: outOfRange(total, offset),
+		}
+		if err := markEmptyCollection(r.Context(), h.repo, &response, total); err != nil {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to count jokes")
+			return
+		}
+
+		applyTimeFormat(r, jokes...)
+		respondWithJSON(w, http.StatusOK, response)
 		return
 	}
 
-	total, err := h.repo.CountJokes(r.Context())
+	// Fetch one extra row to learn whether there is a next page without
+	// having to count.
+	jokes, err := h.repo.ListJokes(r.Context(), limit+1, offset)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to count jokes")
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve jokes")
 		return
 	}
 
+	hasMore := len(jokes) > limit
+	if hasMore {
+		jokes = jokes[:limit]
+	}
+
+	setPaginationLinks(w, r, limit, offset, nil, hasMore)
+
 	response := JokeListResponse{
-		Jokes:  jokes,
-		Total:  total,
-		Limit:  limit,
-		Offset: offset,
+		Jokes:   jokes,
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: hasMore,
+	}
+	if err := markEmptyCollection(r.Context(), h.repo, &response, -1); err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to count jokes")
+		return
 	}
 
+	applyTimeFormat(r, jokes...)
 	respondWithJSON(w, http.StatusOK, response)
 }
 
+// GetJoke handles GET and HEAD /api/joke/{id}. For HEAD the server drops
+// the body, leaving the status and cache validators. Jokes can be templates
+// with {name} and {name=default} placeholders, which ?vars=name:value,...
+// fills in.
 func (h *JokeHandler) GetJoke(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r, "reveal", "time_format", "vars") || !allowTimeFormat(w, r) {
+		return
+	}
+
+	// ?vars fills in the placeholders of template jokes. Without it the
+	// text is returned as stored.
+	var vars map[string]string
+	if r.URL.Query().Has("vars") {
+		parsed, err := parseTemplateVars(r.URL.Query()["vars"])
+		if err != nil {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid vars parameter: "+err.Error())
+			return
+		}
+		vars = parsed
+	}
+
+	reveal := true
+	if v := r.URL.Query().Get("reveal"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid reveal parameter")
+			return
+		}
+		reveal = parsed
+	}
+
 	// Parse joke ID from URL
-	idParam := chi.URLParam(r, "id")
-	id, err := strconv.ParseInt(idParam, 10, 64)
-	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid joke ID")
+	id, ok := h.jokeID(w, r)
+	if !ok {
 		return
 	}
 
@@ -90,143 +493,434 @@ func (h *JokeHandler) GetJoke(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		// Check if it's a "not found" error
 		if errors.Is(err, repository.ErrJokeNotFound) {
-			respondWithError(w, http.StatusNotFound, "Joke not found")
+			respondWithError(w, r, http.StatusNotFound, "Joke not found")
+			return
+		}
+
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve joke")
+		return
+	}
+
+	if vars != nil {
+		if err := renderJokeTemplate(joke, vars); err != nil {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid vars parameter: "+err.Error())
 			return
 		}
+	}
+
+	// Views and access times are buffered and written in batches, so the
+	// read never waits on a write. HEAD requests only probe for existence
+	// and freshness, so they don't count.
+	if r.Method != http.MethodHead && h.opts.Views != nil {
+		h.opts.Views.Record(id)
+	}
+
+	// Without reveal only the setup is returned, so clients can show the
+	// punchline later. The validators describe the full joke, so they are
+	// left out for that representation.
+	if reveal {
+		setJokeCacheHeaders(w, joke)
+		if notModified(r, jokeETag(joke), joke.UpdatedAt) && respondNotModified(w, r) {
+			return
+		}
+	} else {
+		joke.Text = joke.Setup
+		joke.Punchline = ""
+	}
+
+	applyTimeFormat(r, joke)
 
-		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve joke")
+	w.Header().Add("Vary", "Accept")
+	if acceptsMultipart(r) {
+		respondWithMultipart(w, http.StatusOK, joke)
 		return
 	}
 
 	respondWithJSON(w, http.StatusOK, joke)
 }
 
+// GetRandomJoke handles GET /api/joke/random. With ?seed= the pick is
+// deterministic: the same seed returns the same joke for as long as the set
+// of jokes doesn't change. Adding or removing jokes remaps seeds. With
+// ?clean=true jokes containing words from the profanity list are skipped,
+// and with ?max_length=N jokes longer than N characters. ?count=N returns a
+// list of up to N distinct random jokes instead of a single one.
 func (h *JokeHandler) GetRandomJoke(w http.ResponseWriter, r *http.Request) {
-	joke, err := h.repo.GetRandomJoke(r.Context())
+	if !h.allowParams(w, r, "seed", "clean", "max_length", "count", "time_format") || !allowTimeFormat(w, r) {
+		return
+	}
+
+	if r.URL.Query().Has("count") {
+		if r.URL.Query().Has("seed") || r.URL.Query().Has("clean") || r.URL.Query().Has("max_length") {
+			respondWithError(w, r, http.StatusBadRequest, "The count parameter cannot be combined with seed, clean or max_length")
+			return
+		}
+
+		count, ok := parseRandomCount(w, r)
+		if !ok {
+			return
+		}
+
+		h.getRandomJokes(w, r, count)
+		return
+	}
+
+	clean := false
+	if v := r.URL.Query().Get("clean"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid clean parameter")
+			return
+		}
+		clean = parsed
+	}
+
+	maxLength := 0
+	if v := r.URL.Query().Get("max_length"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid max_length parameter")
+			return
+		}
+		maxLength = parsed
+	}
+
+	seed := r.URL.Query().Get("seed")
+	if seed != "" && (clean || maxLength > 0) {
+		respondWithError(w, r, http.StatusBadRequest, "The seed parameter cannot be combined with clean or max_length")
+		return
+	}
+
+	if seed != "" {
+		h.getSeededJoke(w, r, seed)
+		return
+	}
+
+	// Without a word list every joke is clean.
+	if clean && h.opts.Profanity != nil {
+		h.getCleanJoke(w, r, maxLength)
+		return
+	}
+
+	var (
+		joke *model.Joke
+		err  error
+	)
+	if maxLength > 0 {
+		joke, err = h.repo.GetRandomJokeMaxLength(r.Context(), maxLength)
+	} else {
+		joke, err = h.repo.GetRandomJoke(r.Context())
+	}
 	if err != nil {
 		if errors.Is(err, repository.ErrNoJokes) {
-			respondWithError(w, http.StatusNotFound, "No jokes available")
+			if maxLength > 0 {
+				respondWithError(w, r, http.StatusNotFound, "No jokes fit the maximum length")
+				return
+			}
+
+			h.respondNoJokes(w, r)
 			return
 		}
 
-		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve random joke")
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve random joke")
 		return
 	}
 
+	applyTimeFormat(r, joke)
 	respondWithJSON(w, http.StatusOK, joke)
 }
 
+// respondNoJokes answers a request for a single random joke when there are
+// no jokes at all: with the configured placeholder joke, or with 404. The
+// placeholder has no ID and is marked with the X-Fallback-Joke header.
+func (h *JokeHandler) respondNoJokes(w http.ResponseWriter, r *http.Request) {
+	if h.opts.EmptyRandomFallback == "" {
+		respondWithError(w, r, http.StatusNotFound, "No jokes available")
+		return
+	}
+
+	w.Header().Set("X-Fallback-Joke", "true")
+	respondWithJSON(w, http.StatusOK, &model.Joke{
+		Text:  h.opts.EmptyRandomFallback,
+		Setup: h.opts.EmptyRandomFallback,
+	})
+}
+
+// CreateJokeRequest is the body of create, update and validate requests. It
+// holds either Text or both Setup and Punchline.
 type CreateJokeRequest struct {
-	Text string `json:"text"`
+	Text      string `json:"text"`
+	Setup     string `json:"setup"`
+	Punchline string `json:"punchline"`
 }
 
 // CreateJoke handles POST /api/admin/jokes
 func (h *JokeHandler) CreateJoke(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r, "time_format") || !allowTimeFormat(w, r) {
+		return
+	}
+
 	var req CreateJokeRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+	if !h.decodeBody(w, r, &req) {
 		return
 	}
 
-	if req.Text == "" {
-		respondWithError(w, http.StatusBadRequest, "Joke text is required")
+	joke, err := h.prepareJoke(req)
+	if err != nil {
+		respondWithError(w, r, textErrorStatus(err), textErrorMessage(err))
 		return
 	}
 
-	joke := &model.Joke{
-		Text: req.Text,
+	if h.throttle != nil {
+		if ok, retryAfter := h.throttle.allow(joke.Text, h.opts.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(max(int(math.Ceil(retryAfter.Seconds())), 1)))
+			respondWithErrorCode(w, r, http.StatusTooManyRequests, errCodeDuplicateSubmission, "The same joke was submitted moments ago, try again later")
+			return
+		}
 	}
 
-	id, err := h.repo.CreateJoke(r.Context(), joke)
+	// A joke whose normalized text is already stored is not created again:
+	// the existing joke is returned with 200 instead of 201.
+	results, err := h.repo.GetOrCreateJokes(r.Context(), []*model.Joke{joke})
 	if err != nil {
-		h.logger.Error("Failed to create joke", slog.String("error", err.Error()))
-		respondWithError(w, http.StatusInternalServerError, "Failed to create joke")
+		respondWithWriteError(w, r, h.log(r), err, "Failed to create joke")
 		return
 	}
+	result := results[0]
 
-	// Get the created joke
-	createdJoke, err := h.repo.GetJoke(r.Context(), id)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Joke created but failed to retrieve")
-		return
+	status := http.StatusOK
+	if result.Created {
+		status = http.StatusCreated
 	}
 
-	w.Header().Set("Location", "/api/jokes/"+strconv.FormatInt(id, 10))
-	respondWithJSON(w, http.StatusCreated, createdJoke)
+	w.Header().Set("Location", "/api/joke/"+jokeRef(result.Joke))
+	applyTimeFormat(r, result.Joke)
+	setJokeCacheHeaders(w, result.Joke)
+	respondWithJSON(w, status, result.Joke)
 }
 
+// UpdateJoke handles PUT /api/admin/joke/{id}. A missing joke is created at
+// that ID with 201 when PutCreatesMissing is set or the client sends
+// Prefer: create-if-missing, and answered with 404 otherwise.
 func (h *JokeHandler) UpdateJoke(w http.ResponseWriter, r *http.Request) {
-	idParam := chi.URLParam(r, "id")
-	id, err := strconv.ParseInt(idParam, 10, 64)
-	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid joke ID")
+	if !h.allowParams(w, r, "time_format") || !allowTimeFormat(w, r) {
+		return
+	}
+
+	id, ok := h.jokeID(w, r)
+	if !ok {
 		return
 	}
 
 	var req CreateJokeRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+	if !h.decodeBody(w, r, &req) {
 		return
 	}
 
-	if req.Text == "" {
-		respondWithError(w, http.StatusBadRequest, "Joke text is required")
+	joke, err := h.prepareJoke(req)
+	if err != nil {
+		respondWithError(w, r, textErrorStatus(err), textErrorMessage(err))
 		return
 	}
 
 	_, err = h.repo.GetJoke(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, repository.ErrJokeNotFound) {
-			respondWithError(w, http.StatusNotFound, "Joke not found")
+			if h.createsMissing(w, r) {
+				h.createJokeAt(w, r, id, joke)
+				return
+			}
+
+			respondWithError(w, r, http.StatusNotFound, "Joke not found")
 			return
 		}
 
-		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve joke")
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve joke")
 		return
 	}
 
-	joke := &model.Joke{
-		ID:   id,
-		Text: req.Text,
-	}
+	joke.ID = id
 
 	if err := h.repo.UpdateJoke(r.Context(), joke); err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to update joke")
+		// The joke may have been deleted since it was looked up.
+		if errors.Is(err, repository.ErrJokeNotFound) {
+			respondWithError(w, r, http.StatusNotFound, "Joke not found")
+			return
+		}
+
+		respondWithWriteError(w, r, h.log(r), err, "Failed to update joke")
 		return
 	}
 
 	updatedJoke, err := h.repo.GetJoke(r.Context(), id)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Joke updated but failed to retrieve")
+		respondWithError(w, r, http.StatusInternalServerError, "Joke updated but failed to retrieve")
 		return
 	}
 
+	applyTimeFormat(r, updatedJoke)
+	setJokeCacheHeaders(w, updatedJoke)
 	respondWithJSON(w, http.StatusOK, updatedJoke)
 }
 
-func (h *JokeHandler) DeleteJoke(w http.ResponseWriter, r *http.Request) {
-	idParam := chi.URLParam(r, "id")
-	id, err := strconv.ParseInt(idParam, 10, 64)
+// createsMissing reports whether an update of a missing joke should create
+// it, noting in Preference-Applied when the client asked for that.
+func (h *JokeHandler) createsMissing(w http.ResponseWriter, r *http.Request) bool {
+	if h.opts.PublicIDs {
+		return false
+	}
+
+	if hasPreference(r, "create-if-missing") {
+		w.Header().Set("Preference-Applied", "create-if-missing")
+		return true
+	}
+
+	return h.opts.PutCreatesMissing
+}
+
+// createJokeAt creates joke at id for a PUT to a missing joke.
+func (h *JokeHandler) createJokeAt(w http.ResponseWriter, r *http.Request, id int64, joke *model.Joke) {
+	// A concurrent PUT to the same ID gets a unique violation, so it
+	// answers 409 rather than overwriting this one.
+	if err := h.repo.CreateJokeWithID(r.Context(), id, joke); err != nil {
+		respondWithWriteError(w, r, h.log(r), err, "Failed to create joke")
+		return
+	}
+
+	createdJoke, err := h.repo.GetJoke(r.Context(), id)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid joke ID")
+		respondWithError(w, r, http.StatusInternalServerError, "Joke created but failed to retrieve")
+		return
+	}
+
+	w.Header().Set("Location", "/api/joke/"+jokeRef(createdJoke))
+	applyTimeFormat(r, createdJoke)
+	setJokeCacheHeaders(w, createdJoke)
+	respondWithJSON(w, http.StatusCreated, createdJoke)
+}
+
+func (h *JokeHandler) DeleteJoke(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r, "idempotent") {
 		return
 	}
 
-	if err := h.repo.DeleteJoke(r.Context(), id); err != nil {
+	// Retrying clients can opt in to treating an already deleted joke as success.
+	idempotent := r.URL.Query().Get("idempotent") == "true"
+
+	id, err := h.parseJokeID(r)
+	if err == nil {
+		err = h.repo.DeleteJoke(r.Context(), id)
+	}
+
+	if err != nil {
+		if errors.Is(err, errInvalidJokeID) {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid joke ID")
+			return
+		}
+
 		if errors.Is(err, repository.ErrJokeNotFound) {
-			respondWithError(w, http.StatusNotFound, "Joke not found")
+			if idempotent {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			respondWithError(w, r, http.StatusNotFound, "Joke not found")
 			return
 		}
 
-		respondWithError(w, http.StatusInternalServerError, "Failed to delete joke")
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to delete joke")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// allowParams declares the query parameters an endpoint understands. In
+// strict mode it responds with 400 naming the first unknown parameter and
+// reports false; otherwise unknown parameters are ignored.
+func (h *JokeHandler) allowParams(w http.ResponseWriter, r *http.Request, known ...string) bool {
+	return allowQueryParams(w, r, h.opts, known...)
+}
+
+// allowQueryParams implements allowParams for handlers configured with opts.
+func allowQueryParams(w http.ResponseWriter, r *http.Request, opts Options, known ...string) bool {
+	if !opts.StrictParams {
+		return true
+	}
+
+	names := make([]string, 0, len(r.URL.Query()))
+	for name := range r.URL.Query() {
+		if !slices.Contains(known, name) {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return true
+	}
+
+	slices.Sort(names)
+	respondWithError(w, r, http.StatusBadRequest, fmt.Sprintf("Unknown query parameter %q", names[0]))
+	return false
+}
+
+var errInvalidJokeID = errors.New("invalid joke ID")
+
+// parseJokeID resolves the {id} URL parameter to the internal joke ID. It
+// returns errInvalidJokeID for malformed IDs and repository.ErrJokeNotFound
+// for public IDs that don't belong to any joke.
+func (h *JokeHandler) parseJokeID(r *http.Request) (int64, error) {
+	return resolveJokeID(r.Context(), h.repo, h.opts, chi.URLParam(r, "id"))
+}
+
+// resolveJokeID maps a client supplied joke ID to the internal one according
+// to the configured ID scheme.
+func resolveJokeID(ctx context.Context, repo repository.JokeRepository, opts Options, value string) (int64, error) {
+	if !opts.PublicIDs {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, errInvalidJokeID
+		}
+		return id, nil
+	}
+
+	if _, err := uuid.Parse(value); err != nil {
+		return 0, errInvalidJokeID
+	}
+
+	return repo.ResolvePublicID(ctx, value)
+}
+
+// jokeID is parseJokeID for handlers that only act on existing jokes. It
+// writes the error response itself and reports whether to continue.
+func (h *JokeHandler) jokeID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := h.parseJokeID(r)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidJokeID):
+			respondWithError(w, r, http.StatusBadRequest, "Invalid joke ID")
+		case errors.Is(err, repository.ErrJokeNotFound):
+			respondWithError(w, r, http.StatusNotFound, "Joke not found")
+		default:
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve joke")
+		}
+		return 0, false
+	}
+
+	return id, true
+}
+
+// jokeRef is the ID clients use to address a joke in URLs.
+func jokeRef(joke *model.Joke) string {
+	if joke.PublicID != "" {
+		return joke.PublicID
+	}
+
+	return strconv.FormatInt(joke.ID, 10)
+}
+
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	response, err := json.Marshal(payload)
 	if err != nil {
@@ -240,8 +934,27 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	w.Write(response)
 }
 
-func respondWithError(w http.ResponseWriter, code int, message string) {
+func respondWithError(w http.ResponseWriter, r *http.Request, code int, message string) {
 	respondWithJSON(w, code, ErrorResponse{
-		Error: message,
+		Error:     message,
+		RequestID: middleware.GetReqID(r.Context()),
 	})
 }
+
+// requestLogger returns logger annotated with the ID of the request, so log
+// lines can be matched with the request_id of an error response.
+func requestLogger(logger *slog.Logger, r *http.Request) *slog.Logger {
+	if id := middleware.GetReqID(r.Context()); id != "" {
+		return logger.With(slog.String("request_id", id))
+	}
+
+	return logger
+}
+
+func (h *JokeHandler) log(r *http.Request) *slog.Logger {
+	return requestLogger(h.logger, r)
+}
+
+func (h *JokeHandler) decodeBody(w http.ResponseWriter, r *http.Request, dst any) bool {
+	return decodeJSONBodyLogged(w, r, dst, h.log(r), h.opts.LogFailedBodyBytes)
+}
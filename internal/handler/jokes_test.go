@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTotalPages(t *testing.T) {
+	tests := []struct {
+		name         string
+		total, limit int
+		want         int
+	}{
+		{"empty", 0, 20, 0},
+		{"exact multiple", 40, 20, 2},
+		{"remainder", 41, 20, 3},
+		{"fewer than limit", 5, 20, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := *totalPages(tt.total, tt.limit); got != tt.want {
+				t.Errorf("totalPages(%d, %d) = %d, want %d", tt.total, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePaginationPrefer(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		prefer      []string
+		wantLimit   int
+		wantApplied string
+	}{
+		{"no preference", "", nil, 10, ""},
+		{"max-count", "", []string{"max-count=50"}, 50, "max-count=50"},
+		{"capped", "", []string{"max-count=500"}, maxPreferredPageSize, "max-count=100"},
+		{"among other preferences", "", []string{"return=minimal, max-count=\"25\"; foo=bar"}, 25, "max-count=25"},
+		{"second header", "", []string{"respond-async", "MAX-COUNT=7"}, 7, "max-count=7"},
+		{"invalid", "", []string{"max-count=-3"}, 10, ""},
+		{"limit wins", "?limit=30", []string{"max-count=50"}, 30, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/joke/"+tt.query, nil)
+			for _, v := range tt.prefer {
+				req.Header.Add("Prefer", v)
+			}
+			rec := httptest.NewRecorder()
+
+			limit, _ := parsePagination(rec, req)
+			if limit != tt.wantLimit {
+				t.Errorf("limit = %d, want %d", limit, tt.wantLimit)
+			}
+			if got := rec.Header().Get("Preference-Applied"); got != tt.wantApplied {
+				t.Errorf("Preference-Applied = %q, want %q", got, tt.wantApplied)
+			}
+		})
+	}
+}
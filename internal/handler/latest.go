@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+const (
+	// defaultLatestCount is the number of jokes when ?count is omitted.
+	defaultLatestCount = 5
+
+	// maxLatestCount bounds ?count for GET /api/joke/latest.
+	maxLatestCount = 50
+)
+
+type LatestJokesResponse struct {
+	Jokes []*model.Joke `json:"jokes"`
+	Count int           `json:"count"`
+}
+
+// ListLatestJokes handles GET /api/joke/latest. It returns the ?count most
+// recently added jokes, newest first, for "recently added" widgets that have
+// no use for paging.
+func (h *JokeHandler) ListLatestJokes(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r, "count", "time_format") || !allowTimeFormat(w, r) {
+		return
+	}
+
+	count := defaultLatestCount
+	if v := r.URL.Query().Get("count"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 || parsed > maxLatestCount {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid count parameter, expected 1 to "+strconv.Itoa(maxLatestCount))
+			return
+		}
+		count = parsed
+	}
+
+	jokes, err := h.repo.ListRecentJokes(r.Context(), count)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve latest jokes")
+		return
+	}
+
+	applyTimeFormat(r, jokes...)
+	respondWithJSON(w, http.StatusOK, LatestJokesResponse{
+		Jokes: jokes,
+		Count: len(jokes),
+	})
+}
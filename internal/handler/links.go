@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// setPaginationLinks sets an RFC 5988 Link header with first, prev, next and
+// last page URLs. The last link is only included when total is known.
+func setPaginationLinks(w http.ResponseWriter, r *http.Request, limit, offset int, total *int, hasMore bool) {
+	var links []string
+
+	link := func(rel string, offset int) {
+		query := r.URL.Query()
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set("offset", strconv.Itoa(offset))
+		links = append(links, fmt.Sprintf(`<%s?%s>; rel="%s"`, r.URL.Path, query.Encode(), rel))
+	}
+
+	link("first", 0)
+
+	if offset > 0 {
+		link("prev", max(offset-limit, 0))
+	}
+
+	if hasMore {
+		link("next", offset+limit)
+	}
+
+	if total != nil {
+		lastOffset := 0
+		if *total > 0 {
+			lastOffset = (*total - 1) / limit * limit
+		}
+		link("last", lastOffset)
+	}
+
+	w.Header().Add("Link", strings.Join(links, ", "))
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func TestListJokesLinks(t *testing.T) {
+	h, repo := newTestJokeHandler(t, Options{})
+	for i := 0; i < 5; i++ {
+		if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: fmt.Sprintf("joke %d", i)}); err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  map[string]string
+	}{
+		{"limit=2&offset=0", map[string]string{
+			"first": "/api/joke/?limit=2&offset=0",
+			"next":  "/api/joke/?limit=2&offset=2",
+			"last":  "/api/joke/?limit=2&offset=4",
+		}},
+		{"limit=2&offset=2", map[string]string{
+			"first": "/api/joke/?limit=2&offset=0",
+			"prev":  "/api/joke/?limit=2&offset=0",
+			"next":  "/api/joke/?limit=2&offset=4",
+			"last":  "/api/joke/?limit=2&offset=4",
+		}},
+		{"limit=2&offset=4", map[string]string{
+			"first": "/api/joke/?limit=2&offset=0",
+			"prev":  "/api/joke/?limit=2&offset=2",
+			"last":  "/api/joke/?limit=2&offset=4",
+		}},
+		// Without the total there is no last page to link to.
+		{"limit=2&offset=2&include_total=false", map[string]string{
+			"first": "/api/joke/?include_total=false&limit=2&offset=0",
+			"prev":  "/api/joke/?include_total=false&limit=2&offset=0",
+			"next":  "/api/joke/?include_total=false&limit=2&offset=4",
+		}},
+	}
+
+	for _, tt := range tests {
+		rec := serveRoute(h.ListJokes, http.MethodGet, "/api/joke/", "/api/joke/?"+tt.query, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want %d", tt.query, rec.Code, http.StatusOK)
+		}
+
+		got := make(map[string]string)
+		for _, link := range strings.Split(rec.Header().Get("Link"), ", ") {
+			target, params, ok := strings.Cut(link, "; ")
+			rel, found := strings.CutPrefix(params, "rel=")
+			if !ok || !found {
+				t.Fatalf("%s: malformed link %q", tt.query, link)
+			}
+			got[strings.Trim(rel, `"`)] = strings.Trim(target, "<>")
+		}
+
+		if !maps.Equal(got, tt.want) {
+			t.Errorf("%s: links = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
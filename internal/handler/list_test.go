@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// countingRepository counts the calls that count all jokes.
+type countingRepository struct {
+	repository.JokeRepository
+	counts atomic.Int32
+}
+
+func (c *countingRepository) CountJokes(ctx context.Context) (int, error) {
+	c.counts.Add(1)
+	return c.JokeRepository.CountJokes(ctx)
+}
+
+func TestListJokesHasMore(t *testing.T) {
+	_, repo := newTestJokeHandler(t, Options{})
+	for i := 0; i < 5; i++ {
+		if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: fmt.Sprintf("joke %d", i)}); err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+	}
+
+	counting := &countingRepository{JokeRepository: repo}
+	h := NewJokeHandler(counting, slog.New(slog.NewTextHandler(io.Discard, nil)), Options{})
+
+	tests := []struct {
+		query    string
+		jokes    int
+		hasMore  bool
+		hasTotal bool
+		counts   int32
+	}{
+		{"limit=2&offset=0", 2, true, true, 1},
+		{"limit=2&offset=3", 2, false, true, 1},
+		{"limit=5&offset=0", 5, false, true, 1},
+		{"limit=2&offset=0&include_total=false", 2, true, false, 0},
+		{"limit=2&offset=3&include_total=false", 2, false, false, 0},
+		{"limit=5&offset=0&include_total=false", 5, false, false, 0},
+	}
+
+	for _, tt := range tests {
+		counting.counts.Store(0)
+
+		rec := serveRoute(h.ListJokes, http.MethodGet, "/joke/", "/joke/?"+tt.query, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want %d", tt.query, rec.Code, http.StatusOK)
+		}
+
+		var list JokeListResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+			t.Fatalf("%s: decoding list: %v", tt.query, err)
+		}
+		if len(list.Jokes) != tt.jokes || list.HasMore != tt.hasMore {
+			t.Errorf("%s: got %d jokes, has_more %t, want %d, %t", tt.query, len(list.Jokes), list.HasMore, tt.jokes, tt.hasMore)
+		}
+		if (list.Total != nil) != tt.hasTotal {
+			t.Errorf("%s: total = %v, want it present: %t", tt.query, list.Total, tt.hasTotal)
+		}
+		if got := counting.counts.Load(); got != tt.counts {
+			t.Errorf("%s: counted jokes %d times, want %d", tt.query, got, tt.counts)
+		}
+	}
+}
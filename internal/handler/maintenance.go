@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/treboc/huhu-api/internal/consistency"
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+type OrphanCleanupResponse struct {
+	Deleted map[string]int64 `json:"deleted"`
+}
+
+// HandleOrphanCleanup returns the handler for POST /api/admin/maintenance/orphans,
+// which runs the orphaned row cleanup immediately.
+func HandleOrphanCleanup(checker *consistency.Checker, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deleted, err := checker.Check(r.Context())
+		if err != nil {
+			requestLogger(logger, r).Error("Failed to delete orphaned rows", slog.String("error", err.Error()))
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to delete orphaned rows")
+			return
+		}
+
+		respondWithJSON(w, http.StatusOK, OrphanCleanupResponse{Deleted: deleted})
+	}
+}
+
+type SchemaVersionResponse struct {
+	Version    int                      `json:"version"`
+	Migrations []*model.SchemaMigration `json:"migrations"`
+}
+
+// HandleSchemaVersion returns the handler for GET /api/admin/schema-version,
+// reporting the latest applied migration and the full migration history.
+func HandleSchemaVersion(repo repository.MaintenanceRepository, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		migrations, err := repo.ListAppliedMigrations(r.Context())
+		if err != nil {
+			requestLogger(logger, r).Error("Failed to list migrations", slog.String("error", err.Error()))
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve schema version")
+			return
+		}
+
+		var version int
+		if len(migrations) > 0 {
+			version = migrations[len(migrations)-1].Version
+		}
+
+		respondWithJSON(w, http.StatusOK, SchemaVersionResponse{
+			Version:    version,
+			Migrations: migrations,
+		})
+	}
+}
+
+// normalizeBatchSize is how many jokes HandleRenormalize fixes per
+// transaction.
+const normalizeBatchSize = 500
+
+// HandleRenormalize returns the handler for POST /api/admin/jokes/normalize,
+// which recomputes the normalized text hash and length of every joke, for
+// example for rows written before normalization existed or under older
+// rules. Progress is logged after every batch; the response has the totals.
+func HandleRenormalize(repo repository.MaintenanceRepository, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := requestLogger(logger, r)
+
+		done, err := repo.RenormalizeJokes(r.Context(), normalizeBatchSize, func(p repository.NormalizeProgress) {
+			log.Info("Normalizing jokes", "batches", p.Batches, "scanned", p.Scanned, "updated", p.Updated)
+		})
+		if err != nil {
+			log.Error("Failed to normalize jokes", slog.String("error", err.Error()), "scanned", done.Scanned, "updated", done.Updated)
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to normalize jokes")
+			return
+		}
+
+		log.Info("Normalized jokes", "scanned", done.Scanned, "updated", done.Updated)
+		respondWithJSON(w, http.StatusOK, done)
+	}
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// markdownEscaper backslash-escapes characters that have inline meaning in
+// Markdown anywhere in a line.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`<`, `\<`,
+	`>`, `\>`,
+	`#`, `\#`,
+	`|`, `\|`,
+	`~`, `\~`,
+	`&`, `\&`,
+)
+
+// escapeMarkdown makes text render literally in Markdown. Besides the inline
+// characters, markers that only mean something at the start of a line,
+// such as list bullets and ordered list numbers, are escaped there.
+func escapeMarkdown(text string) string {
+	lines := strings.Split(markdownEscaper.Replace(text), "\n")
+
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := line[:len(line)-len(trimmed)]
+
+		switch {
+		case trimmed == "":
+		case strings.ContainsRune("-+=", rune(trimmed[0])):
+			lines[i] = indent + `\` + trimmed
+		default:
+			digits := len(trimmed) - len(strings.TrimLeft(trimmed, "0123456789"))
+			if digits > 0 && digits < len(trimmed) && strings.ContainsRune(".)", rune(trimmed[digits])) {
+				lines[i] = indent + trimmed[:digits] + `\` + trimmed[digits:]
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// ExportJokesMarkdown handles GET /api/admin/jokes/export.md. It renders all
+// jokes as a numbered list in a Markdown document.
+func (h *JokeHandler) ExportJokesMarkdown(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r) {
+		return
+	}
+
+	var out bytes.Buffer
+	if err := h.writeMarkdownExport(r, &out); err != nil {
+		h.log(r).Error("Failed to write Markdown export", slog.String("error", err.Error()))
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to export jokes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="jokes.md"`)
+	w.Write(out.Bytes())
+}
+
+// writeMarkdownExport writes all jokes to out as a Markdown numbered list.
+func (h *JokeHandler) writeMarkdownExport(r *http.Request, out io.Writer) error {
+	if _, err := io.WriteString(out, "# Jokes\n\n"); err != nil {
+		return err
+	}
+
+	n := 0
+	return h.repo.EachJoke(r.Context(), func(joke *model.Joke) error {
+		n++
+
+		// Continuation lines are indented to stay inside the list item.
+		text := strings.ReplaceAll(escapeMarkdown(joke.Text), "\n", "\n   ")
+		_, err := fmt.Fprintf(out, "%d. %s\n", n, text)
+		return err
+	})
+}
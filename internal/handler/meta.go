@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"net/http"
+)
+
+// ServerLimits are the limits enforced outside the handlers that GET
+// /api/meta reports. Zero means no limit.
+type ServerLimits struct {
+	MaxURLLength int
+	MaxStreams   int
+}
+
+// MetaResponse describes the limits a client has to stay within. Limits
+// that aren't configured are left out.
+type MetaResponse struct {
+	Pagination MetaPagination `json:"pagination"`
+	Limits     MetaLimits     `json:"limits"`
+
+	// ListFormats are the values ?format accepts on the joke list.
+	ListFormats []string `json:"list_formats"`
+	// MediaTypes are the types a single joke can be requested in with
+	// the Accept header.
+	MediaTypes []string `json:"media_types"`
+	// TimeFormats are the values ?time_format accepts.
+	TimeFormats []string `json:"time_formats"`
+}
+
+type MetaPagination struct {
+	DefaultPageSize      int `json:"default_page_size"`
+	MaxPreferredPageSize int `json:"max_preferred_page_size"`
+	MaxOffset            int `json:"max_offset,omitempty"`
+}
+
+type MetaLimits struct {
+	MaxRandomCount                   int     `json:"max_random_count"`
+	MaxURLLength                     int     `json:"max_url_length,omitempty"`
+	MaxStreams                       int     `json:"max_streams,omitempty"`
+	DuplicateSubmissionWindowSeconds float64 `json:"duplicate_submission_window_seconds,omitempty"`
+}
+
+// HandleMeta returns the handler for GET /api/meta, which reports the
+// effective limits of opts and server so clients can configure themselves.
+func HandleMeta(opts Options, server ServerLimits) http.HandlerFunc {
+	meta := MetaResponse{
+		Pagination: MetaPagination{
+			DefaultPageSize:      defaultPageSize,
+			MaxPreferredPageSize: maxPreferredPageSize,
+			MaxOffset:            opts.MaxOffset,
+		},
+		Limits: MetaLimits{
+			MaxRandomCount:                   maxRandomCount,
+			MaxURLLength:                     server.MaxURLLength,
+			MaxStreams:                       server.MaxStreams,
+			DuplicateSubmissionWindowSeconds: opts.DuplicateSubmissionWindow.Seconds(),
+		},
+		ListFormats: []string{"json", "ndjson"},
+		MediaTypes:  []string{"application/json", "multipart/mixed"},
+		TimeFormats: []string{"rfc3339", "unix"},
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondWithJSON(w, http.StatusOK, meta)
+	}
+}
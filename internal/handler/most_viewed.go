@@ -0,0 +1,45 @@
+package handler
+
+import "net/http"
+
+// ListMostViewedJokes handles GET /api/joke/most-viewed. Jokes that were
+// never viewed are left out.
+func (h *JokeHandler) ListMostViewedJokes(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r, "limit", "offset", "time_format") || !allowTimeFormat(w, r) {
+		return
+	}
+
+	limit, offset := parsePagination(w, r)
+	if !allowOffset(w, r, offset, h.opts.MaxOffset) {
+		return
+	}
+
+	// Fetch one extra row to learn whether there is a next page without
+	// having to count.
+	jokes, err := h.repo.ListMostViewedJokes(r.Context(), limit+1, offset)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve most viewed jokes")
+		return
+	}
+
+	hasMore := len(jokes) > limit
+	if hasMore {
+		jokes = jokes[:limit]
+	}
+
+	setPaginationLinks(w, r, limit, offset, nil, hasMore)
+
+	response := JokeListResponse{
+		Jokes:   jokes,
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: hasMore,
+	}
+	if err := markEmptyCollection(r.Context(), h.repo, &response, -1); err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to count jokes")
+		return
+	}
+
+	applyTimeFormat(r, jokes...)
+	respondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// acceptsMultipart reports whether the Accept header names multipart/mixed.
+// Wildcards don't count: clients sending */* expect plain JSON.
+func acceptsMultipart(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept") {
+		for _, entry := range strings.Split(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(entry))
+			if err != nil || mediaType != "multipart/mixed" {
+				continue
+			}
+			if params["q"] == "0" {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// respondWithMultipart writes joke as a multipart/mixed body with two parts:
+// the JSON representation GetJoke would return, then the joke text as
+// text/plain.
+func respondWithMultipart(w http.ResponseWriter, code int, joke *model.Joke) {
+	data, err := json.Marshal(joke)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Internal Server Error"))
+		return
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     []byte
+	}{
+		{"application/json", data},
+		{"text/plain; charset=utf-8", []byte(joke.Text)},
+	}
+	for _, p := range parts {
+		part, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {p.contentType}})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		part.Write(p.content)
+	}
+	mw.Close()
+
+	w.Header().Set("Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mw.Boundary()}))
+	w.WriteHeader(code)
+	w.Write(body.Bytes())
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// ndjsonFlushEvery is how many jokes are written between flushes of an
+// NDJSON stream.
+const ndjsonFlushEvery = 100
+
+// streamJokesNDJSON writes jokes as newline delimited JSON, one joke object
+// per line, straight from the repository cursor. Without a limit, from
+// either ?limit or the Prefer header, all jokes from the offset on are
+// streamed.
+func (h *JokeHandler) streamJokesNDJSON(w http.ResponseWriter, r *http.Request) {
+	limit, offset := parsePagination(w, r)
+	if !allowOffset(w, r, offset, h.opts.MaxOffset) {
+		return
+	}
+	if !r.URL.Query().Has("limit") && preferredMaxCount(r) == 0 {
+		limit = 0
+	}
+
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+	written := 0
+
+	err := h.repo.StreamJokes(r.Context(), limit, offset, func(joke *model.Joke) error {
+		if written == 0 {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.WriteHeader(http.StatusOK)
+		}
+
+		applyTimeFormat(r, joke)
+		if err := enc.Encode(joke); err != nil {
+			return err
+		}
+
+		written++
+		if written%ndjsonFlushEvery == 0 {
+			return rc.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// Once the first line is out the status can't change anymore; the
+		// client sees a truncated stream.
+		if written > 0 {
+			h.log(r).Error("Failed to stream jokes", slog.String("error", err.Error()), "written", written)
+			return
+		}
+
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve jokes")
+		return
+	}
+
+	// An empty stream still needs its headers.
+	if written == 0 {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+const (
+	// ndjsonImportBatch is how many jokes an NDJSON import stores per
+	// transaction, so the write lock is released regularly during long
+	// imports.
+	ndjsonImportBatch = 500
+
+	// maxNDJSONLineBytes bounds a single line of an NDJSON import. The
+	// stream as a whole is not limited.
+	maxNDJSONLineBytes = 64 << 10
+
+	// maxReportedLineErrors bounds the failed lines listed in an import
+	// response. Skipped lines beyond it are still counted.
+	maxReportedLineErrors = 100
+)
+
+// errCodeInvalidLine marks NDJSON imports aborted at a malformed line.
+const errCodeInvalidLine = "invalid_line"
+
+type NDJSONImportResponse struct {
+	BatchResult
+	Lines    int `json:"lines"`
+	Created  int `json:"created"`
+	Existing int `json:"existing"`
+	Skipped  int `json:"skipped"`
+	Batches  int `json:"batches"`
+}
+
+// ImportJokes handles POST /api/admin/jokes/import?format=ndjson. The body
+// holds one joke per line in the create format, and is read as it arrives
+// rather than buffered, so imports can be arbitrarily large. Jokes are
+// stored in batches, each in its own transaction, and, like with the upsert
+// endpoint, jokes whose text already exists are not created again. The
+// body read timeout, when set, still bounds how long the whole stream may
+// take.
+//
+// The response lists the imported jokes by line number. A malformed line
+// is skipped and listed as failed, answering 207, when
+// Options.NDJSONImportSkipInvalid is set. Otherwise the import stops there
+// with 400, naming the line; the jokes before it stay imported.
+func (h *JokeHandler) ImportJokes(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r, "format") {
+		return
+	}
+
+	if r.URL.Query().Get("format") != "ndjson" {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid format parameter, expected ndjson")
+		return
+	}
+
+	response := NDJSONImportResponse{BatchResult: newBatchResult()}
+	batch := make([]*model.Joke, 0, ndjsonImportBatch)
+	batchLines := make([]int, 0, ndjsonImportBatch)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		results, err := h.repo.GetOrCreateJokes(r.Context(), batch)
+		if err != nil {
+			return err
+		}
+
+		for i, result := range results {
+			response.succeed(batchLines[i], result)
+			if result.Created {
+				response.Created++
+			} else {
+				response.Existing++
+			}
+		}
+		response.Batches++
+		batch, batchLines = batch[:0], batchLines[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 4096), maxNDJSONLineBytes)
+
+	for scanner.Scan() {
+		response.Lines++
+
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		joke, status, code, message := h.parseImportLine(line)
+		if joke == nil {
+			if !h.opts.NDJSONImportSkipInvalid {
+				if err := flush(); err != nil {
+					respondWithWriteError(w, r, h.log(r), err, "Failed to import jokes")
+					return
+				}
+
+				msg := fmt.Sprintf("Line %d: %s; the lines before it were imported", response.Lines, message)
+				respondWithErrorCode(w, r, status, errCodeInvalidLine, msg)
+				return
+			}
+
+			response.Skipped++
+			if len(response.Failed) < maxReportedLineErrors {
+				response.fail(response.Lines, code, message)
+			}
+			continue
+		}
+
+		batch = append(batch, joke)
+		batchLines = append(batchLines, response.Lines)
+		if len(batch) == ndjsonImportBatch {
+			if err := flush(); err != nil {
+				respondWithWriteError(w, r, h.log(r), err, "Failed to import jokes")
+				return
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		switch {
+		case errors.Is(err, os.ErrDeadlineExceeded):
+			w.Header().Set("Connection", "close")
+			respondWithErrorCode(w, r, http.StatusRequestTimeout, errCodeBodyTimeout, "Timed out reading the request body")
+		case errors.Is(err, bufio.ErrTooLong):
+			respondWithErrorCode(w, r, http.StatusBadRequest, errCodeInvalidLine, fmt.Sprintf("Line %d is longer than %d bytes", response.Lines+1, maxNDJSONLineBytes))
+		default:
+			respondWithError(w, r, http.StatusBadRequest, "Failed to read the request body")
+		}
+		return
+	}
+
+	if err := flush(); err != nil {
+		respondWithWriteError(w, r, h.log(r), err, "Failed to import jokes")
+		return
+	}
+
+	h.log(r).Info("Imported jokes", "lines", response.Lines, "created", response.Created, "existing", response.Existing, "skipped", response.Skipped)
+	respondWithJSON(w, response.status(), response)
+}
+
+// parseImportLine decodes and validates one line of an NDJSON import. On
+// failure the joke is nil and status, code and message describe the
+// problem.
+func (h *JokeHandler) parseImportLine(line []byte) (joke *model.Joke, status int, code, message string) {
+	var req CreateJokeRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		return nil, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON"
+	}
+
+	joke, err := h.prepareJoke(req)
+	if err != nil {
+		return nil, textErrorStatus(err), textErrorCode(err), textErrorMessage(err)
+	}
+
+	return joke, 0, "", ""
+}
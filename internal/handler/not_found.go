@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"net/http"
+)
+
+// NotFoundJoke answers requests for unknown API paths with a 404 that still
+// carries a random joke, so nobody leaves empty-handed.
+func (h *JokeHandler) NotFoundJoke(w http.ResponseWriter, r *http.Request) {
+	joke, err := h.repo.GetRandomJoke(r.Context())
+	if err != nil {
+		respondWithError(w, r, http.StatusNotFound, "Not found")
+		return
+	}
+
+	w.Header().Set("X-Joke-Reason", "This route doesn't exist, but here is a joke for your trouble")
+	respondWithJSON(w, http.StatusNotFound, joke)
+}
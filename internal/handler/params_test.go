@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func TestStrictParams(t *testing.T) {
+	newHandler := func(opts Options) *JokeHandler {
+		h, repo := newTestJokeHandler(t, opts)
+		if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "A strict joke"}); err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+		return h
+	}
+	strict := newHandler(Options{StrictParams: true})
+	lenient := newHandler(Options{})
+
+	list := func(h *JokeHandler, target string) (int, string) {
+		rec := serveRoute(h.ListJokes, http.MethodGet, "/joke/", target, "")
+		return rec.Code, rec.Body.String()
+	}
+
+	status, body := list(strict, "/joke/?limt=5&zzz=1")
+	if status != http.StatusBadRequest {
+		t.Fatalf("unknown parameter: status = %d, want %d", status, http.StatusBadRequest)
+	}
+	if !strings.Contains(body, `Unknown query parameter \"limt\"`) {
+		t.Errorf("body = %s, want it to name the first unknown parameter", body)
+	}
+
+	if rec := serveRoute(strict.GetRandomJoke, http.MethodGet, "/joke/random", "/joke/random?limit=5", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("parameter of another endpoint: status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if status, _ := list(strict, "/joke/?limit=5&offset=0"); status != http.StatusOK {
+		t.Errorf("known parameters: status = %d, want %d", status, http.StatusOK)
+	}
+	if status, _ := list(lenient, "/joke/?limt=5"); status != http.StatusOK {
+		t.Errorf("unknown parameter without strict mode: status = %d, want %d", status, http.StatusOK)
+	}
+}
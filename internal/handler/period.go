@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// relativeUnits are the units of relative time expressions. A day is always
+// 24 hours, regardless of daylight saving time.
+var relativeUnits = map[byte]time.Duration{
+	's': time.Second,
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// parseTimeExpression parses an absolute RFC 3339 time, or a time relative
+// to now such as -7d, -24h or -30m: a sign, a whole number and one of the
+// units s, m, h, d or w.
+func parseTimeExpression(expr string, now time.Time) (time.Time, error) {
+	if len(expr) >= 3 && (expr[0] == '-' || expr[0] == '+') {
+		unit, ok := relativeUnits[expr[len(expr)-1]]
+		if !ok {
+			return time.Time{}, errors.New("unknown unit, expected s, m, h, d or w")
+		}
+
+		n, err := strconv.ParseUint(expr[1:len(expr)-1], 10, 32)
+		if err != nil {
+			return time.Time{}, errors.New("expected a whole number before the unit")
+		}
+
+		offset := time.Duration(n) * unit
+		if expr[0] == '-' {
+			offset = -offset
+		}
+		return now.Add(offset), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, expr)
+	if err != nil {
+		return time.Time{}, errors.New("expected an RFC 3339 time or a relative time such as -7d")
+	}
+	return t, nil
+}
+
+// createdRange returns the range [from, to) that ?created_after and
+// ?created_before select. A missing bound is left as the zero time, which
+// leaves that side open.
+func createdRange(r *http.Request, now time.Time) (from, to time.Time, err error) {
+	if v := r.URL.Query().Get("created_after"); v != "" {
+		if from, err = parseTimeExpression(v, now); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("created_after parameter: %w", err)
+		}
+	}
+	if v := r.URL.Query().Get("created_before"); v != "" {
+		if to, err = parseTimeExpression(v, now); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("created_before parameter: %w", err)
+		}
+	}
+
+	return from, to, nil
+}
+
+// periodRange returns the UTC range [from, to) of the calendar period that
+// contains now. Weeks start on Monday. ok is false for unknown periods.
+func periodRange(period string, now time.Time) (from, to time.Time, ok bool) {
+	today := now.UTC().Truncate(24 * time.Hour)
+
+	switch period {
+	case "today":
+		return today, today.AddDate(0, 0, 1), true
+	case "week":
+		// Weekday counts from Sunday; shift it so Monday is 0.
+		from = today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+		return from, from.AddDate(0, 0, 7), true
+	case "month":
+		from = time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return from, from.AddDate(0, 1, 0), true
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+}
+
+// listFilter builds the filter ?period, ?created_after, ?created_before and
+// ?regex ask for. A period is the created_at range of the current day, week
+// or month, so it combines with the created bounds by intersecting the
+// ranges. ok is false once an error response has been written.
+func (h *JokeHandler) listFilter(w http.ResponseWriter, r *http.Request) (filter repository.JokeFilter, ok bool) {
+	now := h.opts.Now()
+
+	from, to, err := createdRange(r, now)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid "+err.Error())
+		return filter, false
+	}
+	filter.CreatedFrom, filter.CreatedTo = from, to
+
+	if period := r.URL.Query().Get("period"); period != "" {
+		from, to, ok := periodRange(period, now)
+		if !ok {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid period parameter, expected today, week or month")
+			return filter, false
+		}
+
+		if from.After(filter.CreatedFrom) {
+			filter.CreatedFrom = from
+		}
+		if filter.CreatedTo.IsZero() || to.Before(filter.CreatedTo) {
+			filter.CreatedTo = to
+		}
+	}
+
+	if pattern := r.URL.Query().Get("regex"); pattern != "" {
+		re, err := compileJokeRegex(pattern)
+		if err != nil {
+			respondWithError(w, r, http.StatusBadRequest, regexErrorMessage(err))
+			return filter, false
+		}
+		filter.Regex = re
+	}
+
+	return filter, true
+}
+
+// listFilteredJokes writes a page of the jokes matching filter.
+func (h *JokeHandler) listFilteredJokes(w http.ResponseWriter, r *http.Request, filter repository.JokeFilter, limit, offset int) {
+	// Fetch one extra row to learn whether there is a next page without
+	// having to count.
+	jokes, err := h.repo.ListJokesFiltered(r.Context(), filter, limit+1, offset)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve jokes")
+		return
+	}
+
+	hasMore := len(jokes) > limit
+	if hasMore {
+		jokes = jokes[:limit]
+	}
+
+	response := JokeListResponse{
+		Jokes:   jokes,
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: hasMore,
+	}
+
+	if r.URL.Query().Get("include_total") != "false" {
+		total, err := h.repo.CountJokesFiltered(r.Context(), filter)
+		if err != nil {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to count jokes")
+			return
+		}
+
+		response.Total = &total
+		response.TotalPages = totalPages(total, limit)
+		response.OutOfRange = outOfRange(total, offset)
+	}
+
+	// The total only covers the filtered jokes, so it can't tell an empty
+	// collection apart.
+	if err := markEmptyCollection(r.Context(), h.repo, &response, -1); err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to count jokes")
+		return
+	}
+
+	setPaginationLinks(w, r, limit, offset, response.Total, hasMore)
+	applyTimeFormat(r, response.Jokes...)
+	respondWithJSON(w, http.StatusOK, response)
+}
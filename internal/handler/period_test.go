@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// datedRepository serves filtered listings from memory.
+type datedRepository struct {
+	repository.JokeRepository
+	jokes []*model.Joke
+}
+
+func (d datedRepository) filtered(filter repository.JokeFilter) []*model.Joke {
+	var jokes []*model.Joke
+	for _, joke := range d.jokes {
+		if !filter.CreatedFrom.IsZero() && joke.CreatedAt.Before(filter.CreatedFrom) {
+			continue
+		}
+		if !filter.CreatedTo.IsZero() && !joke.CreatedAt.Before(filter.CreatedTo) {
+			continue
+		}
+		jokes = append(jokes, joke)
+	}
+	return jokes
+}
+
+func (d datedRepository) ListJokesFiltered(ctx context.Context, filter repository.JokeFilter, limit, offset int) ([]*model.Joke, error) {
+	jokes := d.filtered(filter)
+	jokes = jokes[min(offset, len(jokes)):]
+	return jokes[:min(limit, len(jokes))], nil
+}
+
+func (d datedRepository) CountJokesFiltered(ctx context.Context, filter repository.JokeFilter) (int, error) {
+	return len(d.filtered(filter)), nil
+}
+
+func (d datedRepository) CountJokes(ctx context.Context) (int, error) {
+	return len(d.jokes), nil
+}
+
+func TestListJokesPeriod(t *testing.T) {
+	// A Wednesday.
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	createdAt := []time.Time{
+		time.Date(2024, 4, 30, 23, 59, 0, 0, time.UTC), // 1: last month
+		time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),    // 2: first of the month
+		time.Date(2024, 5, 12, 18, 0, 0, 0, time.UTC),  // 3: last Sunday
+		time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC),   // 4: Monday, start of the week
+		time.Date(2024, 5, 14, 23, 59, 0, 0, time.UTC), // 5: yesterday
+		time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC),   // 6: start of today
+		time.Date(2024, 5, 15, 11, 0, 0, 0, time.UTC),  // 7: this morning
+	}
+
+	var repo datedRepository
+	for i, at := range createdAt {
+		repo.jokes = append(repo.jokes, &model.Joke{ID: int64(i + 1), Text: "joke", CreatedAt: at})
+	}
+
+	h := NewJokeHandler(repo, slog.New(slog.NewTextHandler(io.Discard, nil)), Options{
+		Now: func() time.Time { return now },
+	})
+
+	tests := []struct {
+		query string
+		want  []int64
+	}{
+		{"period=today", []int64{6, 7}},
+		{"period=week", []int64{4, 5, 6, 7}},
+		{"period=month", []int64{2, 3, 4, 5, 6, 7}},
+		// Created bounds narrow the period further.
+		{"period=month&created_before=2024-05-13T00:00:00Z", []int64{2, 3}},
+		{"period=week&created_after=-1d", []int64{5, 6, 7}},
+		{"period=today&created_before=-30d", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ListJokes(rec, httptest.NewRequest(http.MethodGet, "/api/joke/?"+tt.query, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+
+			var resp JokeListResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding list: %v", err)
+			}
+
+			var got []int64
+			for _, joke := range resp.Jokes {
+				got = append(got, joke.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("jokes = %v, want %v", got, tt.want)
+			}
+			if resp.Total == nil || *resp.Total != len(tt.want) {
+				t.Errorf("total = %v, want %d", resp.Total, len(tt.want))
+			}
+		})
+	}
+
+	for _, query := range []string{"?period=year", "?period=week&sort=random&seed=a", "?period=today&format=ndjson"} {
+		rec := httptest.NewRecorder()
+		h.ListJokes(rec, httptest.NewRequest(http.MethodGet, "/api/joke/"+query, nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestParseTimeExpression(t *testing.T) {
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"-7d", time.Date(2024, 5, 8, 12, 0, 0, 0, time.UTC)},
+		{"-24h", time.Date(2024, 5, 14, 12, 0, 0, 0, time.UTC)},
+		{"-30m", time.Date(2024, 5, 15, 11, 30, 0, 0, time.UTC)},
+		{"-2w", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{"+90s", time.Date(2024, 5, 15, 12, 1, 30, 0, time.UTC)},
+		{"2024-05-01T08:00:00+02:00", time.Date(2024, 5, 1, 6, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTimeExpression(tt.expr, now)
+		if err != nil {
+			t.Errorf("parseTimeExpression(%q): %v", tt.expr, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseTimeExpression(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"", "7d", "-7", "-d", "-7y", "-1.5h", "--7d", "-7 d", "yesterday", "2024-05-01"} {
+		if _, err := parseTimeExpression(expr, now); err == nil {
+			t.Errorf("parseTimeExpression(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestListJokesCreatedAfter(t *testing.T) {
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	var repo datedRepository
+	for i, age := range []time.Duration{10 * 24 * time.Hour, 7*24*time.Hour + time.Minute, 7 * 24 * time.Hour, 2 * time.Hour} {
+		repo.jokes = append(repo.jokes, &model.Joke{ID: int64(i + 1), Text: "joke", CreatedAt: now.Add(-age)})
+	}
+
+	h := NewJokeHandler(repo, slog.New(slog.NewTextHandler(io.Discard, nil)), Options{
+		Now: func() time.Time { return now },
+	})
+
+	tests := []struct {
+		query string
+		want  []int64
+	}{
+		// The cutoff of -7d is exactly seven days before now, inclusive.
+		{"?created_after=-7d", []int64{3, 4}},
+		{"?created_after=-7d&created_before=-1d", []int64{3}},
+		{"?created_before=-7d", []int64{1, 2}},
+		{"?created_after=2024-05-15T00:00:00Z", []int64{4}},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ListJokes(rec, httptest.NewRequest(http.MethodGet, "/api/joke/"+tt.query, nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want %d: %s", tt.query, rec.Code, http.StatusOK, rec.Body)
+		}
+
+		var resp JokeListResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decoding list: %v", err)
+		}
+
+		var got []int64
+		for _, joke := range resp.Jokes {
+			got = append(got, joke.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: jokes = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	for _, query := range []string{"?created_after=-7x", "?created_after=last-week", "?created_before=-d", "?created_after=-7d&period=year", "?created_after=-7d&format=ndjson"} {
+		rec := httptest.NewRecorder()
+		h.ListJokes(rec, httptest.NewRequest(http.MethodGet, "/api/joke/"+query, nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
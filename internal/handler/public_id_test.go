@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestPublicIDs(t *testing.T) {
+	repo, err := repository.NewSQLiteJokeRepository(filepath.Join(t.TempDir(), "uuid.db"), repository.Options{PublicIDs: true})
+	if err != nil {
+		t.Fatalf("creating repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	h := NewJokeHandler(repo, slog.New(slog.NewTextHandler(io.Discard, nil)), Options{PublicIDs: true})
+
+	rec := serveRoute(h.CreateJoke, http.MethodPost, "/joke", "/joke", `{"text":"knock knock"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, want %d (body %s)", rec.Code, http.StatusCreated, rec.Body)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decoding created joke: %v", err)
+	}
+	if _, err := uuid.Parse(created.ID); err != nil {
+		t.Fatalf("created joke id %q is not a UUID: %v", created.ID, err)
+	}
+	if location := rec.Header().Get("Location"); location != "/api/joke/"+created.ID {
+		t.Errorf("Location = %q, want /api/joke/%s", location, created.ID)
+	}
+
+	target := "/joke/" + created.ID
+
+	rec = serveRoute(h.GetJoke, http.MethodGet, "/joke/{id}", target, "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"id":"`+created.ID+`"`) {
+		t.Fatalf("get: status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	rec = serveRoute(h.UpdateJoke, http.MethodPut, "/joke/{id}", target, `{"text":"who's there?"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, want %d (body %s)", rec.Code, http.StatusOK, rec.Body)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `"id":"`+created.ID+`"`) || !strings.Contains(body, `"joke":"who's there?"`) {
+		t.Errorf("update body = %s", body)
+	}
+
+	if rec := serveRoute(h.DeleteJoke, http.MethodDelete, "/joke/{id}", target, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if rec := serveRoute(h.GetJoke, http.MethodGet, "/joke/{id}", target, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("get after delete: status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	for _, id := range []string{"42", "not-a-uuid", created.ID + "0"} {
+		if rec := serveRoute(h.GetJoke, http.MethodGet, "/joke/{id}", "/joke/"+id, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET /joke/%s: status = %d, want %d", id, rec.Code, http.StatusBadRequest)
+		}
+		if rec := serveRoute(h.DeleteJoke, http.MethodDelete, "/joke/{id}", "/joke/"+id, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("DELETE /joke/%s: status = %d, want %d", id, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
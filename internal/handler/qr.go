@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/skip2/go-qrcode"
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+const qrCodeSize = 256
+
+// GetJokeQRCode handles GET /api/joke/{id}/qr
+func (h *JokeHandler) GetJokeQRCode(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r) {
+		return
+	}
+
+	id, ok := h.jokeID(w, r)
+	if !ok {
+		return
+	}
+
+	joke, err := h.repo.GetJoke(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrJokeNotFound) {
+			respondWithError(w, r, http.StatusNotFound, "Joke not found")
+			return
+		}
+
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve joke")
+		return
+	}
+
+	png, err := qrcode.Encode(h.jokeURL(r, joke), qrcode.Medium, qrCodeSize)
+	if err != nil {
+		h.log(r).Error("Failed to encode QR code", slog.String("error", err.Error()))
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to generate QR code")
+		return
+	}
+
+	// The canonical URL of a joke never changes, so neither does its QR code.
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.WriteHeader(http.StatusOK)
+	w.Write(png)
+}
+
+// jokeURL returns the absolute canonical URL of a joke.
+func (h *JokeHandler) jokeURL(r *http.Request, joke *model.Joke) string {
+	baseURL := h.opts.BaseURL
+	if baseURL == "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		baseURL = scheme + "://" + r.Host
+	}
+
+	return baseURL + "/api/joke/" + jokeRef(joke)
+}
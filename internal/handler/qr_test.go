@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func TestGetJokeQRCode(t *testing.T) {
+	h, repo := newTestJokeHandler(t, Options{BaseURL: "https://jokes.example"})
+
+	id, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "A scannable joke"})
+	if err != nil {
+		t.Fatalf("seeding joke: %v", err)
+	}
+
+	rec := serveRoute(h.GetJokeQRCode, http.MethodGet, "/joke/{id}/qr", fmt.Sprintf("/joke/%d/qr", id), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Content-Type = %q, want image/png", ct)
+	}
+
+	img, err := png.Decode(rec.Body)
+	if err != nil {
+		t.Fatalf("decoding PNG: %v", err)
+	}
+	if size := img.Bounds().Size(); size.X != qrCodeSize || size.Y != qrCodeSize {
+		t.Errorf("image size = %v, want %dx%d", size, qrCodeSize, qrCodeSize)
+	}
+
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		t.Fatalf("binarizing image: %v", err)
+	}
+	result, err := qrcode.NewQRCodeReader().Decode(bitmap, nil)
+	if err != nil {
+		t.Fatalf("decoding QR code: %v", err)
+	}
+	if want := fmt.Sprintf("https://jokes.example/api/joke/%d", id); result.GetText() != want {
+		t.Errorf("QR code URL = %q, want %q", result.GetText(), want)
+	}
+
+	rec = serveRoute(h.GetJokeQRCode, http.MethodGet, "/joke/{id}/qr", "/joke/9999/qr", "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing joke: status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
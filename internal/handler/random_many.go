@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// maxRandomCount caps ?count on GET /api/joke/random. Larger counts are
+// clamped to it, the same way the Prefer header's max-count is.
+const maxRandomCount = 20
+
+type RandomJokesResponse struct {
+	Jokes []*model.Joke `json:"jokes"`
+	Count int           `json:"count"`
+}
+
+// parseRandomCount parses ?count for GET /api/joke/random. Zero, negative
+// and non-numeric counts are rejected with 400, counts over maxRandomCount
+// are clamped.
+func parseRandomCount(w http.ResponseWriter, r *http.Request) (int, bool) {
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil || count <= 0 {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid count parameter, expected a positive number")
+		return 0, false
+	}
+
+	return min(count, maxRandomCount), true
+}
+
+// getRandomJokes writes up to count distinct random jokes.
+func (h *JokeHandler) getRandomJokes(w http.ResponseWriter, r *http.Request, count int) {
+	jokes, err := h.repo.GetRandomJokes(r.Context(), count)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve random jokes")
+		return
+	}
+
+	if len(jokes) == 0 {
+		respondWithError(w, r, http.StatusNotFound, "No jokes available")
+		return
+	}
+
+	applyTimeFormat(r, jokes...)
+	respondWithJSON(w, http.StatusOK, RandomJokesResponse{
+		Jokes: jokes,
+		Count: len(jokes),
+	})
+}
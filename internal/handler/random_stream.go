@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+const (
+	defaultRandomStreamInterval = 5 * time.Second
+
+	// minRandomStreamInterval keeps a single client from turning the
+	// stream into a tight loop of random picks.
+	minRandomStreamInterval = time.Second
+)
+
+// StreamRandomJokes handles GET /api/joke/random/stream. It writes a random
+// joke as a line of JSON right away and then another one every ?interval
+// until the client disconnects. A tick that finds no jokes is skipped.
+func (h *JokeHandler) StreamRandomJokes(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r, "interval") {
+		return
+	}
+
+	interval := defaultRandomStreamInterval
+	if v := r.URL.Query().Get("interval"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed <= 0 {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid interval parameter")
+			return
+		}
+		if parsed < minRandomStreamInterval {
+			respondWithError(w, r, http.StatusBadRequest, "The interval must be at least "+minRandomStreamInterval.String())
+			return
+		}
+		interval = parsed
+	}
+
+	joke, err := h.repo.GetRandomJoke(r.Context())
+	if err != nil {
+		if errors.Is(err, repository.ErrNoJokes) {
+			respondWithError(w, r, http.StatusNotFound, "No jokes available")
+			return
+		}
+
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve joke")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if joke != nil {
+			if err := enc.Encode(joke); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+
+		// Stop as soon as the client goes away.
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+
+		joke, err = h.repo.GetRandomJoke(r.Context())
+		switch {
+		case errors.Is(err, repository.ErrNoJokes):
+			joke = nil
+		case err != nil:
+			if r.Context().Err() == nil {
+				h.log(r).Error("Failed to stream random jokes", slog.String("error", err.Error()))
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestStreamRandomJokesStopsOnDisconnect(t *testing.T) {
+	repo, err := repository.NewSQLiteJokeRepository(filepath.Join(t.TempDir(), "jokes.db"), repository.Options{})
+	if err != nil {
+		t.Fatalf("creating repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	for _, text := range []string{"First ticker joke", "Second ticker joke"} {
+		if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: text}); err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+	}
+
+	h := NewJokeHandler(repo, slog.New(slog.NewTextHandler(io.Discard, nil)), Options{})
+
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		h.StreamRandomJokes(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/joke/random/stream?interval=1s", nil)
+	if err != nil {
+		t.Fatalf("building request: %v", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Fatalf("Content-Type = %q, want application/x-ndjson", ct)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for i := 0; i < 2; i++ {
+		if !scanner.Scan() {
+			t.Fatalf("stream ended after %d jokes: %v", i, scanner.Err())
+		}
+
+		var joke model.Joke
+		if err := json.Unmarshal(scanner.Bytes(), &joke); err != nil {
+			t.Fatalf("decoding joke %d: %v", i, err)
+		}
+		if joke.Text == "" {
+			t.Errorf("joke %d has no text", i)
+		}
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler kept streaming after the client disconnected")
+	}
+}
+
+func TestStreamRandomJokesRejectsShortInterval(t *testing.T) {
+	h := NewJokeHandler(nil, slog.New(slog.NewTextHandler(io.Discard, nil)), Options{})
+
+	for _, interval := range []string{"10ms", "0s", "soon"} {
+		rec := httptest.NewRecorder()
+		h.StreamRandomJokes(rec, httptest.NewRequest(http.MethodGet, "/joke/random/stream?interval="+interval, nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("interval %q: status = %d, want %d", interval, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
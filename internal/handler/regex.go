@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"errors"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+)
+
+const (
+	// maxRegexLength bounds the length of ?regex patterns.
+	maxRegexLength = 200
+
+	// maxRegexInstructions bounds the size of the compiled ?regex program,
+	// which keeps patterns like (a{100}){100} from slipping past the length
+	// limit.
+	maxRegexInstructions = 1000
+)
+
+var (
+	errRegexTooLong    = errors.New("pattern too long")
+	errRegexTooComplex = errors.New("pattern too complex")
+)
+
+// compileJokeRegex compiles a ?regex pattern. Go's RE2 engine matches in
+// time linear in the input, so bounding the pattern is enough to keep a
+// match cheap; there is no backtracking to blow up.
+func compileJokeRegex(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) > maxRegexLength {
+		return nil, errRegexTooLong
+	}
+
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return nil, err
+	}
+	if len(prog.Inst) > maxRegexInstructions {
+		return nil, errRegexTooComplex
+	}
+
+	return regexp.Compile(pattern)
+}
+
+// regexErrorMessage turns a compileJokeRegex error into a client facing
+// message.
+func regexErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, errRegexTooLong):
+		return "Invalid regex parameter, patterns are limited to " + strconv.Itoa(maxRegexLength) + " characters"
+	case errors.Is(err, errRegexTooComplex):
+		return "Invalid regex parameter, pattern is too complex"
+	default:
+		return "Invalid regex parameter: " + err.Error()
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+type RestoreRequest struct {
+	File string `json:"file"`
+}
+
+type RestoreResponse struct {
+	File     string `json:"file"`
+	Restored int    `json:"restored"`
+}
+
+// backupJoke is a joke as written by the backup job. Its id is the integer
+// ID, or the UUID when the server runs with public IDs.
+type backupJoke struct {
+	model.Joke
+	ID json.RawMessage `json:"id"`
+}
+
+// RestoreBackup handles POST /api/admin/restore. It replaces all jokes with
+// the ones in the named file from the backup directory. The name must be a
+// plain file name, so requests can't reach files outside the directory.
+func (h *JokeHandler) RestoreBackup(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r) {
+		return
+	}
+
+	if h.opts.BackupDir == "" {
+		respondWithError(w, r, http.StatusNotImplemented, "Backups are not configured")
+		return
+	}
+
+	var req RestoreRequest
+	if !h.decodeBody(w, r, &req) {
+		return
+	}
+
+	if req.File == "" || req.File != filepath.Base(req.File) || !filepath.IsLocal(req.File) {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid backup file name")
+		return
+	}
+
+	jokes, err := readBackup(filepath.Join(h.opts.BackupDir, req.File))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			respondWithError(w, r, http.StatusNotFound, "Backup not found")
+			return
+		}
+
+		h.log(r).Error("Failed to read backup", slog.String("file", req.File), slog.String("error", err.Error()))
+		respondWithError(w, r, http.StatusUnprocessableEntity, "Backup file is not a valid joke backup")
+		return
+	}
+
+	if err := h.repo.ReplaceJokes(r.Context(), jokes); err != nil {
+		respondWithWriteError(w, r, h.log(r), err, "Failed to restore backup")
+		return
+	}
+
+	h.log(r).Warn("Restored jokes from backup", slog.String("file", req.File), slog.Int("count", len(jokes)))
+
+	respondWithJSON(w, http.StatusOK, RestoreResponse{
+		File:     req.File,
+		Restored: len(jokes),
+	})
+}
+
+// readBackup reads the jokes of a backup file.
+func readBackup(path string) ([]*model.Joke, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []backupJoke
+	if err := json.NewDecoder(file).Decode(&entries); err != nil {
+		return nil, err
+	}
+
+	jokes := make([]*model.Joke, 0, len(entries))
+	for _, entry := range entries {
+		joke := entry.Joke
+		if joke.Text == "" {
+			return nil, errors.New("joke without text")
+		}
+
+		var publicID string
+		if err := json.Unmarshal(entry.ID, &publicID); err == nil {
+			joke.PublicID = publicID
+		} else if joke.ID, err = strconv.ParseInt(string(entry.ID), 10, 64); err != nil {
+			return nil, errors.New("joke without a valid id")
+		}
+
+		jokes = append(jokes, &joke)
+	}
+
+	return jokes, nil
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"cmp"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type RouteInfo struct {
+	Method  string `json:"method"`
+	Pattern string `json:"pattern"`
+}
+
+type RoutesResponse struct {
+	Routes []RouteInfo `json:"routes"`
+}
+
+// HandleRoutes returns the handler for GET /api/admin/routes, listing every
+// method and pattern registered on routes. The tree is walked per request,
+// so routes should be the top-level router even though this handler is
+// mounted inside it.
+func HandleRoutes(routes chi.Routes, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var list []RouteInfo
+		err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+			list = append(list, RouteInfo{Method: method, Pattern: route})
+			return nil
+		})
+		if err != nil {
+			requestLogger(logger, r).Error("Failed to walk routes", slog.String("error", err.Error()))
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to list routes")
+			return
+		}
+
+		slices.SortFunc(list, func(a, b RouteInfo) int {
+			return cmp.Or(cmp.Compare(a.Pattern, b.Pattern), cmp.Compare(a.Method, b.Method))
+		})
+
+		respondWithJSON(w, http.StatusOK, RoutesResponse{Routes: list})
+	}
+}
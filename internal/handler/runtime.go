@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+	"runtime"
+	"time"
+)
+
+type RuntimeResponse struct {
+	StartedAt     time.Time     `json:"started_at"`
+	UptimeSeconds float64       `json:"uptime_seconds"`
+	GoVersion     string        `json:"go_version"`
+	Goroutines    int           `json:"goroutines"`
+	Memory        RuntimeMemory `json:"memory"`
+}
+
+type RuntimeMemory struct {
+	AllocBytes      uint64 `json:"alloc_bytes"`
+	TotalAllocBytes uint64 `json:"total_alloc_bytes"`
+	SysBytes        uint64 `json:"sys_bytes"`
+	HeapObjects     uint64 `json:"heap_objects"`
+	NumGC           uint32 `json:"num_gc"`
+}
+
+// HandleRuntime returns the handler for GET /api/admin/runtime, reporting
+// uptime relative to startedAt along with basic Go runtime statistics.
+func HandleRuntime(startedAt time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+
+		respondWithJSON(w, http.StatusOK, RuntimeResponse{
+			StartedAt:     startedAt.UTC(),
+			UptimeSeconds: time.Since(startedAt).Seconds(),
+			GoVersion:     runtime.Version(),
+			Goroutines:    runtime.NumGoroutine(),
+			Memory: RuntimeMemory{
+				AllocBytes:      mem.Alloc,
+				TotalAllocBytes: mem.TotalAlloc,
+				SysBytes:        mem.Sys,
+				HeapObjects:     mem.HeapObjects,
+				NumGC:           mem.NumGC,
+			},
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandleRuntime(t *testing.T) {
+	startedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	rec := serveRoute(HandleRuntime(startedAt), http.MethodGet, "/runtime", "/runtime", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &fields); err != nil {
+		t.Fatalf("decoding runtime: %v", err)
+	}
+	var memory map[string]json.RawMessage
+	if err := json.Unmarshal(fields["memory"], &memory); err != nil {
+		t.Fatalf("decoding memory: %v", err)
+	}
+	if got, want := slices.Sorted(maps.Keys(fields)), []string{"go_version", "goroutines", "memory", "started_at", "uptime_seconds"}; !slices.Equal(got, want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+	if got, want := slices.Sorted(maps.Keys(memory)), []string{"alloc_bytes", "heap_objects", "num_gc", "sys_bytes", "total_alloc_bytes"}; !slices.Equal(got, want) {
+		t.Errorf("memory fields = %v, want %v", got, want)
+	}
+
+	var stats RuntimeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decoding runtime: %v", err)
+	}
+	if !stats.StartedAt.Equal(startedAt) {
+		t.Errorf("started_at = %v, want %v", stats.StartedAt, startedAt)
+	}
+	if stats.UptimeSeconds < 60 {
+		t.Errorf("uptime_seconds = %v, want at least 60", stats.UptimeSeconds)
+	}
+	if !strings.HasPrefix(stats.GoVersion, "go") || stats.Goroutines <= 0 || stats.Memory.SysBytes == 0 {
+		t.Errorf("implausible runtime stats: %+v", stats)
+	}
+}
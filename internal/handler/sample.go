@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// maxSampleSize bounds ?n for GET /api/admin/jokes/sample.
+const maxSampleSize = 1000
+
+type JokeSampleResponse struct {
+	Jokes []*model.Joke `json:"jokes"`
+	Count int           `json:"count"`
+	Seed  int64         `json:"seed"`
+}
+
+// GetRandomSample handles GET /api/admin/jokes/sample. It returns ?n jokes
+// picked at random, or all of them when there are fewer. The same ?seed
+// returns the same sample for as long as the set of jokes doesn't change,
+// so experiments can reproduce which jokes they were run on.
+func (h *JokeHandler) GetRandomSample(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r, "n", "seed", "time_format") || !allowTimeFormat(w, r) {
+		return
+	}
+
+	n, err := strconv.Atoi(r.URL.Query().Get("n"))
+	if err != nil || n <= 0 || n > maxSampleSize {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid n parameter, expected 1 to "+strconv.Itoa(maxSampleSize))
+		return
+	}
+
+	seed, err := strconv.ParseInt(r.URL.Query().Get("seed"), 10, 64)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid seed parameter, expected an integer")
+		return
+	}
+
+	jokes, err := h.repo.GetRandomSample(r.Context(), n, seed)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve sample")
+		return
+	}
+
+	applyTimeFormat(r, jokes...)
+	respondWithJSON(w, http.StatusOK, JokeSampleResponse{
+		Jokes: jokes,
+		Count: len(jokes),
+		Seed:  seed,
+	})
+}
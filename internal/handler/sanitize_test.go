@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPrepareTextSanitizeModes(t *testing.T) {
+	const input = `Why did the <b>chicken</b> <script>alert("x")</script>cross the road?`
+
+	tests := []struct {
+		mode    SanitizeMode
+		want    string
+		wantErr error
+	}{
+		{SanitizeOff, input, nil},
+		{SanitizeStrip, "Why did the chicken cross the road?", nil},
+		{SanitizeReject, "", errTextHTML},
+	}
+
+	for _, tt := range tests {
+		h := &JokeHandler{opts: Options{Sanitize: tt.mode}}
+
+		got, err := h.prepareText(input)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("mode %q: err = %v, want %v", tt.mode, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("mode %q: text = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+
+	// Plain text passes every mode, and escaped markup isn't turned back
+	// into tags.
+	for _, mode := range []SanitizeMode{SanitizeStrip, SanitizeReject} {
+		h := &JokeHandler{opts: Options{Sanitize: mode}}
+		if got, err := h.prepareText("1 &lt; 2 &amp;&amp; 3 > 2"); err != nil || got != "1 &lt; 2 &amp;&amp; 3 > 2" {
+			t.Errorf("mode %q: prepareText = %q, %v, want the text unchanged", mode, got, err)
+		}
+	}
+
+	// Stripping a joke down to nothing leaves no text to store.
+	h := &JokeHandler{opts: Options{Sanitize: SanitizeStrip}}
+	if _, err := h.prepareText("<p></p>"); !errors.Is(err, errTextRequired) {
+		t.Errorf("markup only: err = %v, want %v", err, errTextRequired)
+	}
+}
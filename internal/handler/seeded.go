@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"hash/fnv"
+	"net/http"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// getSeededJoke picks the joke at the position the hashed seed maps to
+// among all jokes ordered by ID.
+func (h *JokeHandler) getSeededJoke(w http.ResponseWriter, r *http.Request, seed string) {
+	total, err := h.repo.CountJokes(r.Context())
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to count jokes")
+		return
+	}
+
+	if total == 0 {
+		h.respondNoJokes(w, r)
+		return
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(seed))
+	offset := int(hash.Sum64() % uint64(total))
+
+	jokes, err := h.repo.ListJokes(r.Context(), 1, offset)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve random joke")
+		return
+	}
+
+	// A joke deleted between counting and listing leaves the offset past
+	// the end.
+	if len(jokes) == 0 {
+		respondWithError(w, r, http.StatusNotFound, "No jokes available")
+		return
+	}
+
+	applyTimeFormat(r, jokes[0])
+	respondWithJSON(w, http.StatusOK, jokes[0])
+}
+
+// listShuffledJokes writes a page of the shuffle the seed selects.
+func (h *JokeHandler) listShuffledJokes(w http.ResponseWriter, r *http.Request, seed string, limit, offset int) {
+	h.listAllJokesInOrder(w, r, func(ctx context.Context, limit, offset int) ([]*model.Joke, error) {
+		return h.repo.ListShuffledJokes(ctx, seed, limit, offset)
+	}, limit, offset)
+}
+
+// listAllJokesInOrder writes a page of all jokes, as listed by list in some
+// order other than the default.
+func (h *JokeHandler) listAllJokesInOrder(w http.ResponseWriter, r *http.Request, list func(ctx context.Context, limit, offset int) ([]*model.Joke, error), limit, offset int) {
+	// Fetch one extra row to learn whether there is a next page without
+	// having to count.
+	jokes, err := list(r.Context(), limit+1, offset)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve jokes")
+		return
+	}
+
+	hasMore := len(jokes) > limit
+	if hasMore {
+		jokes = jokes[:limit]
+	}
+
+	response := JokeListResponse{
+		Jokes:   jokes,
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: hasMore,
+	}
+
+	total := -1
+	if r.URL.Query().Get("include_total") != "false" {
+		count, err := h.repo.CountJokes(r.Context())
+		if err != nil {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to count jokes")
+			return
+		}
+
+		total = count
+		response.Total = &total
+		response.TotalPages = totalPages(total, limit)
+		response.OutOfRange = outOfRange(total, offset)
+	}
+
+	if err := markEmptyCollection(r.Context(), h.repo, &response, total); err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to count jokes")
+		return
+	}
+
+	setPaginationLinks(w, r, limit, offset, response.Total, hasMore)
+	applyTimeFormat(r, response.Jokes...)
+	respondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,11 @@
+package handler
+
+import "net/http"
+
+// listStableJokes writes a page of all jokes in creation order, with ties
+// broken by ID. Unlike the ID order, it follows when jokes were created,
+// including imported ones, and it is still fully deterministic, which
+// snapshot tests of clients rely on.
+func (h *JokeHandler) listStableJokes(w http.ResponseWriter, r *http.Request, limit, offset int) {
+	h.listAllJokesInOrder(w, r, h.repo.ListJokesByCreation, limit, offset)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// defaultStaleDays is the staleness window used when ?days is omitted.
+const defaultStaleDays = 30
+
+// ListStaleJokes handles GET /api/admin/jokes/stale
+func (h *JokeHandler) ListStaleJokes(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r, "days", "limit", "offset", "time_format") || !allowTimeFormat(w, r) {
+		return
+	}
+
+	days := defaultStaleDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid days parameter")
+			return
+		}
+		days = parsed
+	}
+
+	limit, offset := parsePagination(w, r)
+	if !allowOffset(w, r, offset, h.opts.MaxOffset) {
+		return
+	}
+	before := h.opts.Now().AddDate(0, 0, -days)
+
+	jokes, err := h.repo.ListStaleJokes(r.Context(), before, limit, offset)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve stale jokes")
+		return
+	}
+
+	total, err := h.repo.CountStaleJokes(r.Context(), before)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to count stale jokes")
+		return
+	}
+
+	response := JokeListResponse{
+		Jokes:      jokes,
+		Total:      &total,
+		TotalPages: totalPages(total, limit),
+		Limit:      limit,
+		Offset:     offset,
+		HasMore:    offset+len(jokes) < total,
+		OutOfRange: outOfRange(total, offset),
+	}
+	if err := markEmptyCollection(r.Context(), h.repo, &response, -1); err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to count jokes")
+		return
+	}
+
+	applyTimeFormat(r, jokes...)
+	respondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+const (
+	// defaultStatsDays is the window used when ?days is omitted.
+	defaultStatsDays = 30
+
+	// maxStatsDays bounds the window, and with it the response size.
+	maxStatsDays = 366
+)
+
+// GetDailyCounts handles GET /api/admin/stats/daily-counts. It returns one
+// entry per UTC day of the window, ending today, including days on which
+// no jokes were created.
+func (h *JokeHandler) GetDailyCounts(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r, "days") {
+		return
+	}
+
+	days := defaultStatsDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 || parsed > maxStatsDays {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid days parameter")
+			return
+		}
+		days = parsed
+	}
+
+	today := h.opts.Now().UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, -(days - 1))
+
+	counts, err := h.repo.CountJokesPerDay(r.Context(), since)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to count jokes per day")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, fillDailyCounts(counts, since, days))
+}
+
+// fillDailyCounts returns an entry for each of the days starting at since,
+// taking the counts from the sparse counts list.
+func fillDailyCounts(counts []model.DailyCount, since time.Time, days int) []model.DailyCount {
+	byDate := make(map[string]int, len(counts))
+	for _, count := range counts {
+		byDate[count.Date] = count.Count
+	}
+
+	filled := make([]model.DailyCount, days)
+	for i := range filled {
+		date := since.AddDate(0, 0, i).Format(time.DateOnly)
+		filled[i] = model.DailyCount{Date: date, Count: byDate[date]}
+	}
+
+	return filled
+}
+
+// GetLengthPercentiles handles GET /api/admin/stats/length-percentiles.
+func (h *JokeHandler) GetLengthPercentiles(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r) {
+		return
+	}
+
+	stats, err := h.repo.JokeLengthPercentiles(r.Context())
+	if err != nil {
+		h.log(r).Error("Failed to compute joke length percentiles", slog.String("error", err.Error()))
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to compute joke length percentiles")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, stats)
+}
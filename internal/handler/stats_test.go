@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func TestFillDailyCounts(t *testing.T) {
+	since := time.Date(2024, 2, 27, 0, 0, 0, 0, time.UTC)
+	counts := []model.DailyCount{
+		{Date: "2024-02-28", Count: 3},
+		{Date: "2024-03-01", Count: 1},
+	}
+
+	got := fillDailyCounts(counts, since, 4)
+	want := []model.DailyCount{
+		{Date: "2024-02-27", Count: 0},
+		{Date: "2024-02-28", Count: 3},
+		{Date: "2024-02-29", Count: 0},
+		{Date: "2024-03-01", Count: 1},
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("fillDailyCounts = %v, want %v", got, want)
+	}
+}
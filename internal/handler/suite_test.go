@@ -0,0 +1,638 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/treboc/huhu-api/internal/middleware"
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/profanity"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+const suiteAdminKey = "suite-admin-key-0123"
+
+// errSpy is what spyRepository returns for calls it was told to fail.
+var errSpy = errors.New("spy: forced failure")
+
+// spyRepository wraps a real repository, records the calls made to it and
+// fails the methods named in fail.
+type spyRepository struct {
+	repository.JokeRepository
+	fail  map[string]bool
+	calls []string
+}
+
+func (s *spyRepository) call(method string) error {
+	s.calls = append(s.calls, method)
+	if s.fail[method] {
+		return errSpy
+	}
+	return nil
+}
+
+func (s *spyRepository) GetJoke(ctx context.Context, id int64) (*model.Joke, error) {
+	if err := s.call("GetJoke"); err != nil {
+		return nil, err
+	}
+	return s.JokeRepository.GetJoke(ctx, id)
+}
+
+func (s *spyRepository) GetRandomJoke(ctx context.Context) (*model.Joke, error) {
+	if err := s.call("GetRandomJoke"); err != nil {
+		return nil, err
+	}
+	return s.JokeRepository.GetRandomJoke(ctx)
+}
+
+func (s *spyRepository) ListJokes(ctx context.Context, limit, offset int) ([]*model.Joke, error) {
+	if err := s.call("ListJokes"); err != nil {
+		return nil, err
+	}
+	return s.JokeRepository.ListJokes(ctx, limit, offset)
+}
+
+func (s *spyRepository) GetOrCreateJokes(ctx context.Context, jokes []*model.Joke) ([]repository.UpsertResult, error) {
+	if err := s.call("GetOrCreateJokes"); err != nil {
+		return nil, err
+	}
+	return s.JokeRepository.GetOrCreateJokes(ctx, jokes)
+}
+
+func (s *spyRepository) CreateJokeWithID(ctx context.Context, id int64, joke *model.Joke) error {
+	if err := s.call("CreateJokeWithID"); err != nil {
+		return err
+	}
+	return s.JokeRepository.CreateJokeWithID(ctx, id, joke)
+}
+
+func (s *spyRepository) UpdateJoke(ctx context.Context, joke *model.Joke) error {
+	if err := s.call("UpdateJoke"); err != nil {
+		return err
+	}
+	return s.JokeRepository.UpdateJoke(ctx, joke)
+}
+
+func (s *spyRepository) DeleteJoke(ctx context.Context, id int64) error {
+	if err := s.call("DeleteJoke"); err != nil {
+		return err
+	}
+	return s.JokeRepository.DeleteJoke(ctx, id)
+}
+
+// newSuiteRouter serves the joke routes the way the API does, with the
+// admin routes behind the admin key, over a repository holding one joke
+// with ID 1.
+func newSuiteRouter(t *testing.T) (http.Handler, *spyRepository) {
+	t.Helper()
+
+	repo, err := repository.NewSQLiteJokeRepository(filepath.Join(t.TempDir(), "jokes.db"), repository.Options{})
+	if err != nil {
+		t.Fatalf("creating repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "Why did the chicken cross the road?"}); err != nil {
+		t.Fatalf("seeding joke: %v", err)
+	}
+
+	spy := &spyRepository{JokeRepository: repo, fail: make(map[string]bool)}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := NewJokeHandler(spy, logger, Options{
+		Profanity:     profanity.New([]string{"darn"}),
+		ProfanityMode: ProfanityReject,
+	})
+
+	r := chi.NewRouter()
+	r.Get("/api/joke/", h.ListJokes)
+	r.Get("/api/joke/random", h.GetRandomJoke)
+	r.Get("/api/joke/{id}", h.GetJoke)
+	r.Route("/api/admin", func(r chi.Router) {
+		r.Use(middleware.AdminAuth(suiteAdminKey, middleware.AdminAuthOptions{Logger: logger}))
+		r.Post("/joke", h.CreateJoke)
+		r.Put("/joke/{id}", h.UpdateJoke)
+		r.Delete("/joke/{id}", h.DeleteJoke)
+	})
+
+	return r, spy
+}
+
+func TestJokeHandlerStatusCodes(t *testing.T) {
+	// anyValue in wantHeaders only asks for the header to be set.
+	const anyValue = "*"
+
+	tests := []struct {
+		name        string
+		setup       func(*testing.T, *spyRepository)
+		method      string
+		path        string
+		body        string
+		headers     map[string]string
+		admin       bool
+		wantStatus  int
+		wantBody    string
+		wantHeaders map[string]string
+	}{
+		// List
+		{
+			name:        "list",
+			method:      http.MethodGet,
+			path:        "/api/joke/",
+			wantStatus:  http.StatusOK,
+			wantBody:    `"total":1`,
+			wantHeaders: map[string]string{"Content-Type": "application/json"},
+		},
+		{
+			name:       "list with invalid sort",
+			method:     http.MethodGet,
+			path:       "/api/joke/?sort=newest",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "Invalid sort parameter",
+		},
+		{
+			name:       "list failure",
+			setup:      func(t *testing.T, s *spyRepository) { s.fail["ListJokes"] = true },
+			method:     http.MethodGet,
+			path:       "/api/joke/",
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "Failed to retrieve jokes",
+		},
+
+		// Get
+		{
+			name:        "get",
+			method:      http.MethodGet,
+			path:        "/api/joke/1",
+			wantStatus:  http.StatusOK,
+			wantBody:    "chicken",
+			wantHeaders: map[string]string{"Content-Type": "application/json", "ETag": anyValue, "Last-Modified": anyValue},
+		},
+		{
+			name:       "get with invalid ID",
+			method:     http.MethodGet,
+			path:       "/api/joke/abc",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "Invalid joke ID",
+		},
+		{
+			name:       "get missing",
+			method:     http.MethodGet,
+			path:       "/api/joke/99",
+			wantStatus: http.StatusNotFound,
+			wantBody:   "Joke not found",
+		},
+		{
+			name:       "get failure",
+			setup:      func(t *testing.T, s *spyRepository) { s.fail["GetJoke"] = true },
+			method:     http.MethodGet,
+			path:       "/api/joke/1",
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "Failed to retrieve joke",
+		},
+
+		// Random
+		{
+			name:        "random",
+			method:      http.MethodGet,
+			path:        "/api/joke/random",
+			wantStatus:  http.StatusOK,
+			wantBody:    "chicken",
+			wantHeaders: map[string]string{"Content-Type": "application/json"},
+		},
+		{
+			name:       "random with invalid max_length",
+			method:     http.MethodGet,
+			path:       "/api/joke/random?max_length=-1",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "Invalid max_length parameter",
+		},
+		{
+			name: "random from an empty table",
+			setup: func(t *testing.T, s *spyRepository) {
+				if err := s.JokeRepository.DeleteJoke(context.Background(), 1); err != nil {
+					t.Fatalf("emptying table: %v", err)
+				}
+			},
+			method:     http.MethodGet,
+			path:       "/api/joke/random",
+			wantStatus: http.StatusNotFound,
+			wantBody:   "No jokes available",
+		},
+		{
+			name:       "random failure",
+			setup:      func(t *testing.T, s *spyRepository) { s.fail["GetRandomJoke"] = true },
+			method:     http.MethodGet,
+			path:       "/api/joke/random",
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "Failed to retrieve random joke",
+		},
+
+		// Create
+		{
+			name:        "create",
+			method:      http.MethodPost,
+			path:        "/api/admin/joke",
+			body:        `{"text":"A new joke"}`,
+			admin:       true,
+			wantStatus:  http.StatusCreated,
+			wantBody:    "A new joke",
+			wantHeaders: map[string]string{"Content-Type": "application/json", "Location": "/api/joke/2", "ETag": anyValue},
+		},
+		{
+			name:        "create existing text",
+			method:      http.MethodPost,
+			path:        "/api/admin/joke",
+			body:        `{"text":"why did the CHICKEN cross the road?"}`,
+			admin:       true,
+			wantStatus:  http.StatusOK,
+			wantBody:    "Why did the chicken cross the road?",
+			wantHeaders: map[string]string{"Location": "/api/joke/1"},
+		},
+		{
+			name:       "create without admin key",
+			method:     http.MethodPost,
+			path:       "/api/admin/joke",
+			body:       `{"text":"A new joke"}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "create with malformed JSON",
+			method:     http.MethodPost,
+			path:       "/api/admin/joke",
+			body:       `{"text":`,
+			admin:      true,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "create without text",
+			method:     http.MethodPost,
+			path:       "/api/admin/joke",
+			body:       `{"text":"  "}`,
+			admin:      true,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "Joke text is required",
+		},
+		{
+			name:       "create with disallowed words",
+			method:     http.MethodPost,
+			path:       "/api/admin/joke",
+			body:       `{"text":"Well darn"}`,
+			admin:      true,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   "not allowed",
+		},
+		{
+			name:       "create failure",
+			setup:      func(t *testing.T, s *spyRepository) { s.fail["GetOrCreateJokes"] = true },
+			method:     http.MethodPost,
+			path:       "/api/admin/joke",
+			body:       `{"text":"A new joke"}`,
+			admin:      true,
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "Failed to create joke",
+		},
+
+		// Update
+		{
+			name:        "update",
+			method:      http.MethodPut,
+			path:        "/api/admin/joke/1",
+			body:        `{"text":"An updated joke"}`,
+			admin:       true,
+			wantStatus:  http.StatusOK,
+			wantBody:    "An updated joke",
+			wantHeaders: map[string]string{"Content-Type": "application/json", "ETag": anyValue},
+		},
+		{
+			name:       "update without admin key",
+			method:     http.MethodPut,
+			path:       "/api/admin/joke/1",
+			body:       `{"text":"An updated joke"}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "update with invalid ID",
+			method:     http.MethodPut,
+			path:       "/api/admin/joke/abc",
+			body:       `{"text":"An updated joke"}`,
+			admin:      true,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "Invalid joke ID",
+		},
+		{
+			name:       "update missing",
+			method:     http.MethodPut,
+			path:       "/api/admin/joke/99",
+			body:       `{"text":"An updated joke"}`,
+			admin:      true,
+			wantStatus: http.StatusNotFound,
+			wantBody:   "Joke not found",
+		},
+		{
+			name:        "update missing with create-if-missing",
+			method:      http.MethodPut,
+			path:        "/api/admin/joke/99",
+			body:        `{"text":"A joke at a chosen ID"}`,
+			headers:     map[string]string{"Prefer": "create-if-missing"},
+			admin:       true,
+			wantStatus:  http.StatusCreated,
+			wantBody:    `"id":99`,
+			wantHeaders: map[string]string{"Location": "/api/joke/99", "Preference-Applied": "create-if-missing", "ETag": anyValue},
+		},
+		{
+			name:       "update existing with create-if-missing",
+			method:     http.MethodPut,
+			path:       "/api/admin/joke/1",
+			body:       `{"text":"An updated joke"}`,
+			headers:    map[string]string{"Prefer": "create-if-missing"},
+			admin:      true,
+			wantStatus: http.StatusOK,
+			wantBody:   "An updated joke",
+		},
+		{
+			name:       "create-if-missing failure",
+			setup:      func(t *testing.T, s *spyRepository) { s.fail["CreateJokeWithID"] = true },
+			method:     http.MethodPut,
+			path:       "/api/admin/joke/99",
+			body:       `{"text":"A joke at a chosen ID"}`,
+			headers:    map[string]string{"Prefer": "create-if-missing"},
+			admin:      true,
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "Failed to create joke",
+		},
+		{
+			name:       "update with disallowed words",
+			method:     http.MethodPut,
+			path:       "/api/admin/joke/1",
+			body:       `{"text":"Well darn"}`,
+			admin:      true,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "update failure",
+			setup:      func(t *testing.T, s *spyRepository) { s.fail["UpdateJoke"] = true },
+			method:     http.MethodPut,
+			path:       "/api/admin/joke/1",
+			body:       `{"text":"An updated joke"}`,
+			admin:      true,
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "Failed to update joke",
+		},
+
+		// Delete
+		{
+			name:       "delete",
+			method:     http.MethodDelete,
+			path:       "/api/admin/joke/1",
+			admin:      true,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "delete without admin key",
+			method:     http.MethodDelete,
+			path:       "/api/admin/joke/1",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "delete with invalid ID",
+			method:     http.MethodDelete,
+			path:       "/api/admin/joke/abc",
+			admin:      true,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "Invalid joke ID",
+		},
+		{
+			name:       "delete missing",
+			method:     http.MethodDelete,
+			path:       "/api/admin/joke/99",
+			admin:      true,
+			wantStatus: http.StatusNotFound,
+			wantBody:   "Joke not found",
+		},
+		{
+			name:       "delete missing idempotently",
+			method:     http.MethodDelete,
+			path:       "/api/admin/joke/99?idempotent=true",
+			admin:      true,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "delete failure",
+			setup:      func(t *testing.T, s *spyRepository) { s.fail["DeleteJoke"] = true },
+			method:     http.MethodDelete,
+			path:       "/api/admin/joke/1",
+			admin:      true,
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "Failed to delete joke",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, spy := newSuiteRouter(t)
+			if tt.setup != nil {
+				tt.setup(t, spy)
+			}
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			if tt.admin {
+				req.Header.Set("Admin-API-Key", suiteAdminKey)
+			}
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusNoContent && rec.Body.Len() != 0 {
+				t.Errorf("body = %q, want none", rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body.String(), tt.wantBody)
+			}
+			for name, want := range tt.wantHeaders {
+				got := rec.Header().Get(name)
+				if want == anyValue && got == "" || want != anyValue && got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestJokeHandlerSkipsRepositoryOnBadInput(t *testing.T) {
+	router, spy := newSuiteRouter(t)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/joke/?sort=newest", nil),
+		httptest.NewRequest(http.MethodGet, "/api/joke/random?max_length=0", nil),
+		httptest.NewRequest(http.MethodDelete, "/api/admin/joke/1", nil),
+	} {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if len(spy.calls) != 0 {
+		t.Errorf("repository calls = %v, want none", spy.calls)
+	}
+}
+
+func TestGetRandomJokeCount(t *testing.T) {
+	router, spy := newSuiteRouter(t)
+	for i := 0; i < maxRandomCount+5; i++ {
+		if _, err := spy.JokeRepository.CreateJoke(context.Background(), &model.Joke{Text: fmt.Sprintf("joke %d", i)}); err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantCount  int
+	}{
+		{"?count=3", http.StatusOK, 3},
+		{"?count=1", http.StatusOK, 1},
+		{"?count=500", http.StatusOK, maxRandomCount},
+		{"?count=0", http.StatusBadRequest, 0},
+		{"?count=-1", http.StatusBadRequest, 0},
+		{"?count=", http.StatusBadRequest, 0},
+		{"?count=two", http.StatusBadRequest, 0},
+		{"?count=2&max_length=10", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/joke/random"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp RandomJokesResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if len(resp.Jokes) != tt.wantCount || resp.Count != tt.wantCount {
+				t.Errorf("got %d jokes, count %d, want %d", len(resp.Jokes), resp.Count, tt.wantCount)
+			}
+
+			seen := make(map[int64]bool)
+			for _, joke := range resp.Jokes {
+				if seen[joke.ID] {
+					t.Errorf("joke %d returned twice", joke.ID)
+				}
+				seen[joke.ID] = true
+			}
+		})
+	}
+}
+
+func TestGetRandomJokeEmptyFallback(t *testing.T) {
+	repo, err := repository.NewSQLiteJokeRepository(filepath.Join(t.TempDir(), "jokes.db"), repository.Options{})
+	if err != nil {
+		t.Fatalf("creating repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("default", func(t *testing.T) {
+		h := NewJokeHandler(repo, logger, Options{})
+
+		rec := httptest.NewRecorder()
+		h.GetRandomJoke(rec, httptest.NewRequest(http.MethodGet, "/api/joke/random", nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+		}
+		if got := rec.Header().Get("X-Fallback-Joke"); got != "" {
+			t.Errorf("X-Fallback-Joke = %q, want none", got)
+		}
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		const fallback = "The joke database walks into a bar. It's empty."
+		h := NewJokeHandler(repo, logger, Options{EmptyRandomFallback: fallback})
+
+		for _, path := range []string{"/api/joke/random", "/api/joke/random?seed=abc"} {
+			rec := httptest.NewRecorder()
+			h.GetRandomJoke(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("%s: status = %d, want %d", path, rec.Code, http.StatusOK)
+			}
+			if got := rec.Header().Get("X-Fallback-Joke"); got != "true" {
+				t.Errorf("%s: X-Fallback-Joke = %q, want true", path, got)
+			}
+
+			var joke model.Joke
+			if err := json.Unmarshal(rec.Body.Bytes(), &joke); err != nil {
+				t.Fatalf("%s: decoding joke: %v", path, err)
+			}
+			if joke.Text != fallback || joke.ID != 0 {
+				t.Errorf("%s: got joke %d %q, want the fallback without an ID", path, joke.ID, joke.Text)
+			}
+		}
+	})
+}
+
+func TestUpdateJokePutCreatesMissing(t *testing.T) {
+	repo, err := repository.NewSQLiteJokeRepository(filepath.Join(t.TempDir(), "jokes.db"), repository.Options{})
+	if err != nil {
+		t.Fatalf("creating repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	h := NewJokeHandler(repo, slog.New(slog.NewTextHandler(io.Discard, nil)), Options{PutCreatesMissing: true})
+	r := chi.NewRouter()
+	r.Put("/joke/{id}", h.UpdateJoke)
+
+	put := func(id, text string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/joke/"+id, strings.NewReader(`{"text":"`+text+`"}`)))
+		return rec
+	}
+
+	if rec := put("7", "first"); rec.Code != http.StatusCreated {
+		t.Fatalf("PUT missing: status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if rec := put("7", "second"); rec.Code != http.StatusOK {
+		t.Fatalf("PUT existing: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	joke, err := repo.GetJoke(context.Background(), 7)
+	if err != nil || joke.Text != "second" {
+		t.Fatalf("joke 7 = %v, %v, want the updated text", joke, err)
+	}
+
+	// The sequence moves past IDs chosen by clients.
+	id, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "next"})
+	if err != nil {
+		t.Fatalf("creating joke: %v", err)
+	}
+	if id != 8 {
+		t.Errorf("next ID = %d, want 8", id)
+	}
+}
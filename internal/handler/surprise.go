@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// Surprise handles GET /api/surprise. It is the simplest way to get a joke:
+// a random one as plain text, with its ID in the X-Joke-Id header so the
+// client can look it up later.
+func (h *JokeHandler) Surprise(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	joke, err := h.repo.GetRandomJoke(r.Context())
+	if err != nil {
+		if errors.Is(err, repository.ErrNoJokes) {
+			if h.opts.EmptyRandomFallback != "" {
+				w.Header().Set("X-Fallback-Joke", "true")
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(h.opts.EmptyRandomFallback + "\n"))
+				return
+			}
+
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("No jokes here yet. Tough crowd! Come back once someone has added a few.\n"))
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Something went wrong while looking for a joke. That's not funny.\n"))
+		return
+	}
+
+	w.Header().Set("X-Joke-Id", jokeRef(joke))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(joke.Text + "\n"))
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func serveSurprise(h *JokeHandler) (int, http.Header, string) {
+	rec := serveRoute(h.Surprise, http.MethodGet, "/surprise", "/surprise", "")
+	return rec.Code, rec.Header(), rec.Body.String()
+}
+
+func TestSurprise(t *testing.T) {
+	h, repo := newTestJokeHandler(t, Options{})
+	surprise := func() (int, http.Header, string) { return serveSurprise(h) }
+
+	status, header, body := surprise()
+	if status != http.StatusNotFound {
+		t.Errorf("empty: status = %d, want %d", status, http.StatusNotFound)
+	}
+	if ct := header.Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("empty: Content-Type = %q, want text/plain", ct)
+	}
+	if body == "" || header.Get("X-Joke-Id") != "" {
+		t.Errorf("empty: body %q, X-Joke-Id %q", body, header.Get("X-Joke-Id"))
+	}
+
+	id, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "A surprising joke"})
+	if err != nil {
+		t.Fatalf("seeding joke: %v", err)
+	}
+
+	status, header, body = surprise()
+	if status != http.StatusOK {
+		t.Fatalf("populated: status = %d, want %d", status, http.StatusOK)
+	}
+	if body != "A surprising joke\n" {
+		t.Errorf("populated: body = %q, want the joke text", body)
+	}
+	if got := header.Get("X-Joke-Id"); got != strconv.FormatInt(id, 10) {
+		t.Errorf("populated: X-Joke-Id = %q, want %d", got, id)
+	}
+}
+
+func TestSurpriseEmptyFallback(t *testing.T) {
+	h, _ := newTestJokeHandler(t, Options{EmptyRandomFallback: "Nothing to see here."})
+
+	status, header, body := serveSurprise(h)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want %d", status, http.StatusOK)
+	}
+	if body != "Nothing to see here.\n" {
+		t.Errorf("body = %q, want the fallback text", body)
+	}
+	if header.Get("X-Fallback-Joke") != "true" || header.Get("X-Joke-Id") != "" {
+		t.Errorf("X-Fallback-Joke = %q, X-Joke-Id = %q", header.Get("X-Fallback-Joke"), header.Get("X-Joke-Id"))
+	}
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// TellJoke handles GET /api/joke/{id}/tell. It streams the joke as
+// server-sent events: a "setup" event, then after Options.TellPause a
+// "punchline" event for two-part jokes, and finally a "done" event.
+func (h *JokeHandler) TellJoke(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r) {
+		return
+	}
+
+	id, ok := h.jokeID(w, r)
+	if !ok {
+		return
+	}
+
+	joke, err := h.repo.GetJoke(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrJokeNotFound) {
+			respondWithError(w, r, http.StatusNotFound, "Joke not found")
+			return
+		}
+
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to retrieve joke")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeEvent(w, rc, "setup", joke.Setup); err != nil {
+		return
+	}
+
+	if joke.Punchline != "" {
+		timer := time.NewTimer(h.opts.TellPause)
+		defer timer.Stop()
+
+		// Stop waiting as soon as the client goes away.
+		select {
+		case <-r.Context().Done():
+			return
+		case <-timer.C:
+		}
+
+		if err := writeEvent(w, rc, "punchline", joke.Punchline); err != nil {
+			return
+		}
+	}
+
+	writeEvent(w, rc, "done", "")
+}
+
+// writeEvent writes a single server-sent event and flushes it to the client.
+func writeEvent(w http.ResponseWriter, rc *http.ResponseController, event, data string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "event: %s\n", event)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+
+	if _, err := w.Write([]byte(b.String())); err != nil {
+		return err
+	}
+
+	return rc.Flush()
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestTellJokeStreamsSetupThenPunchline(t *testing.T) {
+	repo, err := repository.NewSQLiteJokeRepository(filepath.Join(t.TempDir(), "jokes.db"), repository.Options{})
+	if err != nil {
+		t.Fatalf("creating repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	_, err = repo.CreateJoke(context.Background(), &model.Joke{
+		Text:      "Knock knock. Who's there?",
+		Setup:     "Knock knock.",
+		Punchline: "Who's there?",
+	})
+	if err != nil {
+		t.Fatalf("seeding joke: %v", err)
+	}
+
+	const pause = 100 * time.Millisecond
+
+	h := NewJokeHandler(repo, slog.New(slog.NewTextHandler(io.Discard, nil)), Options{TellPause: pause})
+	r := chi.NewRouter()
+	r.Get("/joke/{id}/tell", h.TellJoke)
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/joke/1/tell")
+	if err != nil {
+		t.Fatalf("GET tell: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	type event struct {
+		name, data string
+		at         time.Time
+	}
+
+	var events []event
+	var current event
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			current.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		case line == "":
+			current.at = time.Now()
+			events = append(events, current)
+			current = event{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("reading stream: %v", err)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3: %v", len(events), events)
+	}
+
+	want := []event{{name: "setup", data: "Knock knock."}, {name: "punchline", data: "Who's there?"}, {name: "done"}}
+	for i, e := range events {
+		if e.name != want[i].name || e.data != want[i].data {
+			t.Errorf("event %d = %s %q, want %s %q", i, e.name, e.data, want[i].name, want[i].data)
+		}
+	}
+
+	if delay := events[1].at.Sub(events[0].at); delay < pause {
+		t.Errorf("punchline arrived %v after setup, want at least %v", delay, pause)
+	}
+}
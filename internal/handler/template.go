@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// templatePlaceholder matches a placeholder in joke text: {name}, or
+// {name=default} with a value used when the request doesn't set one.
+var templatePlaceholder = regexp.MustCompile(`\{([a-z][a-z0-9_]*)(?:=([^{}]*))?\}`)
+
+// parseTemplateVars parses ?vars values of the form name:value, with pairs
+// separated by commas, as in ?vars=animal:chicken,place:road.
+func parseTemplateVars(values []string) (map[string]string, error) {
+	vars := make(map[string]string)
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		for _, pair := range strings.Split(value, ",") {
+			name, val, ok := strings.Cut(pair, ":")
+			name = strings.TrimSpace(name)
+			if !ok || name == "" {
+				return nil, fmt.Errorf("invalid variable %q, expected name:value", pair)
+			}
+			if _, dup := vars[name]; dup {
+				return nil, fmt.Errorf("variable %q is set more than once", name)
+			}
+			vars[name] = val
+		}
+	}
+	return vars, nil
+}
+
+// renderJokeTemplate substitutes vars into the placeholders of the joke's
+// text, setup and punchline. Placeholders not in vars take their default.
+// It fails for a placeholder with neither a value nor a default, and for
+// variables that don't appear in the joke at all.
+func renderJokeTemplate(joke *model.Joke, vars map[string]string) error {
+	used := make(map[string]bool, len(vars))
+	var missing []string
+
+	render := func(text string) string {
+		return templatePlaceholder.ReplaceAllStringFunc(text, func(placeholder string) string {
+			match := templatePlaceholder.FindStringSubmatch(placeholder)
+			name, hasDefault := match[1], strings.Contains(placeholder, "=")
+
+			if value, ok := vars[name]; ok {
+				used[name] = true
+				return value
+			}
+			if hasDefault {
+				return match[2]
+			}
+			if !slices.Contains(missing, name) {
+				missing = append(missing, name)
+			}
+			return placeholder
+		})
+	}
+
+	text, setup, punchline := render(joke.Text), render(joke.Setup), render(joke.Punchline)
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing value for variable %s", strings.Join(missing, ", "))
+	}
+
+	var unknown []string
+	for name := range vars {
+		if !used[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return fmt.Errorf("unknown variable %s", strings.Join(unknown, ", "))
+	}
+
+	joke.Text, joke.Setup, joke.Punchline = text, setup, punchline
+	return nil
+}
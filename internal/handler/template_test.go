@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func TestRenderJokeTemplate(t *testing.T) {
+	const template = "Why did the {animal} cross the {place=road}?"
+
+	tests := []struct {
+		name    string
+		vars    string
+		want    string
+		wantErr string
+	}{
+		{"substitution", "animal:chicken,place:street", "Why did the chicken cross the street?", ""},
+		{"default", "animal:duck", "Why did the duck cross the road?", ""},
+		{"empty value", "animal:,place:road", "Why did the  cross the road?", ""},
+		{"missing variable", "place:road", "", "missing value for variable animal"},
+		{"unknown variable", "animal:cow,color:red", "", "unknown variable color"},
+		{"malformed pair", "animal", "", "expected name:value"},
+		{"repeated variable", "animal:cow,animal:pig", "", "more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			joke := &model.Joke{Text: template, Setup: template}
+
+			vars, err := parseTemplateVars([]string{tt.vars})
+			if err == nil {
+				err = renderJokeTemplate(joke, vars)
+			}
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want one containing %q", err, tt.wantErr)
+				}
+				if joke.Text != template {
+					t.Errorf("text = %q after a failed render, want it unchanged", joke.Text)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			if joke.Text != tt.want || joke.Setup != tt.want {
+				t.Errorf("text, setup = %q, %q, want %q", joke.Text, joke.Setup, tt.want)
+			}
+		})
+	}
+}
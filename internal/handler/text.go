@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/sanitize"
+)
+
+// SanitizeMode controls how HTML in submitted joke text is handled.
+type SanitizeMode string
+
+const (
+	SanitizeOff    SanitizeMode = ""
+	SanitizeStrip  SanitizeMode = "strip"
+	SanitizeReject SanitizeMode = "reject"
+)
+
+// ProfanityMode controls what happens to joke text containing words from
+// the profanity list.
+type ProfanityMode string
+
+const (
+	ProfanityOff    ProfanityMode = ""
+	ProfanityMask   ProfanityMode = "mask"
+	ProfanityReject ProfanityMode = "reject"
+)
+
+var (
+	errTextRequired  = errors.New("joke text is required")
+	errTextHTML      = errors.New("joke text must not contain HTML")
+	errTextProfane   = errors.New("joke text contains flagged words")
+	errPartsRequired = errors.New("setup and punchline are both required")
+	errTextAndParts  = errors.New("joke text cannot be combined with setup and punchline")
+)
+
+// prepareJoke builds the joke to store from a create or update request. A
+// request carries either text or a setup and punchline; for two-part jokes
+// the text is the setup followed by the punchline.
+func (h *JokeHandler) prepareJoke(req CreateJokeRequest) (*model.Joke, error) {
+	if req.Setup == "" && req.Punchline == "" {
+		text, err := h.prepareText(req.Text)
+		if err != nil {
+			return nil, err
+		}
+
+		return &model.Joke{Text: text}, nil
+	}
+
+	if strings.TrimSpace(req.Text) != "" {
+		return nil, errTextAndParts
+	}
+
+	setup, err := h.prepareText(req.Setup)
+	if err != nil {
+		return nil, partError(err)
+	}
+
+	punchline, err := h.prepareText(req.Punchline)
+	if err != nil {
+		return nil, partError(err)
+	}
+
+	return &model.Joke{
+		Text:      setup + " " + punchline,
+		Setup:     setup,
+		Punchline: punchline,
+	}, nil
+}
+
+func partError(err error) error {
+	if errors.Is(err, errTextRequired) {
+		return errPartsRequired
+	}
+	return err
+}
+
+// prepareText normalizes and validates joke text before it is stored. Use
+// textErrorMessage to report its errors to clients.
+func (h *JokeHandler) prepareText(text string) (string, error) {
+	text = strings.TrimSpace(h.opts.UnicodeForm.String(text))
+
+	switch h.opts.Sanitize {
+	case SanitizeStrip:
+		text = sanitize.StripHTML(text)
+	case SanitizeReject:
+		if sanitize.ContainsHTML(text) {
+			return "", errTextHTML
+		}
+	}
+
+	switch h.opts.ProfanityMode {
+	case ProfanityMask:
+		text = h.opts.Profanity.Mask(text)
+	case ProfanityReject:
+		if h.opts.Profanity.Contains(text) {
+			return "", errTextProfane
+		}
+	}
+
+	if text == "" {
+		return "", errTextRequired
+	}
+
+	return text, nil
+}
+
+// textErrorMessage turns a prepareText or prepareJoke error into a client facing message.
+func textErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, errTextRequired):
+		return "Joke text is required"
+	case errors.Is(err, errTextHTML):
+		return "Joke text must not contain HTML"
+	case errors.Is(err, errTextProfane):
+		return "Joke text contains words that are not allowed"
+	case errors.Is(err, errPartsRequired):
+		return "Setup and punchline are both required"
+	case errors.Is(err, errTextAndParts):
+		return "Provide either text or setup and punchline, not both"
+	default:
+		return "Invalid joke text"
+	}
+}
+
+// Error codes of prepareText and prepareJoke errors in batch results.
+const (
+	errCodeInvalidJoke  = "invalid_joke"
+	errCodeRejectedText = "rejected_text"
+)
+
+// textErrorCode is the batch result code of a prepareText or prepareJoke
+// error, matching textErrorStatus.
+func textErrorCode(err error) string {
+	if errors.Is(err, errTextProfane) {
+		return errCodeRejectedText
+	}
+	return errCodeInvalidJoke
+}
+
+// textErrorStatus is the status code to report a prepareText or prepareJoke
+// error with. Malformed requests are 400s, while well-formed text the
+// content policy refuses is a 422.
+func textErrorStatus(err error) int {
+	if errors.Is(err, errTextProfane) {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadRequest
+}
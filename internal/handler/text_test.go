@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"testing"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestPrepareTextNormalizesUnicode(t *testing.T) {
+	// The same text, precomposed and with combining marks.
+	const (
+		composed   = "Café naïve résumé"
+		decomposed = "Café naïve résumé"
+	)
+
+	tests := []struct {
+		name string
+		form norm.Form
+		want string
+	}{
+		{"default is NFC", 0, composed},
+		{"NFC", norm.NFC, composed},
+		{"NFD", norm.NFD, decomposed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &JokeHandler{opts: Options{UnicodeForm: tt.form}}
+
+			for _, input := range []string{composed, decomposed} {
+				got, err := h.prepareText(input)
+				if err != nil {
+					t.Fatalf("prepareText(%q): %v", input, err)
+				}
+				if got != tt.want {
+					t.Errorf("prepareText(%q) = %q (% x), want %q (% x)", input, got, got, tt.want, tt.want)
+				}
+			}
+		})
+	}
+
+	// Compatibility forms also fold ligatures and similar presentation
+	// variants.
+	h := &JokeHandler{opts: Options{UnicodeForm: norm.NFKC}}
+	if got, _ := h.prepareText("ﬁsh"); got != "fish" {
+		t.Errorf("NFKC prepareText(%q) = %q, want %q", "ﬁsh", got, "fish")
+	}
+}
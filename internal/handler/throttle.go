@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// errCodeDuplicateSubmission marks creates rejected by the submission
+// throttle.
+const errCodeDuplicateSubmission = "duplicate_submission"
+
+// submissionThrottle remembers recently submitted joke texts by their
+// normalized form, so the same text can be refused if it comes in again
+// within the window.
+type submissionThrottle struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func newSubmissionThrottle(window time.Duration) *submissionThrottle {
+	return &submissionThrottle{
+		window: window,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// allow records a submission of text at now. It returns false, with the
+// time left until the text may be submitted again, if the same normalized
+// text was submitted less than the window ago. Refused submissions don't
+// extend the window.
+func (t *submissionThrottle) allow(text string, now time.Time) (ok bool, retryAfter time.Duration) {
+	key := repository.NormalizeText(text)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Forget expired entries so the map only holds the current window.
+	for k, at := range t.seen {
+		if now.Sub(at) >= t.window {
+			delete(t.seen, k)
+		}
+	}
+
+	if at, found := t.seen[key]; found {
+		return false, t.window - now.Sub(at)
+	}
+
+	t.seen[key] = now
+	return true, 0
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// allowTimeFormat answers 400 and returns false when ?time_format is
+// neither rfc3339 nor unix.
+func allowTimeFormat(w http.ResponseWriter, r *http.Request) bool {
+	switch r.URL.Query().Get("time_format") {
+	case "", "rfc3339", "unix":
+		return true
+	default:
+		respondWithError(w, r, http.StatusBadRequest, "Invalid time_format parameter, expected rfc3339 or unix")
+		return false
+	}
+}
+
+// applyTimeFormat makes jokes render their timestamps in the format
+// ?time_format asks for. RFC 3339 is the default.
+func applyTimeFormat(r *http.Request, jokes ...*model.Joke) {
+	if r.URL.Query().Get("time_format") != "unix" {
+		return
+	}
+
+	for _, joke := range jokes {
+		joke.UnixTimes = true
+	}
+}
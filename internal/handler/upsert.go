@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// maxUpsertBatch bounds how many jokes one upsert request may carry, and
+// with it how long its transaction holds the write lock.
+const maxUpsertBatch = 500
+
+type UpsertJokesResponse struct {
+	BatchResult
+	Created  int `json:"created"`
+	Existing int `json:"existing"`
+}
+
+// UpsertJokes handles POST /api/admin/jokes/upsert. The body is an array of
+// jokes in the create format. Each joke is created unless one with the same
+// normalized text exists, which is returned instead, so syncing a known set
+// of jokes can be repeated safely. Invalid jokes are reported as failed
+// while the valid ones are stored together in one transaction, answering
+// 207 when some failed.
+func (h *JokeHandler) UpsertJokes(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r) {
+		return
+	}
+
+	var reqs []CreateJokeRequest
+
+	if !h.decodeBody(w, r, &reqs) {
+		return
+	}
+
+	if len(reqs) == 0 {
+		respondWithError(w, r, http.StatusBadRequest, "At least one joke is required")
+		return
+	}
+
+	if len(reqs) > maxUpsertBatch {
+		respondWithError(w, r, http.StatusBadRequest, fmt.Sprintf("At most %d jokes can be upserted at once", maxUpsertBatch))
+		return
+	}
+
+	response := UpsertJokesResponse{BatchResult: newBatchResult()}
+
+	jokes := make([]*model.Joke, 0, len(reqs))
+	positions := make([]int, 0, len(reqs))
+	for i, req := range reqs {
+		joke, err := h.prepareJoke(req)
+		if err != nil {
+			response.fail(i, textErrorCode(err), textErrorMessage(err))
+			continue
+		}
+		jokes = append(jokes, joke)
+		positions = append(positions, i)
+	}
+
+	if len(jokes) > 0 {
+		results, err := h.repo.GetOrCreateJokes(r.Context(), jokes)
+		if err != nil {
+			respondWithWriteError(w, r, h.log(r), err, "Failed to upsert jokes")
+			return
+		}
+
+		for i, result := range results {
+			response.succeed(positions[i], result)
+			if result.Created {
+				response.Created++
+			} else {
+				response.Existing++
+			}
+		}
+	}
+
+	h.log(r).Info("Upserted jokes", "created", response.Created, "existing", response.Existing, "failed", len(response.Failed))
+	respondWithJSON(w, response.status(), response)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import "net/http"
+
+type ValidateJokeResponse struct {
+	Valid     bool     `json:"valid"`
+	Joke      string   `json:"joke,omitempty"`
+	Setup     string   `json:"setup,omitempty"`
+	Punchline string   `json:"punchline,omitempty"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// ValidateJoke handles POST /api/admin/joke/validate. It runs the same
+// normalization and validation as CreateJoke and returns the text that would
+// be stored, without writing anything.
+func (h *JokeHandler) ValidateJoke(w http.ResponseWriter, r *http.Request) {
+	if !h.allowParams(w, r) {
+		return
+	}
+
+	var req CreateJokeRequest
+
+	if !h.decodeBody(w, r, &req) {
+		return
+	}
+
+	joke, err := h.prepareJoke(req)
+	if err != nil {
+		respondWithJSON(w, http.StatusUnprocessableEntity, ValidateJokeResponse{
+			Valid:  false,
+			Errors: []string{textErrorMessage(err)},
+		})
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ValidateJokeResponse{
+		Valid:     true,
+		Joke:      joke.Text,
+		Setup:     joke.Setup,
+		Punchline: joke.Punchline,
+	})
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestValidateJokeDoesNotPersist(t *testing.T) {
+	h, repo := newTestJokeHandler(t, Options{Sanitize: SanitizeStrip})
+
+	rec := serveRoute(h.ValidateJoke, http.MethodPost, "/joke/validate", "/joke/validate", `{"text":"  A <b>valid</b> joke  "}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("valid joke: status = %d, want %d (body %s)", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	var result ValidateJokeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if !result.Valid || result.Joke != "A valid joke" {
+		t.Errorf("result = %+v, want the joke as it would be stored", result)
+	}
+
+	rec = serveRoute(h.ValidateJoke, http.MethodPost, "/joke/validate", "/joke/validate", `{"text":"   "}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid joke: status = %d, want %d (body %s)", rec.Code, http.StatusUnprocessableEntity, rec.Body)
+	}
+
+	count, err := repo.CountJokes(context.Background())
+	if err != nil {
+		t.Fatalf("counting jokes: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("validating stored %d jokes, want none", count)
+	}
+}
@@ -0,0 +1,108 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUpstream        = errors.New("upstream joke source failed")
+	ErrUpstreamTimeout = errors.New("upstream joke source timed out")
+)
+
+// maxResponseSize bounds how much of an upstream response is read.
+const maxResponseSize = 5 << 20
+
+// RemoteSource fetches jokes from an external JSON API.
+//
+// ListField and TextField are dot separated paths into the response. The
+// jokes are read from the array at ListField (or the response itself when
+// it is empty) and each joke's text from TextField. A response that is a
+// single object instead of an array is treated as one joke.
+type RemoteSource struct {
+	URL       string
+	ListField string
+	TextField string
+	Client    *http.Client
+}
+
+func NewRemoteSource(url, listField, textField string, timeout time.Duration) *RemoteSource {
+	return &RemoteSource{
+		URL:       url,
+		ListField: listField,
+		TextField: textField,
+		Client:    &http.Client{Timeout: timeout},
+	}
+}
+
+// Fetch returns the non-empty joke texts found in the upstream response.
+func (s *RemoteSource) Fetch(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error building upstream request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		var netErr interface{ Timeout() bool }
+		if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+			return nil, fmt.Errorf("%w: %v", ErrUpstreamTimeout, err)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrUpstream, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrUpstream, resp.StatusCode)
+	}
+
+	var body any
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: invalid JSON: %v", ErrUpstream, err)
+	}
+
+	list := lookup(body, s.ListField)
+	items, ok := list.([]any)
+	if !ok {
+		items = []any{list}
+	}
+
+	texts := make([]string, 0, len(items))
+	for _, item := range items {
+		text, ok := lookup(item, s.TextField).(string)
+		if !ok {
+			continue
+		}
+
+		text = strings.TrimSpace(text)
+		if text != "" {
+			texts = append(texts, text)
+		}
+	}
+
+	return texts, nil
+}
+
+// lookup follows a dot separated path of object keys.
+func lookup(value any, path string) any {
+	if path == "" {
+		return value
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+
+	return value
+}
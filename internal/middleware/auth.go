@@ -1,15 +1,63 @@
 package middleware
 
 import (
+	"log/slog"
 	"net/http"
+	"path"
 )
 
 var Unauthorized = "Unauthorized"
 
-func AdminAuth(apiKey string) func(next http.Handler) http.Handler {
+// AdminKeyQueryParam is the query parameter clients that cannot set headers
+// may use to pass the admin key, see AdminAuthOptions.AllowQueryKey.
+const AdminKeyQueryParam = "api_key"
+
+type AdminAuthOptions struct {
+	// AllowQueryKey accepts the key from the api_key query parameter when
+	// the Admin-API-Key header is missing. Only requests made over TLS
+	// qualify, so the key is never sent in the clear.
+	AllowQueryKey bool
+
+	// Logger receives a warning whenever the query parameter is used.
+	Logger *slog.Logger
+
+	// JWT, when set, accepts "Authorization: Bearer" tokens it verifies.
+	// Tokens without the admin scope are answered with 403.
+	JWT *JWTVerifier
+}
+
+// AdminAuth requires the admin key in the Admin-API-Key header or, with
+// opts.JWT set, a bearer token granting the admin scope. An empty apiKey
+// disables the static key so only tokens are accepted.
+func AdminAuth(apiKey string, opts AdminAuthOptions) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token, ok := bearerToken(r); ok && opts.JWT != nil {
+				if verifyBearer(w, opts.JWT, token) {
+					next.ServeHTTP(w, r)
+				}
+				return
+			}
+
+			if apiKey == "" {
+				http.Error(w, Unauthorized, http.StatusUnauthorized)
+				return
+			}
+
 			key := r.Header.Get("Admin-API-Key")
+			if key == "" && opts.AllowQueryKey && isTLS(r) {
+				query := r.URL.Query()
+				if key = query.Get(AdminKeyQueryParam); key != "" {
+					// Handlers should never see the key.
+					query.Del(AdminKeyQueryParam)
+					r.URL.RawQuery = query.Encode()
+
+					if opts.Logger != nil {
+						opts.Logger.Warn("Admin key passed as query parameter", "remote_addr", r.RemoteAddr, "uri", r.URL.RequestURI())
+					}
+				}
+			}
+
 			if key == "" {
 				http.Error(w, Unauthorized, http.StatusUnauthorized)
 				return
@@ -24,3 +72,47 @@ func AdminAuth(apiKey string) func(next http.Handler) http.Handler {
 		})
 	}
 }
+
+// isTLS reports whether the client connected to this server over TLS.
+// X-Forwarded-Proto is ignored: any client can send it, so it cannot vouch
+// for the connection.
+func isTLS(r *http.Request) bool {
+	return r.TLS != nil
+}
+
+// ReadAuth requires a key for GET and HEAD requests whose path matches one
+// of the patterns (see path.Match), leaving all other reads public. The
+// read key is accepted in the Read-API-Key header; the admin key works as
+// well, in the Admin-API-Key header.
+func ReadAuth(readKey, adminKey string, patterns ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead || !matchesAny(r.URL.Path, patterns) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if key := r.Header.Get("Read-API-Key"); key != "" && key == readKey {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if key := r.Header.Get("Admin-API-Key"); key != "" && key == adminKey {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			http.Error(w, Unauthorized, http.StatusUnauthorized)
+		})
+	}
+}
+
+func matchesAny(urlPath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, urlPath); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdminAuthQueryKey(t *testing.T) {
+	const apiKey = "secret"
+
+	tests := []struct {
+		name          string
+		allowQueryKey bool
+		tls           bool
+		header        http.Header
+		wantStatus    int
+	}{
+		{"enabled over TLS", true, true, nil, http.StatusOK},
+		{"enabled without TLS", true, false, nil, http.StatusUnauthorized},
+		{"forged X-Forwarded-Proto", true, false, http.Header{"X-Forwarded-Proto": {"https"}}, http.StatusUnauthorized},
+		{"disabled", false, true, nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			var seenQuery string
+
+			h := AdminAuth(apiKey, AdminAuthOptions{
+				AllowQueryKey: tt.allowQueryKey,
+				Logger:        slog.New(slog.NewTextHandler(&logs, nil)),
+			})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seenQuery = r.URL.RawQuery
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/runtime?api_key="+apiKey+"&x=1", nil)
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			if strings.Contains(seenQuery, apiKey) {
+				t.Errorf("handler saw the key in query %q", seenQuery)
+			}
+			if !strings.Contains(logs.String(), "Admin key passed as query parameter") {
+				t.Errorf("no warning logged, got %q", logs.String())
+			}
+			if strings.Contains(logs.String(), apiKey) {
+				t.Errorf("warning contains the key: %q", logs.String())
+			}
+		})
+	}
+}
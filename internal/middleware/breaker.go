@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Breaker reports whether calls to a dependency are currently being
+// rejected, and for how long.
+type Breaker interface {
+	Open() (retryAfter time.Duration, open bool)
+}
+
+// ShortCircuit answers requests with 503 Service Unavailable and a
+// Retry-After header while breaker is open, instead of letting each of
+// them fail against the unhealthy dependency.
+func ShortCircuit(breaker Breaker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if retryAfter, open := breaker.Open(); open {
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeBreaker struct {
+	retryAfter time.Duration
+	open       bool
+}
+
+func (b fakeBreaker) Open() (time.Duration, bool) {
+	return b.retryAfter, b.open
+}
+
+func TestShortCircuit(t *testing.T) {
+	tests := []struct {
+		name           string
+		breaker        fakeBreaker
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{"closed", fakeBreaker{}, http.StatusOK, ""},
+		{"open", fakeBreaker{retryAfter: 2500 * time.Millisecond, open: true}, http.StatusServiceUnavailable, "3"},
+		{"open below a second", fakeBreaker{retryAfter: time.Millisecond, open: true}, http.StatusServiceUnavailable, "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := ShortCircuit(tt.breaker)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/joke/random", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maxCachedResponseBytes is the largest response body the cache keeps.
+	maxCachedResponseBytes = 1 << 20
+
+	// maxCachedResponses bounds the number of cache entries.
+	maxCachedResponses = 1000
+
+	// CacheStatusHeader tells whether a response came from the cache.
+	CacheStatusHeader = "X-Cache"
+)
+
+// ResponseCache keeps successful GET responses in memory for a short time,
+// keyed on path and query. Any successful write through InvalidateOnWrite
+// bumps a version counter, which invalidates every entry at once.
+type ResponseCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	version atomic.Uint64
+
+	mu      sync.Mutex
+	entries map[string]*cachedResponse
+}
+
+type cachedResponse struct {
+	version uint64
+	expires time.Time
+	status  int
+	// header holds the headers the handler set and appended holds the
+	// values it added to headers set before it ran, such as Vary. Headers
+	// set per request by the middleware in front of the cache, like CORS
+	// and request IDs, are in neither, so a hit keeps the live ones.
+	header   http.Header
+	appended http.Header
+	body     []byte
+}
+
+func NewResponseCache(ttl time.Duration) *ResponseCache {
+	return &ResponseCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*cachedResponse),
+	}
+}
+
+// Invalidate drops all cached responses.
+func (c *ResponseCache) Invalidate() {
+	c.version.Add(1)
+}
+
+// Handler serves GET requests from the cache when possible and caches 200
+// responses otherwise. Requests with Cache-Control: no-cache or
+// conditional headers skip the lookup. Responses are passed through while
+// they are recorded, so streaming responses still stream.
+func (c *ResponseCache) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := cacheKey(r)
+		version := c.version.Load()
+
+		if !bypassCache(r) {
+			if entry := c.lookup(key, version); entry != nil {
+				for name, values := range entry.header {
+					w.Header()[name] = append([]string(nil), values...)
+				}
+				for name, values := range entry.appended {
+					w.Header()[name] = append(w.Header()[name], values...)
+				}
+				w.Header().Set(CacheStatusHeader, "HIT")
+				w.WriteHeader(entry.status)
+				w.Write(entry.body)
+				return
+			}
+		}
+
+		w.Header().Set(CacheStatusHeader, "MISS")
+		rec := &recordingResponseWriter{ResponseWriter: w, base: w.Header().Clone()}
+		next.ServeHTTP(rec, r)
+
+		if rec.status == http.StatusOK && !rec.overflow {
+			c.store(key, &cachedResponse{
+				version:  version,
+				expires:  c.now().Add(c.ttl),
+				status:   rec.status,
+				header:   rec.header,
+				appended: rec.appended,
+				body:     rec.body.Bytes(),
+			})
+		}
+	})
+}
+
+// InvalidateOnWrite invalidates the cache after every successful request
+// with a method other than GET, HEAD or OPTIONS.
+func (c *ResponseCache) InvalidateOnWrite(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &recordingResponseWriter{ResponseWriter: w, overflow: true}
+		next.ServeHTTP(rec, r)
+
+		if rec.status == 0 || rec.status < http.StatusBadRequest {
+			c.Invalidate()
+		}
+	})
+}
+
+func (c *ResponseCache) lookup(key string, version uint64) *cachedResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+
+	if entry.version != version || !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil
+	}
+
+	return entry
+}
+
+func (c *ResponseCache) store(key string, entry *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxCachedResponses {
+		now := c.now()
+		current := c.version.Load()
+		for k, e := range c.entries {
+			if e.version != current || !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+
+		// Still full of live entries: start over rather than track usage.
+		if len(c.entries) >= maxCachedResponses {
+			clear(c.entries)
+		}
+	}
+
+	c.entries[key] = entry
+}
+
+// cacheKey identifies a response by path and query, plus the Prefer header,
+// which can change the page size, and the Accept header, which can change
+// the format.
+func cacheKey(r *http.Request) string {
+	key := r.URL.RequestURI()
+	for _, name := range []string{"Prefer", "Accept"} {
+		if values := r.Header.Values(name); len(values) > 0 {
+			key += "\x00" + name + ":" + strings.Join(values, ",")
+		}
+	}
+	return key
+}
+
+// bypassCache reports whether the request asks for a fresh response, either
+// explicitly or by being conditional, which the cache doesn't evaluate.
+func bypassCache(r *http.Request) bool {
+	for _, directive := range strings.Split(r.Header.Get("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-cache") {
+			return true
+		}
+	}
+
+	return r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != ""
+}
+
+// recordingResponseWriter passes the response through while keeping a copy
+// of its status, the headers set since base was taken and, unless overflow
+// is set, body.
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	status   int
+	base     http.Header
+	header   http.Header
+	appended http.Header
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (w *recordingResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+		w.header, w.appended = headerChanges(w.base, w.ResponseWriter.Header())
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// headerChanges returns the headers of current that differ from base:
+// set holds those that are new or were replaced, appended the values added
+// after the ones base already had.
+func headerChanges(base, current http.Header) (set, appended http.Header) {
+	set, appended = make(http.Header), make(http.Header)
+	for name, values := range current {
+		old := base[name]
+		switch {
+		case slices.Equal(old, values):
+		case len(old) > 0 && len(values) > len(old) && slices.Equal(values[:len(old)], old):
+			appended[name] = slices.Clone(values[len(old):])
+		default:
+			set[name] = slices.Clone(values)
+		}
+	}
+	return set, appended
+}
+
+func (w *recordingResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if !w.overflow {
+		if w.body.Len()+len(b) > maxCachedResponseBytes {
+			w.overflow = true
+			w.body = bytes.Buffer{}
+		} else {
+			w.body.Write(b)
+		}
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, for
+// flushing streamed responses.
+func (w *recordingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"path"
+)
+
+// CacheControlPolicy is the Cache-Control value for the responses to paths
+// matching Path, a path.Match pattern such as /api/joke/*.
+type CacheControlPolicy struct {
+	Path  string
+	Value string
+}
+
+// CacheControl sets the Cache-Control header of successful GET and HEAD
+// responses, and of 304s, from the first policy whose path matches. Handlers
+// that set the header themselves, such as the streams, keep their value.
+func CacheControl(policies ...CacheControlPolicy) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			value, ok := cacheControlFor(r.URL.Path, policies)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(&cacheControlResponseWriter{ResponseWriter: w, value: value}, r)
+		})
+	}
+}
+
+func cacheControlFor(urlPath string, policies []CacheControlPolicy) (string, bool) {
+	for _, policy := range policies {
+		if ok, _ := path.Match(policy.Path, urlPath); ok {
+			return policy.Value, true
+		}
+	}
+
+	return "", false
+}
+
+type cacheControlResponseWriter struct {
+	http.ResponseWriter
+	value   string
+	written bool
+}
+
+func (w *cacheControlResponseWriter) WriteHeader(status int) {
+	if !w.written {
+		w.written = true
+		cacheable := status >= 200 && status < 300 || status == http.StatusNotModified
+		if cacheable && w.Header().Get("Cache-Control") == "" {
+			w.Header().Set("Cache-Control", w.value)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *cacheControlResponseWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, for
+// flushing streamed responses.
+func (w *cacheControlResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCacheControl(t *testing.T) {
+	policies := []CacheControlPolicy{
+		{Path: "/api/joke/random", Value: "no-store"},
+		{Path: "/api/joke/", Value: "public, max-age=60"},
+		{Path: "/api/joke/*", Value: "public, max-age=300"},
+	}
+
+	h := CacheControl(policies...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/joke/checksum":
+			w.Header().Set("Cache-Control", "no-cache")
+		case "/api/joke/404":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("{}"))
+	}))
+
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "/api/joke/", "public, max-age=60"},
+		{http.MethodGet, "/api/joke/random", "no-store"},
+		{http.MethodGet, "/api/joke/42", "public, max-age=300"},
+		{http.MethodHead, "/api/joke/42", "public, max-age=300"},
+		// The handler's own value wins.
+		{http.MethodGet, "/api/joke/checksum", "no-cache"},
+		// Errors, writes and unmatched paths are left alone.
+		{http.MethodGet, "/api/joke/404", ""},
+		{http.MethodPost, "/api/joke/42", ""},
+		{http.MethodGet, "/api/collections/1", ""},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+		if got := rec.Header().Get("Cache-Control"); got != tt.want {
+			t.Errorf("%s %s: Cache-Control = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
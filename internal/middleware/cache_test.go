@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewResponseCache(5 * time.Second)
+	cache.now = func() time.Time { return now }
+
+	calls := 0
+	h := cache.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"call":%d}`, calls)
+	}))
+	write := cache.InvalidateOnWrite(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	get := func(t *testing.T, target string, header http.Header) (status, body string) {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", ct)
+		}
+		return rec.Header().Get(CacheStatusHeader), rec.Body.String()
+	}
+
+	expect := func(t *testing.T, target string, header http.Header, wantStatus, wantBody string) {
+		t.Helper()
+
+		status, body := get(t, target, header)
+		if status != wantStatus || body != wantBody {
+			t.Errorf("GET %s = %s %s, want %s %s", target, status, body, wantStatus, wantBody)
+		}
+	}
+
+	expect(t, "/api/joke/?limit=5", nil, "MISS", `{"call":1}`)
+	expect(t, "/api/joke/?limit=5", nil, "HIT", `{"call":1}`)
+
+	// A different query is a different entry.
+	expect(t, "/api/joke/?limit=6", nil, "MISS", `{"call":2}`)
+
+	// no-cache skips the lookup and refreshes the entry.
+	expect(t, "/api/joke/?limit=5", http.Header{"Cache-Control": {"no-cache"}}, "MISS", `{"call":3}`)
+	expect(t, "/api/joke/?limit=5", nil, "HIT", `{"call":3}`)
+
+	// A successful write invalidates every entry.
+	write.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/admin/joke", nil))
+	expect(t, "/api/joke/?limit=5", nil, "MISS", `{"call":4}`)
+	expect(t, "/api/joke/?limit=5", nil, "HIT", `{"call":4}`)
+
+	// Entries expire after the TTL.
+	now = now.Add(6 * time.Second)
+	expect(t, "/api/joke/?limit=5", nil, "MISS", `{"call":5}`)
+}
+
+func TestResponseCacheSkipsErrors(t *testing.T) {
+	cache := NewResponseCache(time.Minute)
+	h := cache.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/joke/404", nil))
+
+		if got := rec.Header().Get(CacheStatusHeader); got != "MISS" {
+			t.Fatalf("request %d: %s = %q, want MISS", i+1, CacheStatusHeader, got)
+		}
+	}
+}
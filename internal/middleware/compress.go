@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"compress/flate"
+	"net/http"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// compressibleTypes are the response content types that get compressed.
+// Streaming types such as text/event-stream are deliberately missing: a
+// compressor holds data back until its buffer fills, which stalls streams.
+var compressibleTypes = []string{
+	"application/json",
+	"text/plain",
+	"text/html",
+	"text/csv",
+}
+
+// Compress compresses responses with a compressible content type for
+// clients that accept it, at the given flate level (1 to 9). A level of 0
+// selects flate.DefaultCompression. Requests whose path matches one of the
+// excludedPaths patterns (see path.Match) are never compressed.
+//
+// chi's Compressor keeps a sync.Pool per encoding for writers that can be
+// Reset, which gzip and deflate writers can, so responses reuse compressor
+// state instead of allocating it every time.
+func Compress(level int, excludedPaths ...string) func(http.Handler) http.Handler {
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+
+	compress := chimiddleware.NewCompressor(level, compressibleTypes...).Handler
+
+	return func(next http.Handler) http.Handler {
+		compressed := compress(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if matchesAny(r.URL.Path, excludedPaths) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			compressed.ServeHTTP(w, r)
+		})
+	}
+}
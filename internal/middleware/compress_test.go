@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var compressTestBody = []byte(`{"jokes":[` + strings.Repeat(`{"id":1,"text":"Why did the gopher cross the road?"},`, 200) + `{"id":2,"text":"done"}]}`)
+
+func jsonHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(compressTestBody)
+}
+
+func TestCompressLevels(t *testing.T) {
+	for _, level := range []int{0, flate.DefaultCompression, flate.BestSpeed, 5, flate.BestCompression} {
+		t.Run(fmt.Sprintf("level %d", level), func(t *testing.T) {
+			h := Compress(level)(http.HandlerFunc(jsonHandler))
+
+			// Several requests, so pooled writers get reused.
+			for i := 0; i < 3; i++ {
+				req := httptest.NewRequest(http.MethodGet, "/api/joke/", nil)
+				req.Header.Set("Accept-Encoding", "gzip")
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, req)
+
+				if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+					t.Fatalf("Content-Encoding = %q, want gzip", got)
+				}
+				if rec.Body.Len() >= len(compressTestBody) {
+					t.Errorf("compressed body is %d bytes, not smaller than %d", rec.Body.Len(), len(compressTestBody))
+				}
+
+				zr, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatalf("gzip.NewReader: %v", err)
+				}
+				body, err := io.ReadAll(zr)
+				if err != nil {
+					t.Fatalf("decompressing: %v", err)
+				}
+				if !bytes.Equal(body, compressTestBody) {
+					t.Fatal("decompressed body differs from the original")
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkCompress measures the middleware, which reuses pooled gzip
+// writers. Compare its allocations with BenchmarkCompressUnpooled.
+func BenchmarkCompress(b *testing.B) {
+	h := Compress(flate.DefaultCompression)(http.HandlerFunc(jsonHandler))
+	req := httptest.NewRequest(http.MethodGet, "/api/joke/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+// BenchmarkCompressUnpooled is the baseline of a new gzip writer per
+// response.
+func BenchmarkCompressUnpooled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rec := httptest.NewRecorder()
+		rec.Header().Set("Content-Encoding", "gzip")
+
+		zw, err := gzip.NewWriterLevel(rec, flate.DefaultCompression)
+		if err != nil {
+			b.Fatal(err)
+		}
+		zw.Write(compressTestBody)
+		zw.Close()
+	}
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DebugResponsesFlag is the feature flag that allows clients to request
+// debug information with ?debug=true.
+const DebugResponsesFlag = "debug_responses"
+
+// debugInfo is rendered as the "_debug" object of a JSON response.
+type debugInfo struct {
+	Route      string            `json:"route"`
+	Filters    map[string]string `json:"filters"`
+	Queries    []queryTiming     `json:"queries"`
+	DurationMS float64           `json:"duration_ms"`
+}
+
+// DebugResponses adds a "_debug" object with the matched route pattern, the
+// query parameters and the repository call timings to JSON object responses
+// when the request has ?debug=true and the debug_responses flag is enabled.
+// The debug parameter is stripped before the request reaches the handler.
+func DebugResponses(flags FeatureChecker) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			if query.Get("debug") != "true" || !flags.Enabled(DebugResponsesFlag) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			query.Del("debug")
+			r.URL.RawQuery = query.Encode()
+
+			info := &debugInfo{Filters: make(map[string]string, len(query))}
+			for name := range query {
+				info.Filters[name] = query.Get(name)
+			}
+
+			r, timings := withQueryTimings(r)
+			rec := &bufferedResponseWriter{ResponseWriter: w}
+			start := time.Now()
+			next.ServeHTTP(rec, r)
+
+			info.DurationMS = milliseconds(time.Since(start))
+			info.Queries, _ = timings.snapshot()
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				info.Route = rctx.RoutePattern()
+			}
+
+			body := rec.body.Bytes()
+			if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+				body = withDebug(body, info)
+				w.Header().Del("Content-Length")
+			}
+
+			if rec.status != 0 {
+				w.WriteHeader(rec.status)
+			}
+			w.Write(body)
+		})
+	}
+}
+
+// withDebug inserts info as the last member of the JSON object in body.
+// Anything other than an object is returned unchanged.
+func withDebug(body []byte, info *debugInfo) []byte {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) < 2 || trimmed[0] != '{' || trimmed[len(trimmed)-1] != '}' {
+		return body
+	}
+
+	encoded, err := json.Marshal(info)
+	if err != nil {
+		return body
+	}
+
+	var out bytes.Buffer
+	out.Write(trimmed[:len(trimmed)-1])
+	if len(bytes.TrimSpace(trimmed[1:len(trimmed)-1])) > 0 {
+		out.WriteByte(',')
+	}
+	out.WriteString(`"_debug":`)
+	out.Write(encoded)
+	out.WriteString("}\n")
+
+	return out.Bytes()
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// bufferedResponseWriter holds back the status and body so they can be
+// rewritten after the handler returns.
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	return w.body.Write(b)
+}
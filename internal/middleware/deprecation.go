@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Deprecation marks an endpoint, or one of its query parameters, as
+// deprecated.
+type Deprecation struct {
+	// Path is a path.Match pattern such as /api/joke/*.
+	Path string
+
+	// Param limits the deprecation to requests that use this query
+	// parameter. The whole endpoint is deprecated when it is empty.
+	Param string
+
+	// Since is when the deprecation took effect.
+	Since time.Time
+
+	// Sunset, when set, is when the endpoint or parameter stops working.
+	Sunset time.Time
+
+	// Link, when set, points to documentation on what to use instead.
+	Link string
+}
+
+func (d Deprecation) matches(r *http.Request) bool {
+	if ok, _ := path.Match(d.Path, r.URL.Path); !ok {
+		return false
+	}
+
+	return d.Param == "" || r.URL.Query().Has(d.Param)
+}
+
+// Deprecated announces deprecations to clients that use them, with the
+// Deprecation (RFC 9745) and Sunset (RFC 8594) headers and a Link to the
+// documentation, and logs a warning so their remaining use can be tracked.
+// The first matching deprecation applies.
+func Deprecated(logger *slog.Logger, deprecations ...Deprecation) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, d := range deprecations {
+				if !d.matches(r) {
+					continue
+				}
+
+				w.Header().Set("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+				if !d.Sunset.IsZero() {
+					w.Header().Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+				}
+				if d.Link != "" {
+					w.Header().Add("Link", "<"+d.Link+`>; rel="deprecation"`)
+				}
+
+				attrs := []any{"path", r.URL.Path, "deprecated", d.Path}
+				if d.Param != "" {
+					attrs = append(attrs, "param", d.Param)
+				}
+				if id := middleware.GetReqID(r.Context()); id != "" {
+					attrs = append(attrs, "request_id", id)
+				}
+				logger.WarnContext(r.Context(), "Deprecated API used", attrs...)
+				break
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
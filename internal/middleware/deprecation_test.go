@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeprecated(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	var logs bytes.Buffer
+	h := Deprecated(slog.New(slog.NewTextHandler(&logs, nil)),
+		Deprecation{Path: "/api/surprise", Since: since, Sunset: sunset, Link: "https://example.com/docs/random"},
+		Deprecation{Path: "/api/joke/", Param: "offset", Since: since},
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		target          string
+		wantDeprecation string
+		wantSunset      string
+		wantLink        string
+		wantWarning     bool
+	}{
+		{
+			target:          "/api/surprise",
+			wantDeprecation: "@1704067200",
+			wantSunset:      "Mon, 01 Jul 2024 00:00:00 GMT",
+			wantLink:        `<https://example.com/docs/random>; rel="deprecation"`,
+			wantWarning:     true,
+		},
+		{target: "/api/joke/?offset=20", wantDeprecation: "@1704067200", wantWarning: true},
+		{target: "/api/joke/?limit=20"},
+		{target: "/api/joke/random"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			logs.Reset()
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if got := rec.Header().Get("Deprecation"); got != tt.wantDeprecation {
+				t.Errorf("Deprecation = %q, want %q", got, tt.wantDeprecation)
+			}
+			if got := rec.Header().Get("Sunset"); got != tt.wantSunset {
+				t.Errorf("Sunset = %q, want %q", got, tt.wantSunset)
+			}
+			if got := rec.Header().Get("Link"); got != tt.wantLink {
+				t.Errorf("Link = %q, want %q", got, tt.wantLink)
+			}
+			if got := strings.Contains(logs.String(), "Deprecated API used"); got != tt.wantWarning {
+				t.Errorf("logged warning = %v, want %v: %s", got, tt.wantWarning, logs.String())
+			}
+		})
+	}
+}
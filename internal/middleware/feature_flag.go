@@ -0,0 +1,22 @@
+package middleware
+
+import "net/http"
+
+type FeatureChecker interface {
+	Enabled(name string) bool
+}
+
+// RequireFeature hides the wrapped routes behind a 404 while the named
+// feature flag is disabled.
+func RequireFeature(flags FeatureChecker, name string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !flags.Enabled(name) {
+				http.NotFound(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var Forbidden = "Forbidden"
+
+// AdminScope is the scope a bearer token needs to use the admin API.
+const AdminScope = "admin"
+
+var errMissingScope = errors.New("token lacks the admin scope")
+
+// JWTOptions are the optional claims a JWTVerifier checks besides the
+// signature, expiry and scope.
+type JWTOptions struct {
+	Issuer   string
+	Audience string
+}
+
+// JWTVerifier validates bearer tokens issued by an identity provider.
+type JWTVerifier struct {
+	parser *jwt.Parser
+	key    any
+}
+
+// NewJWTVerifier returns a verifier for tokens signed with key, which is
+// either an HMAC secret ([]byte) or a public key as returned by
+// ParsePublicKeyPEM. Only the algorithms matching the key type are
+// accepted, so an RSA public key can't be abused as an HMAC secret.
+func NewJWTVerifier(key any, opts JWTOptions) (*JWTVerifier, error) {
+	var methods []string
+	switch key.(type) {
+	case []byte:
+		methods = []string{"HS256", "HS384", "HS512"}
+	case *rsa.PublicKey:
+		methods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}
+	case *ecdsa.PublicKey:
+		methods = []string{"ES256", "ES384", "ES512"}
+	case ed25519.PublicKey:
+		methods = []string{"EdDSA"}
+	default:
+		return nil, fmt.Errorf("unsupported JWT key type %T", key)
+	}
+
+	parserOpts := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+
+	return &JWTVerifier{parser: jwt.NewParser(parserOpts...), key: key}, nil
+}
+
+// ParsePublicKeyPEM parses a PEM encoded RSA, ECDSA or Ed25519 public key.
+func ParsePublicKeyPEM(data []byte) (any, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseECPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	return nil, errors.New("no RSA, ECDSA or Ed25519 public key found")
+}
+
+// Verify checks the token's signature and standard claims, and that it
+// grants the admin scope. A token that is valid but lacks the scope
+// returns errMissingScope.
+func (v *JWTVerifier) Verify(token string) error {
+	claims := &scopeClaims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+		return v.key, nil
+	}); err != nil {
+		return err
+	}
+
+	if !claims.hasScope(AdminScope) {
+		return errMissingScope
+	}
+
+	return nil
+}
+
+// scopeClaims accepts the scope either as the space separated "scope"
+// string of RFC 8693 or as the "scp" list some providers use instead.
+type scopeClaims struct {
+	jwt.RegisteredClaims
+	Scope string   `json:"scope"`
+	Scp   []string `json:"scp"`
+}
+
+func (c *scopeClaims) hasScope(scope string) bool {
+	return slices.Contains(strings.Fields(c.Scope), scope) || slices.Contains(c.Scp, scope)
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// verifyBearer answers requests whose bearer token fails verification with
+// 401, or with 403 when only the admin scope is missing, and reports
+// whether the request may proceed.
+func verifyBearer(w http.ResponseWriter, verifier *JWTVerifier, token string) bool {
+	err := verifier.Verify(token)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, errMissingScope):
+		w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+AdminScope+`"`)
+		http.Error(w, Forbidden, http.StatusForbidden)
+	default:
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		http.Error(w, Unauthorized, http.StatusUnauthorized)
+	}
+
+	return false
+}
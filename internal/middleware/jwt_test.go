@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestAdminAuthJWT(t *testing.T) {
+	secret := []byte("jwt-test-secret")
+	verifier, err := NewJWTVerifier(secret, JWTOptions{Issuer: "https://id.example.com"})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier: %v", err)
+	}
+
+	sign := func(claims jwt.MapClaims, key []byte) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+		if err != nil {
+			t.Fatalf("signing token: %v", err)
+		}
+		return token
+	}
+
+	now := time.Now()
+	claims := func(scope string, expiresAt time.Time) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   "https://id.example.com",
+			"sub":   "ops",
+			"scope": scope,
+			"exp":   expiresAt.Unix(),
+		}
+	}
+
+	tests := []struct {
+		name       string
+		header     http.Header
+		wantStatus int
+	}{
+		{"valid token", http.Header{"Authorization": {"Bearer " + sign(claims("read admin", now.Add(time.Hour)), secret)}}, http.StatusOK},
+		{"scp list", http.Header{"Authorization": {"Bearer " + sign(jwt.MapClaims{"iss": "https://id.example.com", "scp": []string{"admin"}, "exp": now.Add(time.Hour).Unix()}, secret)}}, http.StatusOK},
+		{"expired token", http.Header{"Authorization": {"Bearer " + sign(claims("admin", now.Add(-time.Minute)), secret)}}, http.StatusUnauthorized},
+		{"missing admin scope", http.Header{"Authorization": {"Bearer " + sign(claims("read", now.Add(time.Hour)), secret)}}, http.StatusForbidden},
+		{"wrong secret", http.Header{"Authorization": {"Bearer " + sign(claims("admin", now.Add(time.Hour)), []byte("other"))}}, http.StatusUnauthorized},
+		{"wrong issuer", http.Header{"Authorization": {"Bearer " + sign(jwt.MapClaims{"iss": "https://evil.example.com", "scope": "admin", "exp": now.Add(time.Hour).Unix()}, secret)}}, http.StatusUnauthorized},
+		{"no expiry", http.Header{"Authorization": {"Bearer " + sign(jwt.MapClaims{"iss": "https://id.example.com", "scope": "admin"}, secret)}}, http.StatusUnauthorized},
+		{"static key", http.Header{"Admin-Api-Key": {"static"}}, http.StatusOK},
+		{"no credentials", http.Header{}, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := AdminAuth("static", AdminAuthOptions{JWT: verifier})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			req := httptest.NewRequest(http.MethodGet, "/runtime", nil)
+			req.Header = tt.header
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAdminAuthJWTOnly(t *testing.T) {
+	verifier, err := NewJWTVerifier([]byte("jwt-test-secret"), JWTOptions{})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier: %v", err)
+	}
+
+	h := AdminAuth("", AdminAuthOptions{JWT: verifier})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/runtime", nil)
+	req.Header.Set("Admin-API-Key", "guess")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestJWTVerifierPublicKey(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatalf("marshaling key: %v", err)
+	}
+
+	key, err := ParsePublicKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("ParsePublicKeyPEM: %v", err)
+	}
+
+	verifier, err := NewJWTVerifier(key, JWTOptions{})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier: %v", err)
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+		"scope": "admin",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}).SignedString(private)
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+
+	if err := verifier.Verify(token); err != nil {
+		t.Errorf("Verify = %v, want nil", err)
+	}
+
+	// An HMAC token must not verify against a public key.
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"scope": "admin",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(der))
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+
+	if err := verifier.Verify(forged); err == nil {
+		t.Error("Verify accepted an HMAC token signed with the public key")
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+)
+
+// MaxURLLength rejects requests whose request target is longer than max
+// bytes with 414 URI Too Long. A max of zero disables the check.
+func MaxURLLength(max int) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if max > 0 && len(r.RequestURI) > max {
+				http.Error(w, http.StatusText(http.StatusRequestURITooLong), http.StatusRequestURITooLong)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// BodyReadTimeout gives requests that carry a body, such as POST and PUT,
+// timeout to deliver it, counted from when the handler starts. A client
+// that dribbles the body slower than that makes reads fail with
+// os.ErrDeadlineExceeded instead of holding the connection open. Writers
+// that don't support read deadlines, as in tests, are left alone.
+func BodyReadTimeout(timeout time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				http.NewResponseController(w).SetReadDeadline(time.Now().Add(timeout))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxURLLength(t *testing.T) {
+	handler := MaxURLLength(64)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	get := func(target string) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec.Code
+	}
+
+	if status := get("/api/joke/?limit=5"); status != http.StatusOK {
+		t.Errorf("short URL: status = %d, want %d", status, http.StatusOK)
+	}
+	if status := get("/api/joke/?q=" + strings.Repeat("a", 64)); status != http.StatusRequestURITooLong {
+		t.Errorf("long URL: status = %d, want %d", status, http.StatusRequestURITooLong)
+	}
+}
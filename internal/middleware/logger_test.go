@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLoggerRecordsStatusSizeAndDuration(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+
+	h := Logger(logger, 1)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Millisecond)
+		if r.URL.Path == "/missing" {
+			http.Error(w, "not here", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("hello"))
+	}))
+
+	tests := []struct {
+		path   string
+		status int
+		bytes  int
+	}{
+		{"/ok", http.StatusOK, len("hello")},
+		{"/missing", http.StatusNotFound, len("not here\n")},
+	}
+
+	for _, tt := range tests {
+		logs.Reset()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		var entry struct {
+			URI      string        `json:"uri"`
+			Status   int           `json:"status"`
+			Bytes    int           `json:"bytes"`
+			Duration time.Duration `json:"duration"`
+		}
+		if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+			t.Fatalf("%s: decoding log line %q: %v", tt.path, logs.String(), err)
+		}
+
+		if entry.URI != tt.path {
+			t.Errorf("%s: uri = %q", tt.path, entry.URI)
+		}
+		if entry.Status != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.path, entry.Status, tt.status)
+		}
+		if entry.Bytes != tt.bytes {
+			t.Errorf("%s: bytes = %d, want %d", tt.path, entry.Bytes, tt.bytes)
+		}
+		if entry.Duration < 2*time.Millisecond {
+			t.Errorf("%s: duration = %v, want at least the 2ms the handler took", tt.path, entry.Duration)
+		}
+	}
+}
@@ -1,23 +1,127 @@
 package middleware
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
 )
 
-func Logger(logger *slog.Logger) func(http.Handler) http.Handler {
+// Logger logs every request that ends in a 4xx or 5xx status, but only one
+// in sampleEvery of the other requests. A sampleEvery of 1 or less logs all
+// requests.
+func Logger(logger *slog.Logger, sampleEvery int) func(http.Handler) http.Handler {
+	var successes atomic.Uint64
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			var (
 				ip     = r.RemoteAddr
 				proto  = r.Proto
 				method = r.Method
-				uri    = r.URL.RequestURI()
+				uri    = redactedURI(r)
+				start  = time.Now()
 			)
 
-			logger.Info("Received request", "remote_addr", ip, "proto", proto, "method", method, "uri", uri)
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			// Handlers that never write anything still result in an implicit 200.
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			if status < http.StatusBadRequest && sampleEvery > 1 && successes.Add(1)%uint64(sampleEvery) != 1 {
+				return
+			}
 
-			next.ServeHTTP(w, r)
+			logger.Info("Handled request",
+				"request_id", chimiddleware.GetReqID(r.Context()),
+				"remote_addr", ip,
+				"proto", proto,
+				"method", method,
+				"uri", uri,
+				"status", status,
+				"bytes", ww.BytesWritten(),
+				"duration", time.Since(start),
+			)
 		})
 	}
 }
+
+// redactedURI returns the request URI with the admin key query parameter,
+// if any, masked so it never ends up in the logs.
+func redactedURI(r *http.Request) string {
+	query := r.URL.Query()
+	if !query.Has(AdminKeyQueryParam) {
+		return r.URL.RequestURI()
+	}
+
+	query.Set(AdminKeyQueryParam, "REDACTED")
+	u := *r.URL
+	u.RawQuery = query.Encode()
+
+	return u.RequestURI()
+}
+
+// RequestIDHeader exposes the ID assigned by chi's RequestID middleware to
+// clients via the X-Request-ID response header. It must run after RequestID.
+func RequestIDHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := chimiddleware.GetReqID(r.Context()); id != "" {
+			w.Header().Set(chimiddleware.RequestIDHeader, id)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CorrelationIDHeader carries the ID a gateway assigned to a request.
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// maxCorrelationIDLength bounds adopted correlation IDs, which end up in
+// every log line of the request.
+const maxCorrelationIDLength = 128
+
+// CorrelationID adopts a valid incoming X-Correlation-ID as the request ID,
+// replacing the one assigned by chi's RequestID, and echoes the resulting
+// ID in the X-Correlation-ID response header. Requests without a usable
+// correlation ID keep their generated request ID. It must run after
+// RequestID.
+func CorrelationID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := r.Header.Get(CorrelationIDHeader); validCorrelationID(id) {
+			r = r.WithContext(context.WithValue(r.Context(), chimiddleware.RequestIDKey, id))
+		}
+
+		if id := chimiddleware.GetReqID(r.Context()); id != "" {
+			w.Header().Set(CorrelationIDHeader, id)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validCorrelationID accepts short IDs made of characters that are safe to
+// log and to echo in a header.
+func validCorrelationID(id string) bool {
+	if id == "" || len(id) > maxCorrelationIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("-_.:/+=", c):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+func TestLoggerSamplesSuccessesOnly(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	h := Logger(logger, 5)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+
+	serve := func(path string, n int) {
+		for i := 0; i < n; i++ {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+	}
+
+	serve("/ok", 20)
+	serve("/fail", 3)
+
+	var successes, failures int
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		switch {
+		case strings.Contains(line, "status=200"):
+			successes++
+		case strings.Contains(line, "status=500"):
+			failures++
+		}
+	}
+
+	if successes != 4 {
+		t.Errorf("logged %d of 20 successful requests, want 4", successes)
+	}
+	if failures != 3 {
+		t.Errorf("logged %d of 3 failed requests, want 3", failures)
+	}
+}
+
+func TestCorrelationID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		adopted  bool
+	}{
+		{"adopts incoming", "gw-1234:abc", true},
+		{"generates when absent", "", false},
+		{"rejects unsafe", "bad id\nwith newline", false},
+		{"rejects too long", strings.Repeat("a", 129), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			h := chimiddleware.RequestID(CorrelationID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = chimiddleware.GetReqID(r.Context())
+			})))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(CorrelationIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if tt.adopted && seen != tt.incoming {
+				t.Errorf("request ID = %q, want adopted %q", seen, tt.incoming)
+			}
+			if !tt.adopted && (seen == "" || seen == tt.incoming) {
+				t.Errorf("request ID = %q, want a generated ID", seen)
+			}
+			if got := rec.Header().Get(CorrelationIDHeader); got != seen {
+				t.Errorf("echoed %s = %q, want %q", CorrelationIDHeader, got, seen)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+// streamRetryAfter is the Retry-After, in seconds, sent to clients turned
+// away by MaxStreams.
+const streamRetryAfter = 10
+
+// MaxStreams caps the number of concurrent requests across every route it
+// wraps, meant for long-lived streaming responses. Requests beyond limit
+// get 503 Service Unavailable with a Retry-After header. Use one instance
+// for all streaming routes so they share the cap.
+func MaxStreams(limit int) func(http.Handler) http.Handler {
+	var active atomic.Int64
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if active.Add(1) > int64(limit) {
+				active.Add(-1)
+				w.Header().Set("Retry-After", strconv.Itoa(streamRetryAfter))
+				http.Error(w, "Too many open streams, try again later", http.StatusServiceUnavailable)
+				return
+			}
+			defer active.Add(-1)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
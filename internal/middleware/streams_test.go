@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestMaxStreams(t *testing.T) {
+	const limit = 2
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	h := MaxStreams(limit)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/joke/random/stream", nil))
+		}()
+		<-entered
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/joke/random/stream", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("overflow status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if got := rec.Header().Get("Retry-After"); got == "" {
+		t.Error("overflow response has no Retry-After header")
+	}
+
+	close(release)
+	wg.Wait()
+
+	// Closed streams free their slots.
+	go func() { <-entered }()
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/joke/random/stream", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status after streams closed = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
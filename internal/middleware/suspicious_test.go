@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+func TestSuspiciousQuery(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantStatus int
+	}{
+		{"limit=10&offset=20", http.StatusOK},
+		{"sort=random&format=ndjson", http.StatusOK},
+		{"interval=1m30s&clean=true", http.StatusOK},
+		{"limit=1;DROP", http.StatusBadRequest},
+		{"limit=1%3BDROP%20TABLE%20jokes", http.StatusBadRequest},
+		{"offset=0%27%20OR%20%271%27=%271", http.StatusBadRequest},
+		{"sort=id--", http.StatusBadRequest},
+		{"limit=1%20UNION%20SELECT%20*%20FROM%20jokes", http.StatusBadRequest},
+		{"limit=5&limit=5/*", http.StatusBadRequest},
+		// Free text parameters are not checked.
+		{"text=Don%27t+drop+the+table%3B+select+a+joke", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var logs bytes.Buffer
+			h := chimiddleware.RequestID(SuspiciousQuery(slog.New(slog.NewTextHandler(&logs, nil)), "limit", "offset", "sort", "format", "interval", "clean")(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+			))
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/joke?"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			rejected := tt.wantStatus == http.StatusBadRequest
+			if logged := strings.Contains(logs.String(), "request_id="); logged != rejected {
+				t.Errorf("logged with request ID = %v, want %v: %q", logged, rejected, logs.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type queryTimingsContextKey struct{}
+
+type queryTiming struct {
+	Method     string  `json:"method"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// queryTimings collects the repository calls made while serving a request.
+type queryTimings struct {
+	mu      sync.Mutex
+	queries []queryTiming
+	total   time.Duration
+}
+
+func (q *queryTimings) add(method string, duration time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.queries = append(q.queries, queryTiming{Method: method, DurationMS: milliseconds(duration)})
+	q.total += duration
+}
+
+func (q *queryTimings) snapshot() ([]queryTiming, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]queryTiming{}, q.queries...), q.total
+}
+
+// withQueryTimings returns r with a query timing collector in its context,
+// reusing the one an outer middleware already installed.
+func withQueryTimings(r *http.Request) (*http.Request, *queryTimings) {
+	if timings, ok := r.Context().Value(queryTimingsContextKey{}).(*queryTimings); ok {
+		return r, timings
+	}
+
+	timings := &queryTimings{}
+	return r.WithContext(context.WithValue(r.Context(), queryTimingsContextKey{}, timings)), timings
+}
+
+// RecordQueryTiming adds a repository call to the timings collected for the
+// request in ctx, if any. Its signature matches repository.Observer.
+func RecordQueryTiming(ctx context.Context, method string, duration time.Duration, err error) {
+	if timings, ok := ctx.Value(queryTimingsContextKey{}).(*queryTimings); ok {
+		timings.add(method, duration)
+	}
+}
+
+// ServerTiming reports the time spent in repository calls and the total
+// handler time in the Server-Timing response header, for example
+// "db;dur=3.2, total;dur=4.1". Durations are in milliseconds and measured
+// up to the moment the response headers are written.
+func ServerTiming(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, timings := withQueryTimings(r)
+		start := time.Now()
+
+		tw := &timingResponseWriter{ResponseWriter: w}
+		tw.setHeader = func() {
+			_, db := timings.snapshot()
+			w.Header().Set("Server-Timing", fmt.Sprintf("db;dur=%.1f, total;dur=%.1f", milliseconds(db), milliseconds(time.Since(start))))
+		}
+
+		next.ServeHTTP(tw, r)
+
+		// Handlers that never write still get their headers sent afterwards.
+		tw.writeTimingHeader()
+	})
+}
+
+type timingResponseWriter struct {
+	http.ResponseWriter
+	setHeader func()
+	written   bool
+}
+
+func (w *timingResponseWriter) writeTimingHeader() {
+	if !w.written {
+		w.written = true
+		w.setHeader()
+	}
+}
+
+func (w *timingResponseWriter) WriteHeader(status int) {
+	w.writeTimingHeader()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *timingResponseWriter) Write(b []byte) (int, error) {
+	w.writeTimingHeader()
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to
+// flush streamed responses.
+func (w *timingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,7 @@
+package model
+
+// DailyCount is the number of jokes created on one UTC day.
+type DailyCount struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+type FeatureFlag struct {
+	Name      string    `json:"name"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -1,10 +1,82 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Joke struct {
 	ID        int64     `json:"id"`
+	PublicID  string    `json:"-"`
 	Text      string    `json:"joke"`
+	Setup     string    `json:"setup"`
+	Punchline string    `json:"punchline,omitempty"`
+	Featured  bool      `json:"featured"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// LastAccessedAt is when the joke was last fetched by ID, if ever.
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+
+	// ViewCount is how often the joke was fetched by ID. Views are recorded
+	// in batches, so it lags behind a little.
+	ViewCount int64 `json:"view_count,omitempty"`
+
+	// UnixTimes renders the timestamps as Unix seconds instead of RFC 3339
+	// when the joke is marshalled to JSON.
+	UnixTimes bool `json:"-"`
+}
+
+// MarshalJSON renders the public ID as the joke's "id" when it is set, so
+// the internal integer ID is never exposed in that case. With UnixTimes the
+// timestamps are rendered as Unix seconds.
+func (j Joke) MarshalJSON() ([]byte, error) {
+	type joke Joke
+
+	if j.UnixTimes {
+		return j.marshalUnixTimes()
+	}
+
+	if j.PublicID == "" {
+		return json.Marshal(joke(j))
+	}
+
+	return json.Marshal(struct {
+		ID string `json:"id"`
+		joke
+	}{
+		ID:   j.PublicID,
+		joke: joke(j),
+	})
+}
+
+// marshalUnixTimes is MarshalJSON with the timestamps as Unix seconds. The
+// outer fields take precedence over the embedded ones of the same name.
+func (j Joke) marshalUnixTimes() ([]byte, error) {
+	type joke Joke
+
+	var id any = j.ID
+	if j.PublicID != "" {
+		id = j.PublicID
+	}
+
+	var lastAccessedAt *int64
+	if j.LastAccessedAt != nil {
+		at := j.LastAccessedAt.Unix()
+		lastAccessedAt = &at
+	}
+
+	return json.Marshal(struct {
+		ID             any    `json:"id"`
+		CreatedAt      int64  `json:"created_at"`
+		UpdatedAt      int64  `json:"updated_at"`
+		LastAccessedAt *int64 `json:"last_accessed_at,omitempty"`
+		joke
+	}{
+		ID:             id,
+		CreatedAt:      j.CreatedAt.Unix(),
+		UpdatedAt:      j.UpdatedAt.Unix(),
+		LastAccessedAt: lastAccessedAt,
+		joke:           joke(j),
+	})
 }
@@ -0,0 +1,13 @@
+package model
+
+// LengthPercentiles summarizes the distribution of joke text lengths, in
+// characters. The percentiles use the nearest-rank method, so each is the
+// length of an actual joke. All fields are zero when there are no jokes.
+type LengthPercentiles struct {
+	Count int `json:"count"`
+	Min   int `json:"min"`
+	P50   int `json:"p50"`
+	P90   int `json:"p90"`
+	P99   int `json:"p99"`
+	Max   int `json:"max"`
+}
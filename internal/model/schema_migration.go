@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+type SchemaMigration struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
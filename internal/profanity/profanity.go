@@ -0,0 +1,93 @@
+// Package profanity flags and masks words from a configurable list in joke
+// text.
+package profanity
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// word matches the runs of letters and digits text is compared by, so list
+// entries only ever match whole words.
+var word = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// Filter matches text against a word list, case-insensitively. A nil
+// Filter flags nothing.
+type Filter struct {
+	words map[string]bool
+}
+
+// New returns a filter for words. It returns nil when the list is empty.
+func New(words []string) *Filter {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			set[w] = true
+		}
+	}
+
+	if len(set) == 0 {
+		return nil
+	}
+
+	return &Filter{words: set}
+}
+
+// Load reads a word list from path, one word per line. Blank lines and
+// lines starting with # are ignored.
+func Load(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening word list: %w", err)
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading word list: %w", err)
+	}
+
+	return words, nil
+}
+
+// Contains reports whether text contains a listed word.
+func (f *Filter) Contains(text string) bool {
+	if f == nil {
+		return false
+	}
+
+	for _, w := range word.FindAllString(text, -1) {
+		if f.words[strings.ToLower(w)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Mask replaces every character of each listed word in text with an
+// asterisk.
+func (f *Filter) Mask(text string) string {
+	if f == nil {
+		return text
+	}
+
+	return word.ReplaceAllStringFunc(text, func(w string) string {
+		if !f.words[strings.ToLower(w)] {
+			return w
+		}
+		return strings.Repeat("*", len([]rune(w)))
+	})
+}
@@ -0,0 +1,61 @@
+package profanity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	f := New([]string{"darn", " Heck "})
+
+	tests := []struct {
+		text     string
+		contains bool
+		masked   string
+	}{
+		{"What the heck is that?", true, "What the **** is that?"},
+		{"DARN, heck!", true, "****, ****!"},
+		{"Darned if I know", false, "Darned if I know"},
+		{"A clean joke", false, "A clean joke"},
+	}
+
+	for _, tt := range tests {
+		if got := f.Contains(tt.text); got != tt.contains {
+			t.Errorf("Contains(%q) = %v, want %v", tt.text, got, tt.contains)
+		}
+		if got := f.Mask(tt.text); got != tt.masked {
+			t.Errorf("Mask(%q) = %q, want %q", tt.text, got, tt.masked)
+		}
+	}
+}
+
+func TestNilFilter(t *testing.T) {
+	f := New([]string{" ", ""})
+	if f != nil {
+		t.Fatalf("New with an empty list = %v, want nil", f)
+	}
+
+	if f.Contains("heck") {
+		t.Error("nil filter flagged text")
+	}
+	if got := f.Mask("heck"); got != "heck" {
+		t.Errorf("nil filter Mask = %q, want text unchanged", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("# family friendly\ndarn\n\n  heck  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	words, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if len(words) != 2 || words[0] != "darn" || words[1] != "heck" {
+		t.Errorf("Load = %q, want [darn heck]", words)
+	}
+}
@@ -0,0 +1,651 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// ErrCircuitOpen is returned instead of calling the database while the
+// circuit breaker is open.
+var ErrCircuitOpen = errors.New("database circuit breaker is open")
+
+// CircuitBreaker stops calls to an unhealthy database. It opens after a
+// number of consecutive failures and rejects calls for a cooldown. After
+// the cooldown a single probe call is let through: if it succeeds the
+// breaker closes again, otherwise a new cooldown starts.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	logger    *slog.Logger
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	open      bool
+	probing   bool
+}
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration, logger *slog.Logger) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Open reports whether calls are currently being rejected and, if so, how
+// long until it is worth trying again.
+func (b *CircuitBreaker) Open() (retryAfter time.Duration, open bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return 0, false
+	}
+
+	if b.probing {
+		return time.Second, true
+	}
+
+	if wait := b.openUntil.Sub(b.now()); wait > 0 {
+		return wait, true
+	}
+
+	// The cooldown is over; the next call becomes the probe.
+	return 0, false
+}
+
+// allow reports whether a call may go ahead, and whether it is the probe
+// deciding if the breaker closes again.
+func (b *CircuitBreaker) allow() (probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return false, nil
+	}
+
+	if b.probing || b.now().Before(b.openUntil) {
+		return false, ErrCircuitOpen
+	}
+
+	b.probing = true
+	return true, nil
+}
+
+// record counts the outcome of a call that allow let through.
+func (b *CircuitBreaker) record(probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	failed := isFailure(err)
+
+	if probe {
+		b.probing = false
+		if failed {
+			b.openUntil = b.now().Add(b.cooldown)
+			b.logger.Warn("Database probe failed, circuit breaker stays open", "cooldown", b.cooldown, "error", err.Error())
+			return
+		}
+
+		b.open = false
+		b.failures = 0
+		b.logger.Info("Database probe succeeded, circuit breaker closed")
+		return
+	}
+
+	// Calls that started before the breaker opened say nothing about
+	// whether the database has recovered.
+	if b.open {
+		return
+	}
+
+	if !failed {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.open = true
+		b.openUntil = b.now().Add(b.cooldown)
+		b.logger.Warn("Circuit breaker opened after consecutive database failures", "failures", b.failures, "cooldown", b.cooldown, "error", err.Error())
+	}
+}
+
+// isFailure reports whether err says something about the health of the
+// database. Lookups of missing rows, constraint violations and calls
+// abandoned by the client are not failures.
+func isFailure(err error) bool {
+	var constraintErr *ConstraintError
+
+	switch {
+	case err == nil:
+		return false
+	case errors.As(err, &constraintErr):
+		return false
+	case errors.Is(err, ErrJokeNotFound), errors.Is(err, ErrNoJokes), errors.Is(err, ErrCollectionNotFound), errors.Is(err, ErrJokeLimitReached), errors.Is(err, ErrInvalidCounterField):
+		return false
+	case errors.Is(err, context.Canceled):
+		return false
+	default:
+		return true
+	}
+}
+
+// CircuitBreakerJokeRepository is a JokeRepository decorator that routes
+// every call through a CircuitBreaker, failing fast with ErrCircuitOpen
+// while it is open.
+type CircuitBreakerJokeRepository struct {
+	next    JokeRepository
+	breaker *CircuitBreaker
+}
+
+func NewCircuitBreakerJokeRepository(next JokeRepository, breaker *CircuitBreaker) *CircuitBreakerJokeRepository {
+	return &CircuitBreakerJokeRepository{
+		next:    next,
+		breaker: breaker,
+	}
+}
+
+func (r *CircuitBreakerJokeRepository) GetJoke(ctx context.Context, id int64) (*model.Joke, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	joke, err := r.next.GetJoke(ctx, id)
+	r.breaker.record(probe, err)
+	return joke, err
+}
+
+func (r *CircuitBreakerJokeRepository) GetRandomJoke(ctx context.Context) (*model.Joke, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	joke, err := r.next.GetRandomJoke(ctx)
+	r.breaker.record(probe, err)
+	return joke, err
+}
+
+func (r *CircuitBreakerJokeRepository) GetRandomJokeMaxLength(ctx context.Context, maxLength int) (*model.Joke, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	joke, err := r.next.GetRandomJokeMaxLength(ctx, maxLength)
+	r.breaker.record(probe, err)
+	return joke, err
+}
+
+func (r *CircuitBreakerJokeRepository) GetRandomJokes(ctx context.Context, n int) ([]*model.Joke, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	jokes, err := r.next.GetRandomJokes(ctx, n)
+	r.breaker.record(probe, err)
+	return jokes, err
+}
+
+func (r *CircuitBreakerJokeRepository) ListJokes(ctx context.Context, limit, offset int) ([]*model.Joke, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	jokes, err := r.next.ListJokes(ctx, limit, offset)
+	r.breaker.record(probe, err)
+	return jokes, err
+}
+
+func (r *CircuitBreakerJokeRepository) ListShuffledJokes(ctx context.Context, seed string, limit, offset int) ([]*model.Joke, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	jokes, err := r.next.ListShuffledJokes(ctx, seed, limit, offset)
+	r.breaker.record(probe, err)
+	return jokes, err
+}
+
+func (r *CircuitBreakerJokeRepository) GetRandomSample(ctx context.Context, n int, seed int64) ([]*model.Joke, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	jokes, err := r.next.GetRandomSample(ctx, n, seed)
+	r.breaker.record(probe, err)
+	return jokes, err
+}
+
+func (r *CircuitBreakerJokeRepository) StreamJokes(ctx context.Context, limit, offset int, fn func(*model.Joke) error) error {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return err
+	}
+
+	// Errors from fn, such as a client going away mid-stream, say nothing
+	// about the database.
+	var fnErr error
+	err = r.next.StreamJokes(ctx, limit, offset, func(joke *model.Joke) error {
+		fnErr = fn(joke)
+		return fnErr
+	})
+	if fnErr != nil {
+		r.breaker.record(probe, nil)
+	} else {
+		r.breaker.record(probe, err)
+	}
+	return err
+}
+
+func (r *CircuitBreakerJokeRepository) EachJoke(ctx context.Context, fn func(*model.Joke) error) error {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return err
+	}
+
+	var fnErr error
+	err = r.next.EachJoke(ctx, func(joke *model.Joke) error {
+		fnErr = fn(joke)
+		return fnErr
+	})
+	if fnErr != nil {
+		r.breaker.record(probe, nil)
+	} else {
+		r.breaker.record(probe, err)
+	}
+	return err
+}
+
+func (r *CircuitBreakerJokeRepository) CreateJoke(ctx context.Context, joke *model.Joke) (int64, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := r.next.CreateJoke(ctx, joke)
+	r.breaker.record(probe, err)
+	return id, err
+}
+
+func (r *CircuitBreakerJokeRepository) CreateJokeWithID(ctx context.Context, id int64, joke *model.Joke) error {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return err
+	}
+
+	err = r.next.CreateJokeWithID(ctx, id, joke)
+	r.breaker.record(probe, err)
+	return err
+}
+
+func (r *CircuitBreakerJokeRepository) UpdateJoke(ctx context.Context, joke *model.Joke) error {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return err
+	}
+
+	err = r.next.UpdateJoke(ctx, joke)
+	r.breaker.record(probe, err)
+	return err
+}
+
+func (r *CircuitBreakerJokeRepository) DeleteJoke(ctx context.Context, id int64) error {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return err
+	}
+
+	err = r.next.DeleteJoke(ctx, id)
+	r.breaker.record(probe, err)
+	return err
+}
+
+func (r *CircuitBreakerJokeRepository) ReplaceJokes(ctx context.Context, jokes []*model.Joke) error {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return err
+	}
+
+	err = r.next.ReplaceJokes(ctx, jokes)
+	r.breaker.record(probe, err)
+	return err
+}
+
+func (r *CircuitBreakerJokeRepository) CountJokes(ctx context.Context) (int, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := r.next.CountJokes(ctx)
+	r.breaker.record(probe, err)
+	return count, err
+}
+
+func (r *CircuitBreakerJokeRepository) ListJokeIDs(ctx context.Context) ([]int64, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := r.next.ListJokeIDs(ctx)
+	r.breaker.record(probe, err)
+	return ids, err
+}
+
+func (r *CircuitBreakerJokeRepository) ResolvePublicID(ctx context.Context, publicID string) (int64, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := r.next.ResolvePublicID(ctx, publicID)
+	r.breaker.record(probe, err)
+	return id, err
+}
+
+func (r *CircuitBreakerJokeRepository) JokeTextExists(ctx context.Context, text string) (bool, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return false, err
+	}
+
+	exists, err := r.next.JokeTextExists(ctx, text)
+	r.breaker.record(probe, err)
+	return exists, err
+}
+
+func (r *CircuitBreakerJokeRepository) FindJokesByText(ctx context.Context, text string) ([]*model.Joke, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	jokes, err := r.next.FindJokesByText(ctx, text)
+	r.breaker.record(probe, err)
+	return jokes, err
+}
+
+func (r *CircuitBreakerJokeRepository) GetOrCreateJokes(ctx context.Context, jokes []*model.Joke) ([]UpsertResult, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := r.next.GetOrCreateJokes(ctx, jokes)
+	r.breaker.record(probe, err)
+	return results, err
+}
+
+func (r *CircuitBreakerJokeRepository) SetJokeFeatured(ctx context.Context, id int64, featured bool) error {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return err
+	}
+
+	err = r.next.SetJokeFeatured(ctx, id, featured)
+	r.breaker.record(probe, err)
+	return err
+}
+
+func (r *CircuitBreakerJokeRepository) ListFeaturedJokes(ctx context.Context, limit, offset int) ([]*model.Joke, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	jokes, err := r.next.ListFeaturedJokes(ctx, limit, offset)
+	r.breaker.record(probe, err)
+	return jokes, err
+}
+
+func (r *CircuitBreakerJokeRepository) ListRecentJokes(ctx context.Context, n int) ([]*model.Joke, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	jokes, err := r.next.ListRecentJokes(ctx, n)
+	r.breaker.record(probe, err)
+	return jokes, err
+}
+
+func (r *CircuitBreakerJokeRepository) ListJokesFiltered(ctx context.Context, filter JokeFilter, limit, offset int) ([]*model.Joke, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	jokes, err := r.next.ListJokesFiltered(ctx, filter, limit, offset)
+	r.breaker.record(probe, err)
+	return jokes, err
+}
+
+func (r *CircuitBreakerJokeRepository) CountJokesFiltered(ctx context.Context, filter JokeFilter) (int, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := r.next.CountJokesFiltered(ctx, filter)
+	r.breaker.record(probe, err)
+	return count, err
+}
+
+func (r *CircuitBreakerJokeRepository) CountFeaturedJokes(ctx context.Context) (int, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := r.next.CountFeaturedJokes(ctx)
+	r.breaker.record(probe, err)
+	return count, err
+}
+
+func (r *CircuitBreakerJokeRepository) TouchJokes(ctx context.Context, accessed map[int64]time.Time) error {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return err
+	}
+
+	err = r.next.TouchJokes(ctx, accessed)
+	r.breaker.record(probe, err)
+	return err
+}
+
+func (r *CircuitBreakerJokeRepository) ListStaleJokes(ctx context.Context, before time.Time, limit, offset int) ([]*model.Joke, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	jokes, err := r.next.ListStaleJokes(ctx, before, limit, offset)
+	r.breaker.record(probe, err)
+	return jokes, err
+}
+
+func (r *CircuitBreakerJokeRepository) CountStaleJokes(ctx context.Context, before time.Time) (int, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := r.next.CountStaleJokes(ctx, before)
+	r.breaker.record(probe, err)
+	return count, err
+}
+
+func (r *CircuitBreakerJokeRepository) CountJokesPerDay(ctx context.Context, since time.Time) ([]model.DailyCount, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := r.next.CountJokesPerDay(ctx, since)
+	r.breaker.record(probe, err)
+	return counts, err
+}
+
+func (r *CircuitBreakerJokeRepository) ListJokesByCreation(ctx context.Context, limit, offset int) ([]*model.Joke, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	jokes, err := r.next.ListJokesByCreation(ctx, limit, offset)
+	r.breaker.record(probe, err)
+	return jokes, err
+}
+
+func (r *CircuitBreakerJokeRepository) JokeLengthPercentiles(ctx context.Context) (*model.LengthPercentiles, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := r.next.JokeLengthPercentiles(ctx)
+	r.breaker.record(probe, err)
+	return stats, err
+}
+
+func (r *CircuitBreakerJokeRepository) AddJokeViews(ctx context.Context, views map[int64]int64) error {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return err
+	}
+
+	err = r.next.AddJokeViews(ctx, views)
+	r.breaker.record(probe, err)
+	return err
+}
+
+func (r *CircuitBreakerJokeRepository) IncrementAndGet(ctx context.Context, id int64, field string, delta int) (*model.Joke, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	joke, err := r.next.IncrementAndGet(ctx, id, field, delta)
+	r.breaker.record(probe, err)
+	return joke, err
+}
+
+func (r *CircuitBreakerJokeRepository) ResetJokeCounters(ctx context.Context, id int64) (*model.Joke, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	joke, err := r.next.ResetJokeCounters(ctx, id)
+	r.breaker.record(probe, err)
+	return joke, err
+}
+
+func (r *CircuitBreakerJokeRepository) ListMostViewedJokes(ctx context.Context, limit, offset int) ([]*model.Joke, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	jokes, err := r.next.ListMostViewedJokes(ctx, limit, offset)
+	r.breaker.record(probe, err)
+	return jokes, err
+}
+
+func (r *CircuitBreakerJokeRepository) JokesChecksum(ctx context.Context) (string, int, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return "", 0, err
+	}
+
+	checksum, count, err := r.next.JokesChecksum(ctx)
+	r.breaker.record(probe, err)
+	return checksum, count, err
+}
+
+func (r *CircuitBreakerJokeRepository) CreateCollection(ctx context.Context, collection *model.Collection) (int64, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := r.next.CreateCollection(ctx, collection)
+	r.breaker.record(probe, err)
+	return id, err
+}
+
+func (r *CircuitBreakerJokeRepository) GetCollection(ctx context.Context, id int64) (*model.Collection, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := r.next.GetCollection(ctx, id)
+	r.breaker.record(probe, err)
+	return collection, err
+}
+
+func (r *CircuitBreakerJokeRepository) AddJokeToCollection(ctx context.Context, collectionID, jokeID int64) error {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return err
+	}
+
+	err = r.next.AddJokeToCollection(ctx, collectionID, jokeID)
+	r.breaker.record(probe, err)
+	return err
+}
+
+func (r *CircuitBreakerJokeRepository) RemoveJokeFromCollection(ctx context.Context, collectionID, jokeID int64) error {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return err
+	}
+
+	err = r.next.RemoveJokeFromCollection(ctx, collectionID, jokeID)
+	r.breaker.record(probe, err)
+	return err
+}
+
+func (r *CircuitBreakerJokeRepository) ListCollectionJokes(ctx context.Context, collectionID int64, limit, offset int) ([]*model.Joke, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	jokes, err := r.next.ListCollectionJokes(ctx, collectionID, limit, offset)
+	r.breaker.record(probe, err)
+	return jokes, err
+}
+
+func (r *CircuitBreakerJokeRepository) CountCollectionJokes(ctx context.Context, collectionID int64) (int, error) {
+	probe, err := r.breaker.allow()
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := r.next.CountCollectionJokes(ctx, collectionID)
+	r.breaker.record(probe, err)
+	return count, err
+}
+
+func (r *CircuitBreakerJokeRepository) Close() error {
+	return r.next.Close()
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// flakyRepository fails GetRandomJoke with err, or succeeds when err is nil.
+type flakyRepository struct {
+	JokeRepository
+	err   error
+	calls int
+}
+
+func (r *flakyRepository) GetRandomJoke(ctx context.Context) (*model.Joke, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &model.Joke{ID: 1, Text: "recovered"}, nil
+}
+
+func (r *flakyRepository) GetCollection(ctx context.Context, id int64) (*model.Collection, error) {
+	r.calls++
+	return nil, r.err
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	breaker := NewCircuitBreaker(3, 30*time.Second, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	breaker.now = func() time.Time { return now }
+
+	flaky := &flakyRepository{err: errors.New("disk I/O error")}
+	repo := NewCircuitBreakerJokeRepository(flaky, breaker)
+
+	for i := 0; i < 3; i++ {
+		if _, err := repo.GetRandomJoke(ctx); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d rejected before reaching the threshold", i+1)
+		}
+	}
+
+	if _, open := breaker.Open(); !open {
+		t.Fatal("breaker closed after 3 consecutive failures")
+	}
+
+	if _, err := repo.GetRandomJoke(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("open breaker returned %v, want %v", err, ErrCircuitOpen)
+	}
+	if flaky.calls != 3 {
+		t.Fatalf("open breaker reached the repository: %d calls, want 3", flaky.calls)
+	}
+
+	// A failing probe after the cooldown starts another one.
+	now = now.Add(31 * time.Second)
+	if _, err := repo.GetRandomJoke(ctx); errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("probe rejected after the cooldown")
+	}
+	if retryAfter, open := breaker.Open(); !open || retryAfter != 30*time.Second {
+		t.Fatalf("after failed probe Open() = %v, %v, want 30s, true", retryAfter, open)
+	}
+
+	// A successful probe closes the breaker.
+	now = now.Add(31 * time.Second)
+	flaky.err = nil
+	if _, err := repo.GetRandomJoke(ctx); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if _, open := breaker.Open(); open {
+		t.Fatal("breaker still open after a successful probe")
+	}
+	if _, err := repo.GetRandomJoke(ctx); err != nil {
+		t.Fatalf("call after recovery: %v", err)
+	}
+}
+
+func TestCircuitBreakerIgnoresNotFound(t *testing.T) {
+	breaker := NewCircuitBreaker(2, time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	repo := NewCircuitBreakerJokeRepository(&flakyRepository{err: ErrNoJokes}, breaker)
+
+	for i := 0; i < 5; i++ {
+		if _, err := repo.GetRandomJoke(context.Background()); !errors.Is(err, ErrNoJokes) {
+			t.Fatalf("call %d returned %v, want %v", i+1, err, ErrNoJokes)
+		}
+	}
+
+	if _, open := breaker.Open(); open {
+		t.Error("breaker opened on not found errors")
+	}
+}
+
+func TestCircuitBreakerCoversCollections(t *testing.T) {
+	breaker := NewCircuitBreaker(2, time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	flaky := &flakyRepository{err: errors.New("disk I/O error")}
+	repo := NewCircuitBreakerJokeRepository(flaky, breaker)
+
+	for i := 0; i < 2; i++ {
+		repo.GetCollection(context.Background(), 1)
+	}
+
+	if _, err := repo.GetCollection(context.Background(), 1); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("open breaker returned %v, want %v", err, ErrCircuitOpen)
+	}
+	if flaky.calls != 2 {
+		t.Errorf("open breaker reached the repository: %d calls, want 2", flaky.calls)
+	}
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// JokesChecksum returns a SHA-256 hex digest over the ID and last update
+// time of every joke, along with the number of jokes. Rows are hashed in ID
+// order, so the digest only depends on the data, and any create, update or
+// delete changes it.
+func (r *SQLiteJokeRepository) JokesChecksum(ctx context.Context) (string, int, error) {
+	query := `
+		SELECT id, updated_at
+		FROM jokes
+		ORDER BY id
+	`
+
+	rows, err := r.readDB.QueryContext(ctx, query)
+	if err != nil {
+		return "", 0, fmt.Errorf("error computing jokes checksum: %w", err)
+	}
+	defer rows.Close()
+
+	hash := sha256.New()
+	count := 0
+	for rows.Next() {
+		var (
+			id        int64
+			updatedAt time.Time
+		)
+		if err := rows.Scan(&id, &updatedAt); err != nil {
+			return "", 0, fmt.Errorf("error scanning joke for checksum: %w", err)
+		}
+
+		fmt.Fprintf(hash, "%d:%s\n", id, updatedAt.UTC().Format(time.RFC3339Nano))
+		count++
+	}
+
+	if err := rows.Err(); err != nil {
+		return "", 0, fmt.Errorf("error computing jokes checksum: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), count, nil
+}
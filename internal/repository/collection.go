@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+var ErrCollectionNotFound = errors.New("collection not found")
+
+type CollectionRepository interface {
+	CreateCollection(ctx context.Context, collection *model.Collection) (int64, error)
+	GetCollection(ctx context.Context, id int64) (*model.Collection, error)
+	AddJokeToCollection(ctx context.Context, collectionID, jokeID int64) error
+	RemoveJokeFromCollection(ctx context.Context, collectionID, jokeID int64) error
+	ListCollectionJokes(ctx context.Context, collectionID int64, limit, offset int) ([]*model.Joke, error)
+	CountCollectionJokes(ctx context.Context, collectionID int64) (int, error)
+}
+
+func (r *SQLiteJokeRepository) CreateCollection(ctx context.Context, collection *model.Collection) (int64, error) {
+	query := `
+		INSERT INTO collections (name, description, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	now := time.Now().UTC()
+	result, err := r.db.ExecContext(ctx, query, collection.Name, collection.Description, now, now)
+	if err != nil {
+		return 0, fmt.Errorf("error creating collection: %w", constraintError(err))
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error getting last insert ID: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *SQLiteJokeRepository) GetCollection(ctx context.Context, id int64) (*model.Collection, error) {
+	query := `
+		SELECT id, name, description, created_at, updated_at
+		FROM collections
+		WHERE id = ?
+	`
+
+	collection := &model.Collection{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&collection.ID,
+		&collection.Name,
+		&collection.Description,
+		&collection.CreatedAt,
+		&collection.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, fmt.Errorf("error getting collection: %w", err)
+	}
+
+	return collection, nil
+}
+
+// AddJokeToCollection appends a joke to the end of a collection. Adding a
+// joke that is already part of the collection keeps its current position.
+func (r *SQLiteJokeRepository) AddJokeToCollection(ctx context.Context, collectionID, jokeID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var collectionExists, jokeExists bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT
+			EXISTS (SELECT 1 FROM collections WHERE id = ?),
+			EXISTS (SELECT 1 FROM jokes WHERE id = ?)
+	`, collectionID, jokeID).Scan(&collectionExists, &jokeExists)
+	if err != nil {
+		return fmt.Errorf("error checking collection and joke: %w", err)
+	}
+
+	if !collectionExists {
+		return ErrCollectionNotFound
+	}
+	if !jokeExists {
+		return ErrJokeNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT OR IGNORE INTO collection_jokes (collection_id, joke_id, position, added_at)
+		SELECT ?, ?, COALESCE(MAX(position), 0) + 1, ?
+		FROM collection_jokes
+		WHERE collection_id = ?
+	`, collectionID, jokeID, time.Now().UTC(), collectionID)
+	if err != nil {
+		return fmt.Errorf("error adding joke to collection: %w", constraintError(err))
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE collections SET updated_at = ? WHERE id = ?`, time.Now().UTC(), collectionID)
+	if err != nil {
+		return fmt.Errorf("error updating collection: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLiteJokeRepository) RemoveJokeFromCollection(ctx context.Context, collectionID, jokeID int64) error {
+	query := `
+		DELETE FROM collection_jokes
+		WHERE collection_id = ? AND joke_id = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query, collectionID, jokeID)
+	if err != nil {
+		return fmt.Errorf("error removing joke from collection: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrJokeNotFound
+	}
+
+	return nil
+}
+
+func (r *SQLiteJokeRepository) ListCollectionJokes(ctx context.Context, collectionID int64, limit, offset int) ([]*model.Joke, error) {
+	query := `
+		SELECT ` + jokeColumns + `
+		FROM jokes
+		JOIN collection_jokes cj ON cj.joke_id = jokes.id
+		WHERE cj.collection_id = ?
+		ORDER BY cj.position
+		LIMIT ? OFFSET ?
+	`
+
+	return r.queryJokes(ctx, query, collectionID, limit, offset)
+}
+
+func (r *SQLiteJokeRepository) CountCollectionJokes(ctx context.Context, collectionID int64) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM collection_jokes
+		JOIN jokes ON jokes.id = collection_jokes.joke_id
+		WHERE collection_jokes.collection_id = ?
+	`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, collectionID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting collection jokes: %w", err)
+	}
+
+	return count, nil
+}
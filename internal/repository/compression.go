@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// compressMinLength is the shortest text that is stored compressed. Below
+// it the gzip framing outweighs the savings.
+const compressMinLength = 256
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// compressText gzips s. Compression is deterministic, so equal texts always
+// produce equal bytes and can still be compared in SQL.
+func compressText(s string) []byte {
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(s))
+	zw.Close()
+
+	return buf.Bytes()
+}
+
+// encodeText returns the value to store for a text column: a gzip blob when
+// compression is enabled and the text is long enough, the plain text
+// otherwise.
+func (r *SQLiteJokeRepository) encodeText(s string) any {
+	if !r.opts.CompressText || len(s) < compressMinLength {
+		return s
+	}
+
+	return compressText(s)
+}
+
+// decodeText reverses encodeText. Rows written without compression are
+// returned as is, so compressed and plain rows can live side by side.
+func decodeText(b []byte) (string, error) {
+	if !bytes.HasPrefix(b, gzipMagic) {
+		return string(b), nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return "", fmt.Errorf("error decompressing text: %w", err)
+	}
+	defer zr.Close()
+
+	text, err := io.ReadAll(zr)
+	if err != nil {
+		return "", fmt.Errorf("error decompressing text: %w", err)
+	}
+
+	return string(text), nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func TestCompressedTextRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{CompressText: true})
+
+	text := strings.Repeat("A very long joke that goes on and on. ", 100)
+
+	id, err := repo.CreateJoke(ctx, &model.Joke{Text: text})
+	if err != nil {
+		t.Fatalf("creating joke: %v", err)
+	}
+
+	var stored []byte
+	if err := repo.db.QueryRow(`SELECT text FROM jokes WHERE id = ?`, id).Scan(&stored); err != nil {
+		t.Fatalf("reading stored text: %v", err)
+	}
+	if !bytes.HasPrefix(stored, gzipMagic) || len(stored) >= len(text) {
+		t.Errorf("stored text is not compressed (%d bytes for %d bytes of text)", len(stored), len(text))
+	}
+
+	joke, err := repo.GetJoke(ctx, id)
+	if err != nil {
+		t.Fatalf("getting joke: %v", err)
+	}
+	if joke.Text != text || joke.Setup != text {
+		t.Error("text read back differs from the text written")
+	}
+
+	exists, err := repo.JokeTextExists(ctx, text)
+	if err != nil {
+		t.Fatalf("checking text: %v", err)
+	}
+	if !exists {
+		t.Error("JokeTextExists = false for a compressed joke")
+	}
+}
+
+func TestCompressedTextReadsLegacyRows(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{CompressText: true})
+
+	text := strings.Repeat("A legacy joke stored before compression. ", 20)
+
+	result, err := repo.db.Exec(`INSERT INTO jokes (text, setup) VALUES (?, ?)`, text, text)
+	if err != nil {
+		t.Fatalf("inserting legacy row: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		t.Fatalf("getting legacy row id: %v", err)
+	}
+
+	joke, err := repo.GetJoke(ctx, id)
+	if err != nil {
+		t.Fatalf("getting joke: %v", err)
+	}
+	if joke.Text != text {
+		t.Errorf("text = %q, want %q", joke.Text, text)
+	}
+
+	exists, err := repo.JokeTextExists(ctx, text)
+	if err != nil {
+		t.Fatalf("checking text: %v", err)
+	}
+	if !exists {
+		t.Error("JokeTextExists = false for a legacy joke")
+	}
+}
+
+func TestGetRandomJokeMaxLengthCountsDecodedCharacters(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{CompressText: true})
+
+	// Compressed, the long text takes far fewer bytes than its length.
+	long := strings.Repeat("ä", 400)
+	if _, err := repo.CreateJoke(ctx, &model.Joke{Text: long}); err != nil {
+		t.Fatalf("creating joke: %v", err)
+	}
+
+	if _, err := repo.GetRandomJokeMaxLength(ctx, 399); !errors.Is(err, ErrNoJokes) {
+		t.Fatalf("GetRandomJokeMaxLength(399) error = %v, want %v", err, ErrNoJokes)
+	}
+
+	joke, err := repo.GetRandomJokeMaxLength(ctx, 400)
+	if err != nil {
+		t.Fatalf("GetRandomJokeMaxLength(400): %v", err)
+	}
+	if joke.Text != long {
+		t.Errorf("text = %q, want the long joke", joke.Text)
+	}
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// ConstraintKind identifies the kind of database constraint a write
+// violated.
+type ConstraintKind int
+
+const (
+	ConstraintOther ConstraintKind = iota
+	ConstraintUnique
+	ConstraintForeignKey
+	ConstraintNotNull
+	ConstraintCheck
+)
+
+// ConstraintError reports a write the database rejected because it
+// violated a constraint. Callers can tell it apart from other failures with
+// errors.As, without depending on the SQLite driver.
+type ConstraintError struct {
+	Kind ConstraintKind
+	Err  error
+}
+
+func (e *ConstraintError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// constraintError wraps SQLite constraint violations in a ConstraintError
+// and returns all other errors unchanged.
+func constraintError(err error) error {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) || sqliteErr.Code != sqlite3.ErrConstraint {
+		return err
+	}
+
+	kind := ConstraintOther
+	switch sqliteErr.ExtendedCode {
+	case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+		kind = ConstraintUnique
+	case sqlite3.ErrConstraintForeignKey:
+		kind = ConstraintForeignKey
+	case sqlite3.ErrConstraintNotNull:
+		kind = ConstraintNotNull
+	case sqlite3.ErrConstraintCheck:
+		kind = ConstraintCheck
+	}
+
+	return &ConstraintError{Kind: kind, Err: err}
+}
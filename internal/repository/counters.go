@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// ErrInvalidCounterField is returned by IncrementAndGet for fields that are
+// not counters.
+var ErrInvalidCounterField = errors.New("invalid counter field")
+
+// counterColumns maps the counter fields IncrementAndGet accepts to their
+// columns. Only names from here are ever put into the query.
+var counterColumns = map[string]string{
+	"view_count": "view_count",
+}
+
+// IncrementAndGet adds delta to one of the joke's counter fields and
+// returns the joke as updated, in a single statement, so concurrent
+// increments are neither lost nor observed half-way.
+func (r *SQLiteJokeRepository) IncrementAndGet(ctx context.Context, id int64, field string, delta int) (*model.Joke, error) {
+	column, ok := counterColumns[field]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidCounterField, field)
+	}
+
+	query := `
+		UPDATE jokes
+		SET ` + column + ` = ` + column + ` + ?
+		WHERE id = ?
+		RETURNING ` + jokeColumns
+
+	joke, err := r.scanJoke(r.db.QueryRowContext(ctx, query, delta, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrJokeNotFound
+		}
+		return nil, fmt.Errorf("error incrementing %s: %w", field, err)
+	}
+
+	return joke, nil
+}
+
+// ResetJokeCounters sets all of the joke's counter fields to zero and
+// returns the joke as updated. Views still buffered in memory are added
+// on top when they are flushed.
+func (r *SQLiteJokeRepository) ResetJokeCounters(ctx context.Context, id int64) (*model.Joke, error) {
+	columns := slices.Sorted(maps.Values(counterColumns))
+	for i, column := range columns {
+		columns[i] = column + " = 0"
+	}
+
+	query := `
+		UPDATE jokes
+		SET ` + strings.Join(columns, ", ") + `
+		WHERE id = ?
+		RETURNING ` + jokeColumns
+
+	joke, err := r.scanJoke(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrJokeNotFound
+		}
+		return nil, fmt.Errorf("error resetting joke counters: %w", err)
+	}
+
+	return joke, nil
+}
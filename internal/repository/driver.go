@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"database/sql"
+	"regexp"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// driverName is the SQLite driver the repository opens its databases with.
+// It is the stock driver plus the functions registered in registerFunctions.
+const driverName = "sqlite3_jokes"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{ConnectHook: registerFunctions})
+}
+
+// registerFunctions adds the SQL functions the repository's queries use to
+// a new connection.
+func registerFunctions(conn *sqlite3.SQLiteConn) error {
+	// SQLite parses "x REGEXP y" but leaves regexp() to the application.
+	// A connection runs one statement at a time, so the last compiled
+	// pattern is cached without a lock; a query passes the same pattern
+	// for every row.
+	var (
+		lastPattern string
+		lastRegexp  *regexp.Regexp
+	)
+	match := func(pattern string, text []byte) (bool, error) {
+		if lastRegexp == nil || pattern != lastPattern {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return false, err
+			}
+			lastPattern, lastRegexp = pattern, re
+		}
+
+		// Text may be stored compressed.
+		decoded, err := decodeText(text)
+		if err != nil {
+			return false, err
+		}
+		return lastRegexp.MatchString(decoded), nil
+	}
+
+	return conn.RegisterFunc("regexp", match, true)
+}
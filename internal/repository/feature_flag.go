@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+type FeatureFlagRepository interface {
+	ListFeatureFlags(ctx context.Context) ([]*model.FeatureFlag, error)
+	SetFeatureFlag(ctx context.Context, name string, enabled bool) error
+}
+
+func (r *SQLiteJokeRepository) ListFeatureFlags(ctx context.Context) ([]*model.FeatureFlag, error) {
+	query := `
+		SELECT name, enabled, updated_at
+		FROM feature_flags
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error listing feature flags: %w", err)
+	}
+	defer rows.Close()
+
+	flags := make([]*model.FeatureFlag, 0)
+	for rows.Next() {
+		flag := &model.FeatureFlag{}
+		if err := rows.Scan(&flag.Name, &flag.Enabled, &flag.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning feature flag: %w", err)
+		}
+		flags = append(flags, flag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing feature flags: %w", err)
+	}
+
+	return flags, nil
+}
+
+func (r *SQLiteJokeRepository) SetFeatureFlag(ctx context.Context, name string, enabled bool) error {
+	query := `
+		INSERT INTO feature_flags (name, enabled, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET enabled = excluded.enabled, updated_at = excluded.updated_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, name, enabled, time.Now().UTC()); err != nil {
+		return fmt.Errorf("error setting feature flag: %w", constraintError(err))
+	}
+
+	return nil
+}
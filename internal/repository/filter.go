@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// JokeFilter narrows a joke listing. The zero value matches every joke.
+type JokeFilter struct {
+	// CreatedFrom and CreatedTo keep the jokes created at or after
+	// CreatedFrom and before CreatedTo. A zero time leaves that side open.
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+
+	// Regex, when set, keeps the jokes whose text it matches. The match
+	// runs in SQLite, after the cheaper conditions narrowed the rows down.
+	Regex *regexp.Regexp
+}
+
+// where returns the WHERE clause selecting the jokes the filter matches,
+// and its arguments. It is empty for the zero filter.
+func (f JokeFilter) where() (string, []any) {
+	var (
+		conditions []string
+		args       []any
+	)
+
+	if !f.CreatedFrom.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, f.CreatedFrom.UTC())
+	}
+	if !f.CreatedTo.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, f.CreatedTo.UTC())
+	}
+
+	if f.Regex != nil {
+		conditions = append(conditions, "text REGEXP ?")
+		args = append(args, f.Regex.String())
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// ListJokesFiltered lists the jokes matching filter, ordered by ID.
+func (r *SQLiteJokeRepository) ListJokesFiltered(ctx context.Context, filter JokeFilter, limit, offset int) ([]*model.Joke, error) {
+	where, args := filter.where()
+	query := `
+		SELECT ` + jokeColumns + `
+		FROM jokes
+		` + where + `
+		ORDER BY id
+		LIMIT ? OFFSET ?
+	`
+
+	return r.queryJokes(ctx, query, append(args, limit, offset)...)
+}
+
+// CountJokesFiltered counts the jokes matching filter.
+func (r *SQLiteJokeRepository) CountJokesFiltered(ctx context.Context, filter JokeFilter) (int, error) {
+	where, args := filter.where()
+	query := `
+		SELECT COUNT(*)
+		FROM jokes
+		` + where
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting jokes: %w", err)
+	}
+
+	return count, nil
+}
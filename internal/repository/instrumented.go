@@ -0,0 +1,349 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// Observer receives the outcome of every call made through an
+// InstrumentedJokeRepository.
+type Observer func(ctx context.Context, method string, duration time.Duration, err error)
+
+// InstrumentedJokeRepository is a JokeRepository decorator that reports the
+// duration and error of each call to an Observer. It can wrap any other
+// JokeRepository, including other decorators.
+type InstrumentedJokeRepository struct {
+	next    JokeRepository
+	observe Observer
+}
+
+func NewInstrumentedJokeRepository(next JokeRepository, observe Observer) *InstrumentedJokeRepository {
+	return &InstrumentedJokeRepository{
+		next:    next,
+		observe: observe,
+	}
+}
+
+// LogObserver returns an Observer that logs every call.
+func LogObserver(logger *slog.Logger) Observer {
+	return func(ctx context.Context, method string, duration time.Duration, err error) {
+		attrs := []any{"method", method, "duration", duration}
+		if err != nil {
+			attrs = append(attrs, "error", err.Error())
+		}
+
+		logger.InfoContext(ctx, "Repository call", attrs...)
+	}
+}
+
+func (r *InstrumentedJokeRepository) record(ctx context.Context, method string, start time.Time, err error) {
+	r.observe(ctx, method, time.Since(start), err)
+}
+
+func (r *InstrumentedJokeRepository) GetJoke(ctx context.Context, id int64) (*model.Joke, error) {
+	start := time.Now()
+	joke, err := r.next.GetJoke(ctx, id)
+	r.record(ctx, "GetJoke", start, err)
+	return joke, err
+}
+
+func (r *InstrumentedJokeRepository) GetRandomJoke(ctx context.Context) (*model.Joke, error) {
+	start := time.Now()
+	joke, err := r.next.GetRandomJoke(ctx)
+	r.record(ctx, "GetRandomJoke", start, err)
+	return joke, err
+}
+
+func (r *InstrumentedJokeRepository) GetRandomJokeMaxLength(ctx context.Context, maxLength int) (*model.Joke, error) {
+	start := time.Now()
+	joke, err := r.next.GetRandomJokeMaxLength(ctx, maxLength)
+	r.record(ctx, "GetRandomJokeMaxLength", start, err)
+	return joke, err
+}
+
+func (r *InstrumentedJokeRepository) GetRandomJokes(ctx context.Context, n int) ([]*model.Joke, error) {
+	start := time.Now()
+	jokes, err := r.next.GetRandomJokes(ctx, n)
+	r.record(ctx, "GetRandomJokes", start, err)
+	return jokes, err
+}
+
+func (r *InstrumentedJokeRepository) ListJokes(ctx context.Context, limit, offset int) ([]*model.Joke, error) {
+	start := time.Now()
+	jokes, err := r.next.ListJokes(ctx, limit, offset)
+	r.record(ctx, "ListJokes", start, err)
+	return jokes, err
+}
+
+func (r *InstrumentedJokeRepository) ListShuffledJokes(ctx context.Context, seed string, limit, offset int) ([]*model.Joke, error) {
+	start := time.Now()
+	jokes, err := r.next.ListShuffledJokes(ctx, seed, limit, offset)
+	r.record(ctx, "ListShuffledJokes", start, err)
+	return jokes, err
+}
+
+func (r *InstrumentedJokeRepository) GetRandomSample(ctx context.Context, n int, seed int64) ([]*model.Joke, error) {
+	start := time.Now()
+	jokes, err := r.next.GetRandomSample(ctx, n, seed)
+	r.record(ctx, "GetRandomSample", start, err)
+	return jokes, err
+}
+
+func (r *InstrumentedJokeRepository) StreamJokes(ctx context.Context, limit, offset int, fn func(*model.Joke) error) error {
+	start := time.Now()
+	err := r.next.StreamJokes(ctx, limit, offset, fn)
+	r.record(ctx, "StreamJokes", start, err)
+	return err
+}
+
+func (r *InstrumentedJokeRepository) EachJoke(ctx context.Context, fn func(*model.Joke) error) error {
+	start := time.Now()
+	err := r.next.EachJoke(ctx, fn)
+	r.record(ctx, "EachJoke", start, err)
+	return err
+}
+
+func (r *InstrumentedJokeRepository) CreateJoke(ctx context.Context, joke *model.Joke) (int64, error) {
+	start := time.Now()
+	id, err := r.next.CreateJoke(ctx, joke)
+	r.record(ctx, "CreateJoke", start, err)
+	return id, err
+}
+
+func (r *InstrumentedJokeRepository) CreateJokeWithID(ctx context.Context, id int64, joke *model.Joke) error {
+	start := time.Now()
+	err := r.next.CreateJokeWithID(ctx, id, joke)
+	r.record(ctx, "CreateJokeWithID", start, err)
+	return err
+}
+
+func (r *InstrumentedJokeRepository) UpdateJoke(ctx context.Context, joke *model.Joke) error {
+	start := time.Now()
+	err := r.next.UpdateJoke(ctx, joke)
+	r.record(ctx, "UpdateJoke", start, err)
+	return err
+}
+
+func (r *InstrumentedJokeRepository) DeleteJoke(ctx context.Context, id int64) error {
+	start := time.Now()
+	err := r.next.DeleteJoke(ctx, id)
+	r.record(ctx, "DeleteJoke", start, err)
+	return err
+}
+
+func (r *InstrumentedJokeRepository) ReplaceJokes(ctx context.Context, jokes []*model.Joke) error {
+	start := time.Now()
+	err := r.next.ReplaceJokes(ctx, jokes)
+	r.record(ctx, "ReplaceJokes", start, err)
+	return err
+}
+
+func (r *InstrumentedJokeRepository) CountJokes(ctx context.Context) (int, error) {
+	start := time.Now()
+	count, err := r.next.CountJokes(ctx)
+	r.record(ctx, "CountJokes", start, err)
+	return count, err
+}
+
+func (r *InstrumentedJokeRepository) ListJokeIDs(ctx context.Context) ([]int64, error) {
+	start := time.Now()
+	ids, err := r.next.ListJokeIDs(ctx)
+	r.record(ctx, "ListJokeIDs", start, err)
+	return ids, err
+}
+
+func (r *InstrumentedJokeRepository) ResolvePublicID(ctx context.Context, publicID string) (int64, error) {
+	start := time.Now()
+	id, err := r.next.ResolvePublicID(ctx, publicID)
+	r.record(ctx, "ResolvePublicID", start, err)
+	return id, err
+}
+
+func (r *InstrumentedJokeRepository) JokeTextExists(ctx context.Context, text string) (bool, error) {
+	start := time.Now()
+	exists, err := r.next.JokeTextExists(ctx, text)
+	r.record(ctx, "JokeTextExists", start, err)
+	return exists, err
+}
+
+func (r *InstrumentedJokeRepository) FindJokesByText(ctx context.Context, text string) ([]*model.Joke, error) {
+	start := time.Now()
+	jokes, err := r.next.FindJokesByText(ctx, text)
+	r.record(ctx, "FindJokesByText", start, err)
+	return jokes, err
+}
+
+func (r *InstrumentedJokeRepository) GetOrCreateJokes(ctx context.Context, jokes []*model.Joke) ([]UpsertResult, error) {
+	start := time.Now()
+	results, err := r.next.GetOrCreateJokes(ctx, jokes)
+	r.record(ctx, "GetOrCreateJokes", start, err)
+	return results, err
+}
+
+func (r *InstrumentedJokeRepository) SetJokeFeatured(ctx context.Context, id int64, featured bool) error {
+	start := time.Now()
+	err := r.next.SetJokeFeatured(ctx, id, featured)
+	r.record(ctx, "SetJokeFeatured", start, err)
+	return err
+}
+
+func (r *InstrumentedJokeRepository) ListFeaturedJokes(ctx context.Context, limit, offset int) ([]*model.Joke, error) {
+	start := time.Now()
+	jokes, err := r.next.ListFeaturedJokes(ctx, limit, offset)
+	r.record(ctx, "ListFeaturedJokes", start, err)
+	return jokes, err
+}
+
+func (r *InstrumentedJokeRepository) ListRecentJokes(ctx context.Context, n int) ([]*model.Joke, error) {
+	start := time.Now()
+	jokes, err := r.next.ListRecentJokes(ctx, n)
+	r.record(ctx, "ListRecentJokes", start, err)
+	return jokes, err
+}
+
+func (r *InstrumentedJokeRepository) ListJokesFiltered(ctx context.Context, filter JokeFilter, limit, offset int) ([]*model.Joke, error) {
+	start := time.Now()
+	jokes, err := r.next.ListJokesFiltered(ctx, filter, limit, offset)
+	r.record(ctx, "ListJokesFiltered", start, err)
+	return jokes, err
+}
+
+func (r *InstrumentedJokeRepository) CountJokesFiltered(ctx context.Context, filter JokeFilter) (int, error) {
+	start := time.Now()
+	count, err := r.next.CountJokesFiltered(ctx, filter)
+	r.record(ctx, "CountJokesFiltered", start, err)
+	return count, err
+}
+
+func (r *InstrumentedJokeRepository) CountFeaturedJokes(ctx context.Context) (int, error) {
+	start := time.Now()
+	count, err := r.next.CountFeaturedJokes(ctx)
+	r.record(ctx, "CountFeaturedJokes", start, err)
+	return count, err
+}
+
+func (r *InstrumentedJokeRepository) TouchJokes(ctx context.Context, accessed map[int64]time.Time) error {
+	start := time.Now()
+	err := r.next.TouchJokes(ctx, accessed)
+	r.record(ctx, "TouchJokes", start, err)
+	return err
+}
+
+func (r *InstrumentedJokeRepository) ListStaleJokes(ctx context.Context, before time.Time, limit, offset int) ([]*model.Joke, error) {
+	start := time.Now()
+	jokes, err := r.next.ListStaleJokes(ctx, before, limit, offset)
+	r.record(ctx, "ListStaleJokes", start, err)
+	return jokes, err
+}
+
+func (r *InstrumentedJokeRepository) CountStaleJokes(ctx context.Context, before time.Time) (int, error) {
+	start := time.Now()
+	count, err := r.next.CountStaleJokes(ctx, before)
+	r.record(ctx, "CountStaleJokes", start, err)
+	return count, err
+}
+
+func (r *InstrumentedJokeRepository) CountJokesPerDay(ctx context.Context, since time.Time) ([]model.DailyCount, error) {
+	start := time.Now()
+	counts, err := r.next.CountJokesPerDay(ctx, since)
+	r.record(ctx, "CountJokesPerDay", start, err)
+	return counts, err
+}
+
+func (r *InstrumentedJokeRepository) ListJokesByCreation(ctx context.Context, limit, offset int) ([]*model.Joke, error) {
+	start := time.Now()
+	jokes, err := r.next.ListJokesByCreation(ctx, limit, offset)
+	r.record(ctx, "ListJokesByCreation", start, err)
+	return jokes, err
+}
+
+func (r *InstrumentedJokeRepository) JokeLengthPercentiles(ctx context.Context) (*model.LengthPercentiles, error) {
+	start := time.Now()
+	stats, err := r.next.JokeLengthPercentiles(ctx)
+	r.record(ctx, "JokeLengthPercentiles", start, err)
+	return stats, err
+}
+
+func (r *InstrumentedJokeRepository) AddJokeViews(ctx context.Context, views map[int64]int64) error {
+	start := time.Now()
+	err := r.next.AddJokeViews(ctx, views)
+	r.record(ctx, "AddJokeViews", start, err)
+	return err
+}
+
+func (r *InstrumentedJokeRepository) IncrementAndGet(ctx context.Context, id int64, field string, delta int) (*model.Joke, error) {
+	start := time.Now()
+	joke, err := r.next.IncrementAndGet(ctx, id, field, delta)
+	r.record(ctx, "IncrementAndGet", start, err)
+	return joke, err
+}
+
+func (r *InstrumentedJokeRepository) ResetJokeCounters(ctx context.Context, id int64) (*model.Joke, error) {
+	start := time.Now()
+	joke, err := r.next.ResetJokeCounters(ctx, id)
+	r.record(ctx, "ResetJokeCounters", start, err)
+	return joke, err
+}
+
+func (r *InstrumentedJokeRepository) ListMostViewedJokes(ctx context.Context, limit, offset int) ([]*model.Joke, error) {
+	start := time.Now()
+	jokes, err := r.next.ListMostViewedJokes(ctx, limit, offset)
+	r.record(ctx, "ListMostViewedJokes", start, err)
+	return jokes, err
+}
+
+func (r *InstrumentedJokeRepository) JokesChecksum(ctx context.Context) (string, int, error) {
+	start := time.Now()
+	checksum, count, err := r.next.JokesChecksum(ctx)
+	r.record(ctx, "JokesChecksum", start, err)
+	return checksum, count, err
+}
+
+func (r *InstrumentedJokeRepository) CreateCollection(ctx context.Context, collection *model.Collection) (int64, error) {
+	start := time.Now()
+	id, err := r.next.CreateCollection(ctx, collection)
+	r.record(ctx, "CreateCollection", start, err)
+	return id, err
+}
+
+func (r *InstrumentedJokeRepository) GetCollection(ctx context.Context, id int64) (*model.Collection, error) {
+	start := time.Now()
+	collection, err := r.next.GetCollection(ctx, id)
+	r.record(ctx, "GetCollection", start, err)
+	return collection, err
+}
+
+func (r *InstrumentedJokeRepository) AddJokeToCollection(ctx context.Context, collectionID, jokeID int64) error {
+	start := time.Now()
+	err := r.next.AddJokeToCollection(ctx, collectionID, jokeID)
+	r.record(ctx, "AddJokeToCollection", start, err)
+	return err
+}
+
+func (r *InstrumentedJokeRepository) RemoveJokeFromCollection(ctx context.Context, collectionID, jokeID int64) error {
+	start := time.Now()
+	err := r.next.RemoveJokeFromCollection(ctx, collectionID, jokeID)
+	r.record(ctx, "RemoveJokeFromCollection", start, err)
+	return err
+}
+
+func (r *InstrumentedJokeRepository) ListCollectionJokes(ctx context.Context, collectionID int64, limit, offset int) ([]*model.Joke, error) {
+	start := time.Now()
+	jokes, err := r.next.ListCollectionJokes(ctx, collectionID, limit, offset)
+	r.record(ctx, "ListCollectionJokes", start, err)
+	return jokes, err
+}
+
+func (r *InstrumentedJokeRepository) CountCollectionJokes(ctx context.Context, collectionID int64) (int, error) {
+	start := time.Now()
+	count, err := r.next.CountCollectionJokes(ctx, collectionID)
+	r.record(ctx, "CountCollectionJokes", start, err)
+	return count, err
+}
+
+func (r *InstrumentedJokeRepository) Close() error {
+	return r.next.Close()
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// slowRepository delays GetJoke so the measured duration is predictable.
+type slowRepository struct {
+	JokeRepository
+	delay time.Duration
+}
+
+func (s *slowRepository) GetJoke(ctx context.Context, id int64) (*model.Joke, error) {
+	time.Sleep(s.delay)
+	return s.JokeRepository.GetJoke(ctx, id)
+}
+
+func TestInstrumentedLogsCalls(t *testing.T) {
+	ctx := context.Background()
+	sqlite, err := NewSQLiteJokeRepository(filepath.Join(t.TempDir(), "jokes.db"), Options{})
+	if err != nil {
+		t.Fatalf("creating repository: %v", err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+
+	id, err := sqlite.CreateJoke(ctx, &model.Joke{Text: "A timed joke"})
+	if err != nil {
+		t.Fatalf("creating joke: %v", err)
+	}
+
+	var logs bytes.Buffer
+	repo := NewInstrumentedJokeRepository(
+		&slowRepository{JokeRepository: sqlite, delay: 5 * time.Millisecond},
+		LogObserver(slog.New(slog.NewJSONHandler(&logs, nil))),
+	)
+
+	if _, err := repo.GetJoke(ctx, id); err != nil {
+		t.Fatalf("GetJoke: %v", err)
+	}
+	if _, err := repo.GetJoke(ctx, id+1); !errors.Is(err, ErrJokeNotFound) {
+		t.Fatalf("GetJoke of a missing joke: err = %v, want ErrJokeNotFound", err)
+	}
+
+	type entry struct {
+		Msg      string        `json:"msg"`
+		Method   string        `json:"method"`
+		Duration time.Duration `json:"duration"`
+		Error    string        `json:"error"`
+	}
+	var entries []entry
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var e entry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("decoding log line %q: %v", line, err)
+		}
+		entries = append(entries, e)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("got %d log lines, want 2:\n%s", len(entries), logs.String())
+	}
+	for _, e := range entries {
+		if e.Msg != "Repository call" || e.Method != "GetJoke" {
+			t.Errorf("logged %q for method %q, want a repository call to GetJoke", e.Msg, e.Method)
+		}
+		if e.Duration < 5*time.Millisecond {
+			t.Errorf("duration = %v, want at least the 5ms delay", e.Duration)
+		}
+	}
+	if entries[0].Error != "" {
+		t.Errorf("successful call logged error %q", entries[0].Error)
+	}
+	if entries[1].Error != ErrJokeNotFound.Error() {
+		t.Errorf("failed call logged error %q, want %q", entries[1].Error, ErrJokeNotFound.Error())
+	}
+}
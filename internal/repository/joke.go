@@ -7,37 +7,104 @@ import (
 	"fmt"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/google/uuid"
 	"github.com/treboc/huhu-api/internal/model"
 )
 
 var (
 	ErrJokeNotFound = errors.New("joke not found")
 	ErrNoJokes      = errors.New("no jokes available")
+
+	// ErrJokeLimitReached is returned by writes that would grow the
+	// collection past Options.MaxJokes.
+	ErrJokeLimitReached = errors.New("joke limit reached")
 )
 
 type JokeRepository interface {
+	CollectionRepository
+
 	GetJoke(ctx context.Context, id int64) (*model.Joke, error)
 	GetRandomJoke(ctx context.Context) (*model.Joke, error)
-	ListJokes(ctx context.Context, offset, limit int) ([]*model.Joke, error)
+	GetRandomJokeMaxLength(ctx context.Context, maxLength int) (*model.Joke, error)
+	GetRandomJokes(ctx context.Context, n int) ([]*model.Joke, error)
+	ListJokes(ctx context.Context, limit, offset int) ([]*model.Joke, error)
+	ListShuffledJokes(ctx context.Context, seed string, limit, offset int) ([]*model.Joke, error)
+	GetRandomSample(ctx context.Context, n int, seed int64) ([]*model.Joke, error)
+	StreamJokes(ctx context.Context, limit, offset int, fn func(*model.Joke) error) error
+	EachJoke(ctx context.Context, fn func(*model.Joke) error) error
 	CreateJoke(ctx context.Context, joke *model.Joke) (int64, error)
+	CreateJokeWithID(ctx context.Context, id int64, joke *model.Joke) error
 	UpdateJoke(ctx context.Context, joke *model.Joke) error
 	DeleteJoke(ctx context.Context, id int64) error
+	ReplaceJokes(ctx context.Context, jokes []*model.Joke) error
 	CountJokes(ctx context.Context) (int, error)
+	ListJokeIDs(ctx context.Context) ([]int64, error)
+	ResolvePublicID(ctx context.Context, publicID string) (int64, error)
+	JokeTextExists(ctx context.Context, text string) (bool, error)
+	FindJokesByText(ctx context.Context, text string) ([]*model.Joke, error)
+	GetOrCreateJokes(ctx context.Context, jokes []*model.Joke) ([]UpsertResult, error)
+	SetJokeFeatured(ctx context.Context, id int64, featured bool) error
+	ListFeaturedJokes(ctx context.Context, limit, offset int) ([]*model.Joke, error)
+	ListRecentJokes(ctx context.Context, n int) ([]*model.Joke, error)
+	ListJokesFiltered(ctx context.Context, filter JokeFilter, limit, offset int) ([]*model.Joke, error)
+	ListJokesByCreation(ctx context.Context, limit, offset int) ([]*model.Joke, error)
+	CountJokesFiltered(ctx context.Context, filter JokeFilter) (int, error)
+	CountFeaturedJokes(ctx context.Context) (int, error)
+	TouchJokes(ctx context.Context, accessed map[int64]time.Time) error
+	AddJokeViews(ctx context.Context, views map[int64]int64) error
+	IncrementAndGet(ctx context.Context, id int64, field string, delta int) (*model.Joke, error)
+	ResetJokeCounters(ctx context.Context, id int64) (*model.Joke, error)
+	ListMostViewedJokes(ctx context.Context, limit, offset int) ([]*model.Joke, error)
+	ListStaleJokes(ctx context.Context, before time.Time, limit, offset int) ([]*model.Joke, error)
+	CountStaleJokes(ctx context.Context, before time.Time) (int, error)
+	CountJokesPerDay(ctx context.Context, since time.Time) ([]model.DailyCount, error)
+	JokeLengthPercentiles(ctx context.Context) (*model.LengthPercentiles, error)
+	JokesChecksum(ctx context.Context) (string, int, error)
 	Close() error
 }
 
 type SQLiteJokeRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	readDB *sql.DB
+	opts   Options
+}
+
+// Options configures optional behaviour of the SQLite repository.
+type Options struct {
+	// PublicIDs exposes each joke's UUID as its ID instead of the internal
+	// autoincrement integer.
+	PublicIDs bool
+
+	// CompressText stores long joke texts gzip compressed. Reads handle
+	// compressed and plain rows regardless of this setting.
+	CompressText bool
+
+	// MaxJokes caps the total number of jokes. Inserts beyond it fail with
+	// ErrJokeLimitReached. Zero means no limit.
+	MaxJokes int
+}
+
+// jokeColumns is the column list scanned by scanJoke.
+const jokeColumns = "jokes.id, jokes.public_id, jokes.text, jokes.setup, jokes.punchline, jokes.featured, jokes.created_at, jokes.updated_at, jokes.last_accessed_at, jokes.view_count"
+
+type rowScanner interface {
+	Scan(dest ...any) error
 }
 
-func NewSQLiteJokeRepository(dbPath string) (*SQLiteJokeRepository, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+// sqliteBusyTimeout is how long, in milliseconds, a connection waits for a
+// lock held by another connection before failing with SQLITE_BUSY.
+const sqliteBusyTimeout = 5000
+
+func NewSQLiteJokeRepository(dbPath string, opts Options) (*SQLiteJokeRepository, error) {
+	// WAL lets readers proceed while a write is in progress. Foreign keys
+	// are off by default in SQLite and have to be enabled per connection.
+	db, err := sql.Open(driverName, fmt.Sprintf("file:%s?_journal_mode=WAL&_busy_timeout=%d&_foreign_keys=on", dbPath, sqliteBusyTimeout))
 	if err != nil {
 		return nil, fmt.Errorf("error opening database: %w", err)
 	}
 
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
@@ -49,26 +116,125 @@ func NewSQLiteJokeRepository(dbPath string) (*SQLiteJokeRepository, error) {
 		)
 	`)
 	if err != nil {
+		db.Close()
 		return nil, fmt.Errorf("error creating jokes table: %w", err)
 	}
 
-	return &SQLiteJokeRepository{db: db}, nil
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := backfillPublicIDs(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := backfillTextHashes(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := backfillTextLengths(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	// Hot read paths use a separate read-only pool so they never queue up
+	// behind connections that are busy writing.
+	readDB, err := sql.Open(driverName, fmt.Sprintf("file:%s?mode=ro&_busy_timeout=%d", dbPath, sqliteBusyTimeout))
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error opening read-only database: %w", err)
+	}
+
+	if err := readDB.Ping(); err != nil {
+		readDB.Close()
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to read-only database: %w", err)
+	}
+
+	return &SQLiteJokeRepository{db: db, readDB: readDB, opts: opts}, nil
+}
+
+// backfillPublicIDs assigns a public ID to jokes created before public IDs
+// existed, so switching the ID scheme on works for the whole collection.
+func backfillPublicIDs(db *sql.DB) error {
+	rows, err := db.Query(`SELECT id FROM jokes WHERE public_id IS NULL`)
+	if err != nil {
+		return fmt.Errorf("error finding jokes without public ID: %w", err)
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning joke ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		if _, err := db.Exec(`UPDATE jokes SET public_id = ? WHERE id = ?`, newPublicID(), id); err != nil {
+			return fmt.Errorf("error assigning public ID: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// newPublicID returns a time-ordered UUID (v7), so public IDs sort by creation
+// time just like the integer IDs do.
+func newPublicID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// scanJoke scans a row selected with jokeColumns.
+func (r *SQLiteJokeRepository) scanJoke(row rowScanner) (*model.Joke, error) {
+	joke := &model.Joke{}
+	var publicID sql.NullString
+	var text, setup, punchline []byte
+	var lastAccessedAt sql.NullTime
+
+	if err := row.Scan(&joke.ID, &publicID, &text, &setup, &punchline, &joke.Featured, &joke.CreatedAt, &joke.UpdatedAt, &lastAccessedAt, &joke.ViewCount); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if joke.Text, err = decodeText(text); err != nil {
+		return nil, err
+	}
+	if joke.Setup, err = decodeText(setup); err != nil {
+		return nil, err
+	}
+	if joke.Punchline, err = decodeText(punchline); err != nil {
+		return nil, err
+	}
+
+	if lastAccessedAt.Valid {
+		joke.LastAccessedAt = &lastAccessedAt.Time
+	}
+
+	if r.opts.PublicIDs {
+		joke.PublicID = publicID.String
+	}
+
+	return joke, nil
 }
 
 func (r *SQLiteJokeRepository) GetJoke(ctx context.Context, id int64) (*model.Joke, error) {
 	query := `
-		SELECT id, text, created_at, updated_at
+		SELECT ` + jokeColumns + `
 		FROM jokes
 		WHERE id = ?
 	`
 
-	row := r.db.QueryRowContext(ctx, query, id)
-	joke := &model.Joke{}
-
-	err := row.Scan(&joke.ID, &joke.Text, &joke.CreatedAt, &joke.UpdatedAt)
+	joke, err := r.scanJoke(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("joke not found: %w", err)
+			return nil, ErrJokeNotFound
 		}
 		return nil, fmt.Errorf("error getting joke: %w", err)
 	}
@@ -78,19 +244,38 @@ func (r *SQLiteJokeRepository) GetJoke(ctx context.Context, id int64) (*model.Jo
 
 func (r *SQLiteJokeRepository) GetRandomJoke(ctx context.Context) (*model.Joke, error) {
 	query := `
-		SELECT id, text, created_at, updated_at
+		SELECT ` + jokeColumns + `
 		FROM jokes
 		ORDER BY RANDOM()
 		LIMIT 1
 	`
 
-	row := r.db.QueryRowContext(ctx, query)
-	joke := &model.Joke{}
+	joke, err := r.scanJoke(r.readDB.QueryRowContext(ctx, query))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNoJokes
+		}
+		return nil, fmt.Errorf("error getting joke: %w", err)
+	}
+
+	return joke, nil
+}
+
+// GetRandomJokeMaxLength returns a random joke whose text is at most
+// maxLength characters long, or ErrNoJokes when none is that short.
+func (r *SQLiteJokeRepository) GetRandomJokeMaxLength(ctx context.Context, maxLength int) (*model.Joke, error) {
+	query := `
+		SELECT ` + jokeColumns + `
+		FROM jokes
+		WHERE text_length <= ?
+		ORDER BY RANDOM()
+		LIMIT 1
+	`
 
-	err := row.Scan(&joke.ID, &joke.Text, &joke.CreatedAt, &joke.UpdatedAt)
+	joke, err := r.scanJoke(r.readDB.QueryRowContext(ctx, query, maxLength))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("joke not found: %w", err)
+			return nil, ErrNoJokes
 		}
 		return nil, fmt.Errorf("error getting joke: %w", err)
 	}
@@ -98,14 +283,85 @@ func (r *SQLiteJokeRepository) GetRandomJoke(ctx context.Context) (*model.Joke,
 	return joke, nil
 }
 
+// GetRandomJokes returns up to n distinct random jokes. It returns fewer
+// when there aren't that many jokes, and none on an empty table.
+func (r *SQLiteJokeRepository) GetRandomJokes(ctx context.Context, n int) ([]*model.Joke, error) {
+	query := `
+		SELECT ` + jokeColumns + `
+		FROM jokes
+		ORDER BY RANDOM()
+		LIMIT ?
+	`
+
+	return r.queryJokes(ctx, query, n)
+}
+
 func (r *SQLiteJokeRepository) ListJokes(ctx context.Context, limit, offset int) ([]*model.Joke, error) {
 	query := `
-		SELECT id, text, created_at, updated_at
+		SELECT ` + jokeColumns + `
+		FROM jokes
+		ORDER BY id
+		LIMIT ? OFFSET ?
+	`
+
+	return r.queryJokes(ctx, query, limit, offset)
+}
+
+// StreamJokes calls fn for each joke ordered by ID, reading them from a
+// cursor instead of loading them all first. A limit of 0 or less streams
+// all jokes from offset on. Streaming stops at the first error fn returns.
+// It reads from the read-only pool, so a slow consumer doesn't hold up
+// writes waiting for a connection.
+func (r *SQLiteJokeRepository) StreamJokes(ctx context.Context, limit, offset int, fn func(*model.Joke) error) error {
+	if limit <= 0 {
+		limit = -1 // no limit in SQLite
+	}
+
+	query := `
+		SELECT ` + jokeColumns + `
 		FROM jokes
+		ORDER BY id
 		LIMIT ? OFFSET ?
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := r.readDB.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return fmt.Errorf("error streaming jokes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		joke, err := r.scanJoke(rows)
+		if err != nil {
+			return fmt.Errorf("error scanning joke: %w", err)
+		}
+
+		if err := fn(joke); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error streaming jokes: %w", err)
+	}
+
+	return nil
+}
+
+// EachJoke calls fn for every joke ordered by ID without building a slice
+// of them. It stops at the first error fn returns, or once ctx is done.
+func (r *SQLiteJokeRepository) EachJoke(ctx context.Context, fn func(*model.Joke) error) error {
+	return r.StreamJokes(ctx, 0, 0, func(joke *model.Joke) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(joke)
+	})
+}
+
+// queryJokes runs a query selecting jokeColumns and scans all resulting rows.
+func (r *SQLiteJokeRepository) queryJokes(ctx context.Context, query string, args ...any) ([]*model.Joke, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error listing jokes: %w", err)
 	}
@@ -113,39 +369,168 @@ func (r *SQLiteJokeRepository) ListJokes(ctx context.Context, limit, offset int)
 
 	jokes := make([]*model.Joke, 0)
 	for rows.Next() {
-		joke := &model.Joke{}
-		if err := rows.Scan(&joke.ID, &joke.Text, &joke.CreatedAt, &joke.UpdatedAt); err != nil {
+		joke, err := r.scanJoke(rows)
+		if err != nil {
 			return nil, fmt.Errorf("error scanning joke: %w", err)
 		}
 		jokes = append(jokes, joke)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing jokes: %w", err)
+	}
+
 	return jokes, nil
 }
 
+// jokeSetup returns the stored setup of a joke. Single-text jokes use their
+// whole text as the setup.
+func jokeSetup(joke *model.Joke) string {
+	if joke.Setup == "" {
+		return joke.Text
+	}
+	return joke.Setup
+}
+
+// CreateJoke stores a new joke unless a joke with the same normalized text
+// (see NormalizeText) already exists, in which case the ID of the oldest
+// such joke is returned instead. Concurrent identical creates resolve to a
+// single joke.
 func (r *SQLiteJokeRepository) CreateJoke(ctx context.Context, joke *model.Joke) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	id, _, err := r.getOrInsertJoke(ctx, tx, 0, joke)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing joke: %w", err)
+	}
+
+	return id, nil
+}
+
+// CreateJokeWithID creates a joke with the given ID. A joke that already
+// has the ID, or the same normalized text, is a ConstraintUnique error.
+// SQLite moves the ID sequence past the ID, so jokes created later never
+// collide with it.
+func (r *SQLiteJokeRepository) CreateJokeWithID(ctx context.Context, id int64, joke *model.Joke) error {
+	_, created, err := r.insertJoke(ctx, r.db, id, joke)
+	if err != nil {
+		return err
+	}
+	if created {
+		return nil
+	}
+
+	if _, err := r.findJokeByText(ctx, r.db, joke.Text); err != nil {
+		return err
+	}
+	return &ConstraintError{Kind: ConstraintUnique, Err: errDuplicateText}
+}
+
+// errDuplicateText is wrapped in the ConstraintError for a joke whose text
+// is already taken.
+var errDuplicateText = errors.New("a joke with the same text already exists")
+
+// execer is implemented by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// getOrInsertJoke inserts joke through tx unless a joke with the same
+// normalized text exists, and returns the ID of the joke that holds the
+// text either way. Inserting first, rather than looking the joke up and
+// then inserting, takes the write lock right away: a concurrent call with
+// the same text waits for this transaction and then finds the joke,
+// instead of both inserting it or failing to upgrade a read lock.
+func (r *SQLiteJokeRepository) getOrInsertJoke(ctx context.Context, tx *sql.Tx, id int64, joke *model.Joke) (int64, bool, error) {
+	id, created, err := r.insertJoke(ctx, tx, id, joke)
+	if err != nil || created {
+		return id, created, err
+	}
+
+	id, err = r.findJokeByText(ctx, tx, joke.Text)
+	return id, false, err
+}
+
+// findJokeByText returns the ID of the oldest joke with the same normalized
+// text as text. It is called after insertJoke inserted nothing, so when no
+// such joke exists the joke limit was the reason.
+func (r *SQLiteJokeRepository) findJokeByText(ctx context.Context, db execer, text string) (int64, error) {
 	query := `
-		INSERT INTO jokes (text, created_at, updated_at)
-		VALUES (?, ?, ?)
+		SELECT id
+		FROM jokes
+		WHERE text_hash = ?
+		ORDER BY id
+		LIMIT 1
 	`
 
-	result, err := r.db.ExecContext(ctx, query, joke.Text, time.Now().UTC(), time.Now().UTC())
+	var id int64
+	err := db.QueryRowContext(ctx, query, textHash(text)).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, ErrJokeLimitReached
+	}
 	if err != nil {
-		return 0, fmt.Errorf("error creating joke: %w", err)
+		return 0, fmt.Errorf("error looking up joke: %w", err)
 	}
 
-	id, err := result.LastInsertId()
+	return id, nil
+}
+
+// insertJoke stores joke through db, which may be a transaction, unless a
+// joke with the same normalized text exists. An id of 0 lets SQLite assign
+// one. The duplicate check and, with a joke limit configured, the count
+// check are part of the INSERT itself so concurrent writers can't slip past
+// them. created is false when nothing was inserted, either because of an
+// existing joke or because the joke limit is reached.
+func (r *SQLiteJokeRepository) insertJoke(ctx context.Context, db execer, id int64, joke *model.Joke) (int64, bool, error) {
+	query := `
+		INSERT INTO jokes (id, public_id, text, text_hash, text_length, setup, punchline, created_at, updated_at)
+		SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?
+		WHERE NOT EXISTS (SELECT 1 FROM jokes WHERE text_hash = ?)
+		AND (? = 0 OR (SELECT COUNT(*) FROM jokes) < ?)
+	`
+
+	// A NULL id is assigned from the sequence.
+	var idArg any
+	if id != 0 {
+		idArg = id
+	}
+
+	hash := textHash(joke.Text)
+	now := time.Now().UTC()
+	result, err := db.ExecContext(ctx, query, idArg, newPublicID(), r.encodeText(joke.Text), hash, textLength(joke.Text), r.encodeText(jokeSetup(joke)), r.encodeText(joke.Punchline), now, now, hash, r.opts.MaxJokes, r.opts.MaxJokes)
 	if err != nil {
-		return 0, fmt.Errorf("error getting last insert ID: %w", err)
+		return 0, false, fmt.Errorf("error creating joke: %w", constraintError(err))
 	}
 
-	return id, nil
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, false, fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return 0, false, nil
+	}
+
+	id, err = result.LastInsertId()
+	if err != nil {
+		return 0, false, fmt.Errorf("error getting last insert ID: %w", err)
+	}
+
+	return id, true, nil
 }
 
 func (r *SQLiteJokeRepository) UpdateJoke(ctx context.Context, joke *model.Joke) error {
 	query := `
 		UPDATE jokes
-		SET text = ?, updated_at = ?
+		SET text = ?, text_hash = ?, text_length = ?, setup = ?, punchline = ?, updated_at = ?
 		WHERE id = ?
 	`
 
@@ -153,13 +538,17 @@ func (r *SQLiteJokeRepository) UpdateJoke(ctx context.Context, joke *model.Joke)
 	result, err := r.db.ExecContext(
 		ctx,
 		query,
-		joke.Text,
+		r.encodeText(joke.Text),
+		textHash(joke.Text),
+		textLength(joke.Text),
+		r.encodeText(jokeSetup(joke)),
+		r.encodeText(joke.Punchline),
 		now,
 		joke.ID,
 	)
 
 	if err != nil {
-		return fmt.Errorf("error updating joke: %w", err)
+		return fmt.Errorf("error updating joke: %w", constraintError(err))
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -168,7 +557,7 @@ func (r *SQLiteJokeRepository) UpdateJoke(ctx context.Context, joke *model.Joke)
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("joke not found")
+		return ErrJokeNotFound
 	}
 
 	return nil
@@ -191,7 +580,7 @@ func (r *SQLiteJokeRepository) DeleteJoke(ctx context.Context, id int64) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("joke not found")
+		return ErrJokeNotFound
 	}
 
 	return nil
@@ -213,6 +602,194 @@ func (r *SQLiteJokeRepository) CountJokes(ctx context.Context) (int, error) {
 	return count, nil
 }
 
+// ListJokeIDs returns the IDs of all jokes in ascending order.
+func (r *SQLiteJokeRepository) ListJokeIDs(ctx context.Context) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM jokes ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("error listing joke IDs: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning joke ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing joke IDs: %w", err)
+	}
+
+	return ids, nil
+}
+
+func (r *SQLiteJokeRepository) ResolvePublicID(ctx context.Context, publicID string) (int64, error) {
+	query := `
+		SELECT id
+		FROM jokes
+		WHERE public_id = ?
+	`
+
+	var id int64
+	if err := r.db.QueryRowContext(ctx, query, publicID).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrJokeNotFound
+		}
+		return 0, fmt.Errorf("error resolving public ID: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *SQLiteJokeRepository) JokeTextExists(ctx context.Context, text string) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM jokes WHERE text IN (?, ?))
+	`
+
+	// Match the text whether it was stored compressed or not.
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, text, compressText(text)).Scan(&exists); err != nil {
+		return false, fmt.Errorf("error checking joke text: %w", err)
+	}
+
+	return exists, nil
+}
+
+// FindJokesByText returns the jokes whose text matches text after both are
+// normalized, see NormalizeText.
+func (r *SQLiteJokeRepository) FindJokesByText(ctx context.Context, text string) ([]*model.Joke, error) {
+	query := `
+		SELECT ` + jokeColumns + `
+		FROM jokes
+		WHERE text_hash = ?
+		ORDER BY id
+	`
+
+	return r.queryJokes(ctx, query, textHash(text))
+}
+
+func (r *SQLiteJokeRepository) SetJokeFeatured(ctx context.Context, id int64, featured bool) error {
+	query := `
+		UPDATE jokes
+		SET featured = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query, featured, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("error updating featured status: %w", constraintError(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrJokeNotFound
+	}
+
+	return nil
+}
+
+func (r *SQLiteJokeRepository) ListFeaturedJokes(ctx context.Context, limit, offset int) ([]*model.Joke, error) {
+	query := `
+		SELECT ` + jokeColumns + `
+		FROM jokes
+		WHERE featured = 1
+		ORDER BY updated_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`
+
+	return r.queryJokes(ctx, query, limit, offset)
+}
+
+// ListRecentJokes lists the n most recently created jokes, newest first.
+func (r *SQLiteJokeRepository) ListRecentJokes(ctx context.Context, n int) ([]*model.Joke, error) {
+	query := `
+		SELECT ` + jokeColumns + `
+		FROM jokes
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`
+
+	return r.queryJokes(ctx, query, n)
+}
+
+// ListJokesByCreation lists jokes oldest first. Jokes created at the same
+// time are ordered by ID, so the order is total and pages never overlap.
+func (r *SQLiteJokeRepository) ListJokesByCreation(ctx context.Context, limit, offset int) ([]*model.Joke, error) {
+	query := `
+		SELECT ` + jokeColumns + `
+		FROM jokes
+		ORDER BY created_at, id
+		LIMIT ? OFFSET ?
+	`
+
+	return r.queryJokes(ctx, query, limit, offset)
+}
+
+func (r *SQLiteJokeRepository) CountFeaturedJokes(ctx context.Context) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM jokes
+		WHERE featured = 1
+	`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting featured jokes: %w", err)
+	}
+
+	return count, nil
+}
+
+// ListStaleJokes lists jokes that haven't been accessed since before. Jokes
+// that were never accessed count from their creation time. The least
+// recently accessed jokes come first.
+func (r *SQLiteJokeRepository) ListStaleJokes(ctx context.Context, before time.Time, limit, offset int) ([]*model.Joke, error) {
+	query := `
+		SELECT ` + jokeColumns + `
+		FROM jokes
+		WHERE COALESCE(last_accessed_at, created_at) < ?
+		ORDER BY COALESCE(last_accessed_at, created_at) ASC, id ASC
+		LIMIT ? OFFSET ?
+	`
+
+	return r.queryJokes(ctx, query, before.UTC(), limit, offset)
+}
+
+func (r *SQLiteJokeRepository) CountStaleJokes(ctx context.Context, before time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM jokes
+		WHERE COALESCE(last_accessed_at, created_at) < ?
+	`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, before.UTC()).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting stale jokes: %w", err)
+	}
+
+	return count, nil
+}
+
+// Ping runs a trivial query on both connection pools, which also opens a
+// first connection in each.
+func (r *SQLiteJokeRepository) Ping(ctx context.Context) error {
+	for _, db := range []*sql.DB{r.db, r.readDB} {
+		var one int
+		if err := db.QueryRowContext(ctx, `SELECT 1`).Scan(&one); err != nil {
+			return fmt.Errorf("error pinging database: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func (r *SQLiteJokeRepository) Close() error {
-	return r.db.Close()
+	return errors.Join(r.readDB.Close(), r.db.Close())
 }
@@ -0,0 +1,958 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func newTestRepository(t *testing.T, opts Options) *SQLiteJokeRepository {
+	t.Helper()
+
+	repo, err := NewSQLiteJokeRepository(filepath.Join(t.TempDir(), "jokes.db"), opts)
+	if err != nil {
+		t.Fatalf("creating repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	return repo
+}
+
+func TestTouchJokesUpdatesLastAccessedAt(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{})
+
+	id, err := repo.CreateJoke(ctx, &model.Joke{Text: "knock knock"})
+	if err != nil {
+		t.Fatalf("creating joke: %v", err)
+	}
+
+	joke, err := repo.GetJoke(ctx, id)
+	if err != nil {
+		t.Fatalf("getting joke: %v", err)
+	}
+	if joke.LastAccessedAt != nil {
+		t.Fatalf("new joke has last_accessed_at %v, want none", joke.LastAccessedAt)
+	}
+
+	// A joke deleted before the batch is written is skipped.
+	at := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	if err := repo.TouchJokes(ctx, map[int64]time.Time{id: at, id + 1: at}); err != nil {
+		t.Fatalf("touching jokes: %v", err)
+	}
+
+	joke, err = repo.GetJoke(ctx, id)
+	if err != nil {
+		t.Fatalf("getting joke: %v", err)
+	}
+	if joke.LastAccessedAt == nil || !joke.LastAccessedAt.Equal(at) {
+		t.Errorf("last_accessed_at = %v, want %v", joke.LastAccessedAt, at)
+	}
+}
+
+func TestListStaleJokesExcludesRecentlyAccessed(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{})
+
+	accessed, err := repo.CreateJoke(ctx, &model.Joke{Text: "accessed"})
+	if err != nil {
+		t.Fatalf("creating joke: %v", err)
+	}
+	idle, err := repo.CreateJoke(ctx, &model.Joke{Text: "idle"})
+	if err != nil {
+		t.Fatalf("creating joke: %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	cutoff := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	if err := repo.TouchJokes(ctx, map[int64]time.Time{accessed: time.Now()}); err != nil {
+		t.Fatalf("touching joke: %v", err)
+	}
+
+	jokes, err := repo.ListStaleJokes(ctx, cutoff, 10, 0)
+	if err != nil {
+		t.Fatalf("listing stale jokes: %v", err)
+	}
+	if len(jokes) != 1 || jokes[0].ID != idle {
+		t.Fatalf("stale jokes = %v, want only joke %d", jokes, idle)
+	}
+
+	count, err := repo.CountStaleJokes(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("counting stale jokes: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("stale count = %d, want 1", count)
+	}
+}
+
+func TestGetRandomJokeConcurrentWithWrites(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{})
+
+	for i := 0; i < 20; i++ {
+		if _, err := repo.CreateJoke(ctx, &model.Joke{Text: fmt.Sprintf("joke %d", i)}); err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+	}
+
+	const (
+		readers        = 8
+		readsPerReader = 200
+		writes         = 50
+		maxReadLatency = time.Second
+	)
+
+	errs := make(chan error, readers*readsPerReader+writes)
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < writes; i++ {
+			if _, err := repo.CreateJoke(ctx, &model.Joke{Text: fmt.Sprintf("new joke %d", i)}); err != nil {
+				errs <- fmt.Errorf("write: %w", err)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < readsPerReader; j++ {
+				start := time.Now()
+				if _, err := repo.GetRandomJoke(ctx); err != nil {
+					errs <- fmt.Errorf("read: %w", err)
+					continue
+				}
+				if d := time.Since(start); d > maxReadLatency {
+					errs <- fmt.Errorf("read took %v, want at most %v", d, maxReadLatency)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestListJokesPages(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{})
+
+	var ids []int64
+	for i := 0; i < 7; i++ {
+		id, err := repo.CreateJoke(ctx, &model.Joke{Text: fmt.Sprintf("joke %d", i)})
+		if err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	tests := []struct {
+		name          string
+		limit, offset int
+		want          []int64
+	}{
+		{"first page", 3, 0, ids[:3]},
+		{"middle page", 3, 2, ids[2:5]},
+		{"last page", 3, 6, ids[6:]},
+		{"past the end", 3, 10, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jokes, err := repo.ListJokes(ctx, tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("listing jokes: %v", err)
+			}
+
+			var got []int64
+			for _, joke := range jokes {
+				got = append(got, joke.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("limit %d, offset %d: got %v, want %v", tt.limit, tt.offset, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateMissingJoke(t *testing.T) {
+	repo := newTestRepository(t, Options{})
+
+	err := repo.UpdateJoke(context.Background(), &model.Joke{ID: 42, Text: "nobody home"})
+	if !errors.Is(err, ErrJokeNotFound) {
+		t.Errorf("UpdateJoke of a missing joke = %v, want ErrJokeNotFound", err)
+	}
+}
+
+func TestFindJokesByTextNormalizes(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{})
+
+	// CreateJoke would not store the second text next to the first, but a
+	// restored backup may hold both.
+	now := time.Now().UTC()
+	var seed []*model.Joke
+	for i, text := range []string{"Knock knock, who's there?", "knock   KNOCK,\twho's there?", "Knock knock, who is there?"} {
+		seed = append(seed, &model.Joke{ID: int64(i + 1), Text: text, CreatedAt: now, UpdatedAt: now})
+	}
+	if err := repo.ReplaceJokes(ctx, seed); err != nil {
+		t.Fatalf("seeding jokes: %v", err)
+	}
+	want := []int64{1, 2}
+
+	jokes, err := repo.FindJokesByText(ctx, "  KNOCK knock, WHO'S there? ")
+	if err != nil {
+		t.Fatalf("finding jokes: %v", err)
+	}
+	if len(jokes) != len(want) {
+		t.Fatalf("found %d jokes, want %d", len(jokes), len(want))
+	}
+	for i, joke := range jokes {
+		if joke.ID != want[i] {
+			t.Errorf("joke %d has ID %d, want %d", i, joke.ID, want[i])
+		}
+	}
+
+	jokes, err = repo.FindJokesByText(ctx, "no such joke")
+	if err != nil {
+		t.Fatalf("finding jokes: %v", err)
+	}
+	if jokes == nil || len(jokes) != 0 {
+		t.Errorf("jokes = %v, want an empty slice", jokes)
+	}
+}
+
+func TestListShuffledJokesPagesCoverEveryJoke(t *testing.T) {
+	repo := newTestRepository(t, Options{})
+	ctx := context.Background()
+
+	const count = 25
+	for i := 0; i < count; i++ {
+		if _, err := repo.CreateJoke(ctx, &model.Joke{Text: fmt.Sprintf("joke %d", i)}); err != nil {
+			t.Fatalf("CreateJoke: %v", err)
+		}
+	}
+
+	shuffle := func(seed string) []int64 {
+		var ids []int64
+		for offset := 0; ; offset += 7 {
+			jokes, err := repo.ListShuffledJokes(ctx, seed, 7, offset)
+			if err != nil {
+				t.Fatalf("ListShuffledJokes: %v", err)
+			}
+			for _, joke := range jokes {
+				ids = append(ids, joke.ID)
+			}
+			if len(jokes) < 7 {
+				return ids
+			}
+		}
+	}
+
+	first := shuffle("feed-1")
+	if len(first) != count {
+		t.Fatalf("paged through %d jokes, want %d", len(first), count)
+	}
+
+	seen := make(map[int64]bool, count)
+	ordered := true
+	for i, id := range first {
+		if seen[id] {
+			t.Fatalf("joke %d listed twice", id)
+		}
+		seen[id] = true
+		if i > 0 && id < first[i-1] {
+			ordered = false
+		}
+	}
+	if ordered {
+		t.Error("shuffle returned jokes in ID order")
+	}
+
+	if again := shuffle("feed-1"); !slices.Equal(again, first) {
+		t.Errorf("same seed gave %v, earlier %v", again, first)
+	}
+	if other := shuffle("feed-2"); slices.Equal(other, first) {
+		t.Error("different seeds gave the same order")
+	}
+}
+
+func TestGetRandomSample(t *testing.T) {
+	repo := newTestRepository(t, Options{})
+	ctx := context.Background()
+
+	const count = 12
+	for i := 0; i < count; i++ {
+		if _, err := repo.CreateJoke(ctx, &model.Joke{Text: fmt.Sprintf("joke %d", i)}); err != nil {
+			t.Fatalf("CreateJoke: %v", err)
+		}
+	}
+
+	sample := func(n int, seed int64) []int64 {
+		jokes, err := repo.GetRandomSample(ctx, n, seed)
+		if err != nil {
+			t.Fatalf("GetRandomSample: %v", err)
+		}
+		var ids []int64
+		for _, joke := range jokes {
+			ids = append(ids, joke.ID)
+		}
+		return ids
+	}
+
+	first := sample(5, 42)
+	if len(first) != 5 {
+		t.Fatalf("sample has %d jokes, want 5", len(first))
+	}
+	if again := sample(5, 42); !slices.Equal(again, first) {
+		t.Errorf("same seed gave %v, earlier %v", again, first)
+	}
+	if other := sample(5, 43); slices.Equal(other, first) {
+		t.Error("different seeds gave the same sample")
+	}
+
+	if all := sample(count+10, 42); len(all) != count {
+		t.Errorf("oversized sample has %d jokes, want all %d", len(all), count)
+	}
+}
+
+func TestCountJokesPerDay(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{})
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	createdAt := []time.Time{
+		today.AddDate(0, 0, -10), // before the window
+		today.AddDate(0, 0, -2).Add(3 * time.Hour),
+		today.AddDate(0, 0, -2).Add(20 * time.Hour),
+		today.Add(time.Minute),
+	}
+
+	for i, at := range createdAt {
+		id, err := repo.CreateJoke(ctx, &model.Joke{Text: fmt.Sprintf("joke %d", i)})
+		if err != nil {
+			t.Fatalf("creating joke: %v", err)
+		}
+		if _, err := repo.db.ExecContext(ctx, `UPDATE jokes SET created_at = ? WHERE id = ?`, at, id); err != nil {
+			t.Fatalf("backdating joke: %v", err)
+		}
+	}
+
+	counts, err := repo.CountJokesPerDay(ctx, today.AddDate(0, 0, -6))
+	if err != nil {
+		t.Fatalf("CountJokesPerDay: %v", err)
+	}
+
+	want := []model.DailyCount{
+		{Date: today.AddDate(0, 0, -2).Format(time.DateOnly), Count: 2},
+		{Date: today.Format(time.DateOnly), Count: 1},
+	}
+	if !slices.Equal(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+}
+
+func TestListRecentJokes(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{})
+
+	// Inserted out of creation order so ordering by id would be wrong.
+	now := time.Now().UTC()
+	createdAt := []time.Time{
+		now.Add(-2 * time.Hour),
+		now.Add(-72 * time.Hour),
+		now.Add(-time.Minute),
+		now.Add(-24 * time.Hour),
+	}
+
+	var ids []int64
+	for i, at := range createdAt {
+		id, err := repo.CreateJoke(ctx, &model.Joke{Text: fmt.Sprintf("joke %d", i)})
+		if err != nil {
+			t.Fatalf("creating joke: %v", err)
+		}
+		if _, err := repo.db.ExecContext(ctx, `UPDATE jokes SET created_at = ? WHERE id = ?`, at, id); err != nil {
+			t.Fatalf("backdating joke: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	tests := []struct {
+		n    int
+		want []int64
+	}{
+		{n: 1, want: []int64{ids[2]}},
+		{n: 3, want: []int64{ids[2], ids[0], ids[3]}},
+		{n: 10, want: []int64{ids[2], ids[0], ids[3], ids[1]}},
+	}
+
+	for _, tt := range tests {
+		jokes, err := repo.ListRecentJokes(ctx, tt.n)
+		if err != nil {
+			t.Fatalf("ListRecentJokes(%d): %v", tt.n, err)
+		}
+
+		var got []int64
+		for _, joke := range jokes {
+			got = append(got, joke.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ListRecentJokes(%d) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestListJokesFilteredByCreation(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{})
+
+	from := time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	createdAt := []time.Time{
+		from.Add(-time.Second),
+		from,
+		to.Add(-time.Second),
+		to,
+	}
+
+	var ids []int64
+	for i, at := range createdAt {
+		id, err := repo.CreateJoke(ctx, &model.Joke{Text: fmt.Sprintf("joke %d", i)})
+		if err != nil {
+			t.Fatalf("creating joke: %v", err)
+		}
+		if _, err := repo.db.ExecContext(ctx, `UPDATE jokes SET created_at = ? WHERE id = ?`, at, id); err != nil {
+			t.Fatalf("backdating joke: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	filter := JokeFilter{CreatedFrom: from, CreatedTo: to}
+	jokes, err := repo.ListJokesFiltered(ctx, filter, 10, 0)
+	if err != nil {
+		t.Fatalf("ListJokesFiltered: %v", err)
+	}
+
+	var got []int64
+	for _, joke := range jokes {
+		got = append(got, joke.ID)
+	}
+	if want := ids[1:3]; !slices.Equal(got, want) {
+		t.Errorf("jokes = %v, want %v", got, want)
+	}
+
+	count, err := repo.CountJokesFiltered(ctx, filter)
+	if err != nil {
+		t.Fatalf("CountJokesFiltered: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("count = %d, want 2", count)
+	}
+
+	// An open bound leaves that side unfiltered.
+	if count, err := repo.CountJokesFiltered(ctx, JokeFilter{CreatedFrom: from}); err != nil || count != 3 {
+		t.Errorf("count from %v = %d, %v, want 3", from, count, err)
+	}
+	if count, err := repo.CountJokesFiltered(ctx, JokeFilter{}); err != nil || count != 4 {
+		t.Errorf("unfiltered count = %d, %v, want 4", count, err)
+	}
+}
+
+func TestEachJoke(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{})
+
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		id, err := repo.CreateJoke(ctx, &model.Joke{Text: fmt.Sprintf("joke %d", i)})
+		if err != nil {
+			t.Fatalf("creating joke: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	t.Run("visits every joke once", func(t *testing.T) {
+		var got []int64
+		err := repo.EachJoke(ctx, func(joke *model.Joke) error {
+			got = append(got, joke.ID)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("EachJoke: %v", err)
+		}
+		if !slices.Equal(got, ids) {
+			t.Errorf("visited %v, want %v", got, ids)
+		}
+	})
+
+	t.Run("stops on callback error", func(t *testing.T) {
+		errStop := errors.New("stop")
+
+		calls := 0
+		err := repo.EachJoke(ctx, func(joke *model.Joke) error {
+			calls++
+			if calls == 2 {
+				return errStop
+			}
+			return nil
+		})
+		if !errors.Is(err, errStop) {
+			t.Errorf("err = %v, want the callback's error", err)
+		}
+		if calls != 2 {
+			t.Errorf("callback called %d times, want 2", calls)
+		}
+	})
+
+	t.Run("stops on cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		calls := 0
+		err := repo.EachJoke(ctx, func(joke *model.Joke) error {
+			calls++
+			cancel()
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+		if calls != 1 {
+			t.Errorf("callback called %d times, want 1", calls)
+		}
+	})
+}
+
+func TestListJokesFilteredByRegex(t *testing.T) {
+	for _, opts := range []Options{{}, {CompressText: true}} {
+		t.Run(fmt.Sprintf("compress=%t", opts.CompressText), func(t *testing.T) {
+			ctx := context.Background()
+			repo := newTestRepository(t, opts)
+
+			var matching []int64
+			for i := 0; i < 10; i++ {
+				text := fmt.Sprintf("joke %d", i)
+				if i%3 == 0 {
+					text = fmt.Sprintf("knock knock %d", i)
+				}
+
+				id, err := repo.CreateJoke(ctx, &model.Joke{Text: text})
+				if err != nil {
+					t.Fatalf("creating joke: %v", err)
+				}
+				if i%3 == 0 {
+					matching = append(matching, id)
+				}
+			}
+
+			filter := JokeFilter{Regex: regexp.MustCompile(`^knock`)}
+			tests := []struct {
+				limit, offset int
+				want          []int64
+			}{
+				{limit: 10, offset: 0, want: matching},
+				{limit: 2, offset: 0, want: matching[:2]},
+				{limit: 2, offset: 2, want: matching[2:]},
+				{limit: 2, offset: 4, want: nil},
+			}
+
+			for _, tt := range tests {
+				jokes, err := repo.ListJokesFiltered(ctx, filter, tt.limit, tt.offset)
+				if err != nil {
+					t.Fatalf("ListJokesFiltered: %v", err)
+				}
+
+				var got []int64
+				for _, joke := range jokes {
+					got = append(got, joke.ID)
+				}
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("limit %d, offset %d: got %v, want %v", tt.limit, tt.offset, got, tt.want)
+				}
+			}
+
+			if count, err := repo.CountJokesFiltered(ctx, filter); err != nil || count != len(matching) {
+				t.Errorf("count = %d, %v, want %d", count, err, len(matching))
+			}
+
+			// The regex narrows a created_at range rather than replacing it.
+			from := time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)
+			if _, err := repo.db.ExecContext(ctx, `UPDATE jokes SET created_at = ?`, from.Add(-time.Hour)); err != nil {
+				t.Fatalf("backdating jokes: %v", err)
+			}
+			if _, err := repo.db.ExecContext(ctx, `UPDATE jokes SET created_at = ? WHERE id IN (?, ?)`, from, matching[0], matching[0]+1); err != nil {
+				t.Fatalf("dating jokes: %v", err)
+			}
+			filter.CreatedFrom = from
+			jokes, err := repo.ListJokesFiltered(ctx, filter, 10, 0)
+			if err != nil || len(jokes) != 1 || jokes[0].ID != matching[0] {
+				t.Errorf("regex and created range = %v, %v, want only joke %d", jokes, err, matching[0])
+			}
+			if count, err := repo.CountJokesFiltered(ctx, filter); err != nil || count != 1 {
+				t.Errorf("regex and created range count = %d, %v, want 1", count, err)
+			}
+		})
+	}
+}
+
+func TestCreateJokeReportsUniqueViolation(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{})
+
+	id, err := repo.CreateJoke(ctx, &model.Joke{Text: "once"})
+	if err != nil {
+		t.Fatalf("creating joke: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		id   int64
+		text string
+	}{
+		{"taken ID", id, "twice"},
+		{"taken text", id + 1, "ONCE "},
+	}
+
+	for _, tt := range tests {
+		err := repo.CreateJokeWithID(ctx, tt.id, &model.Joke{Text: tt.text})
+
+		var constraintErr *ConstraintError
+		if !errors.As(err, &constraintErr) {
+			t.Fatalf("%s: CreateJokeWithID error = %v, want a ConstraintError", tt.name, err)
+		}
+		if constraintErr.Kind != ConstraintUnique {
+			t.Errorf("%s: constraint kind = %v, want %v", tt.name, constraintErr.Kind, ConstraintUnique)
+		}
+	}
+}
+
+func TestCreateJokeReturnsExisting(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{})
+
+	id, err := repo.CreateJoke(ctx, &model.Joke{Text: "Why did the chicken cross the road?"})
+	if err != nil {
+		t.Fatalf("creating joke: %v", err)
+	}
+
+	again, err := repo.CreateJoke(ctx, &model.Joke{Text: "  why did the CHICKEN cross the road? "})
+	if err != nil {
+		t.Fatalf("creating duplicate: %v", err)
+	}
+	if again != id {
+		t.Errorf("duplicate CreateJoke = %d, want the existing joke %d", again, id)
+	}
+
+	if count, err := repo.CountJokes(ctx); err != nil || count != 1 {
+		t.Errorf("CountJokes = %d, %v, want 1", count, err)
+	}
+}
+
+func TestCreateJokeRespectsMaxJokes(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{MaxJokes: 3})
+
+	var lastID int64
+	for i := 0; i < 3; i++ {
+		id, err := repo.CreateJoke(ctx, &model.Joke{Text: fmt.Sprintf("joke %d", i)})
+		if err != nil {
+			t.Fatalf("creating joke %d: %v", i, err)
+		}
+		lastID = id
+	}
+
+	if _, err := repo.CreateJoke(ctx, &model.Joke{Text: "one too many"}); !errors.Is(err, ErrJokeLimitReached) {
+		t.Fatalf("CreateJoke past the limit = %v, want ErrJokeLimitReached", err)
+	}
+
+	// A batch that would cross the limit is rolled back entirely, while
+	// jokes that already exist don't count against it.
+	if err := repo.DeleteJoke(ctx, lastID); err != nil {
+		t.Fatalf("deleting joke: %v", err)
+	}
+
+	_, err := repo.GetOrCreateJokes(ctx, []*model.Joke{{Text: "joke 0"}, {Text: "new 1"}, {Text: "new 2"}})
+	if !errors.Is(err, ErrJokeLimitReached) {
+		t.Fatalf("GetOrCreateJokes past the limit = %v, want ErrJokeLimitReached", err)
+	}
+
+	count, err := repo.CountJokes(ctx)
+	if err != nil {
+		t.Fatalf("counting jokes: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("count after rejected batch = %d, want 2", count)
+	}
+
+	if _, err := repo.GetOrCreateJokes(ctx, []*model.Joke{{Text: "joke 0"}, {Text: "new 1"}}); err != nil {
+		t.Errorf("GetOrCreateJokes up to the limit: %v", err)
+	}
+}
+
+func TestGetOrCreateJokesConcurrentDuplicates(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{})
+
+	const workers = 16
+	var wg sync.WaitGroup
+	ids := make([]int64, workers)
+	errs := make([]error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// Differently spaced and cased, but the same normalized text.
+			text := "Why did the chicken cross the road?"
+			if i%2 == 1 {
+				text = "  why did the CHICKEN cross the road? "
+			}
+
+			results, err := repo.GetOrCreateJokes(ctx, []*model.Joke{{Text: text}})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			ids[i] = results[0].Joke.ID
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("worker %d: GetOrCreateJokes: %v", i, err)
+		}
+	}
+
+	count, err := repo.CountJokes(ctx)
+	if err != nil {
+		t.Fatalf("counting jokes: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("count = %d after concurrent identical creates, want 1", count)
+	}
+
+	for i, id := range ids {
+		if id != ids[0] {
+			t.Errorf("worker %d got joke %d, worker 0 got %d", i, id, ids[0])
+		}
+	}
+}
+
+func TestJokesChecksumChangesOnWrites(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{})
+
+	checksum := func() string {
+		t.Helper()
+		sum, _, err := repo.JokesChecksum(ctx)
+		if err != nil {
+			t.Fatalf("JokesChecksum: %v", err)
+		}
+		return sum
+	}
+
+	seen := map[string]string{checksum(): "empty"}
+	expectChange := func(after string) {
+		t.Helper()
+		sum := checksum()
+		if prev, ok := seen[sum]; ok {
+			t.Fatalf("checksum after %s equals the one after %s", after, prev)
+		}
+		seen[sum] = after
+	}
+
+	id, err := repo.CreateJoke(ctx, &model.Joke{Text: "first"})
+	if err != nil {
+		t.Fatalf("creating joke: %v", err)
+	}
+	expectChange("create")
+
+	if _, err := repo.CreateJoke(ctx, &model.Joke{Text: "second"}); err != nil {
+		t.Fatalf("creating joke: %v", err)
+	}
+	expectChange("second create")
+
+	if checksum() != checksum() {
+		t.Fatal("checksum changed without a write")
+	}
+
+	if err := repo.UpdateJoke(ctx, &model.Joke{ID: id, Text: "first, edited"}); err != nil {
+		t.Fatalf("updating joke: %v", err)
+	}
+	expectChange("update")
+
+	if err := repo.SetJokeFeatured(ctx, id, true); err != nil {
+		t.Fatalf("featuring joke: %v", err)
+	}
+	expectChange("feature")
+
+	if err := repo.DeleteJoke(ctx, id); err != nil {
+		t.Fatalf("deleting joke: %v", err)
+	}
+	expectChange("delete")
+}
+
+func TestJokesChecksumIgnoresInsertOrder(t *testing.T) {
+	ctx := context.Background()
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var sums []string
+	for _, ids := range [][]int64{{1, 2, 3}, {3, 1, 2}} {
+		repo := newTestRepository(t, Options{})
+		for _, id := range ids {
+			_, err := repo.db.ExecContext(ctx,
+				`INSERT INTO jokes (id, text, created_at, updated_at) VALUES (?, ?, ?, ?)`,
+				id, fmt.Sprintf("joke %d", id), updatedAt, updatedAt.Add(time.Duration(id)*time.Minute))
+			if err != nil {
+				t.Fatalf("inserting joke: %v", err)
+			}
+		}
+
+		sum, count, err := repo.JokesChecksum(ctx)
+		if err != nil {
+			t.Fatalf("JokesChecksum: %v", err)
+		}
+		if count != 3 {
+			t.Errorf("count = %d, want 3", count)
+		}
+		sums = append(sums, sum)
+	}
+
+	if sums[0] != sums[1] {
+		t.Errorf("checksums of identical datasets differ: %s and %s", sums[0], sums[1])
+	}
+}
+
+func TestIncrementAndGet(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{})
+
+	id, err := repo.CreateJoke(ctx, &model.Joke{Text: "counted joke"})
+	if err != nil {
+		t.Fatalf("creating joke: %v", err)
+	}
+
+	// counter reads the field back from a joke, so each whitelisted field
+	// must have an entry here.
+	counters := map[string]func(*model.Joke) int64{
+		"view_count": func(joke *model.Joke) int64 { return joke.ViewCount },
+	}
+
+	for field := range counterColumns {
+		counter, ok := counters[field]
+		if !ok {
+			t.Errorf("no test accessor for counter field %q", field)
+			continue
+		}
+
+		t.Run(field, func(t *testing.T) {
+			const workers = 8
+			var wg sync.WaitGroup
+			errs := make([]error, workers)
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, errs[i] = repo.IncrementAndGet(ctx, id, field, 2)
+				}()
+			}
+			wg.Wait()
+
+			for i, err := range errs {
+				if err != nil {
+					t.Fatalf("worker %d: IncrementAndGet: %v", i, err)
+				}
+			}
+
+			joke, err := repo.IncrementAndGet(ctx, id, field, -1)
+			if err != nil {
+				t.Fatalf("IncrementAndGet: %v", err)
+			}
+			if got := counter(joke); got != 2*workers-1 {
+				t.Errorf("%s = %d, want %d", field, got, 2*workers-1)
+			}
+			if joke.ID != id || joke.Text != "counted joke" {
+				t.Errorf("returned joke %d %q, want the updated row", joke.ID, joke.Text)
+			}
+		})
+	}
+
+	for _, field := range []string{"text", "id", "view_count = 0; --", ""} {
+		if _, err := repo.IncrementAndGet(ctx, id, field, 1); !errors.Is(err, ErrInvalidCounterField) {
+			t.Errorf("IncrementAndGet(%q): err = %v, want ErrInvalidCounterField", field, err)
+		}
+	}
+
+	if _, err := repo.IncrementAndGet(ctx, id+100, "view_count", 1); !errors.Is(err, ErrJokeNotFound) {
+		t.Errorf("IncrementAndGet on a missing joke: err = %v, want ErrJokeNotFound", err)
+	}
+}
+
+func TestJokeLengthPercentiles(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{})
+
+	stats, err := repo.JokeLengthPercentiles(ctx)
+	if err != nil {
+		t.Fatalf("JokeLengthPercentiles on an empty table: %v", err)
+	}
+	if *stats != (model.LengthPercentiles{}) {
+		t.Errorf("empty table stats = %+v, want zeros", *stats)
+	}
+
+	// Lengths 1 to 100, inserted out of order. Multi-byte characters count
+	// once.
+	for _, n := range rand.Perm(100) {
+		if _, err := repo.CreateJoke(ctx, &model.Joke{Text: strings.Repeat("ü", n+1)}); err != nil {
+			t.Fatalf("creating joke: %v", err)
+		}
+	}
+
+	stats, err = repo.JokeLengthPercentiles(ctx)
+	if err != nil {
+		t.Fatalf("JokeLengthPercentiles: %v", err)
+	}
+	want := model.LengthPercentiles{Count: 100, Min: 1, P50: 50, P90: 90, P99: 99, Max: 100}
+	if *stats != want {
+		t.Errorf("stats = %+v, want %+v", *stats, want)
+	}
+
+	// With 10 jokes, the nearest ranks of p90 and p99 are 9 and 10.
+	repo = newTestRepository(t, Options{})
+	for n := 1; n <= 10; n++ {
+		if _, err := repo.CreateJoke(ctx, &model.Joke{Text: strings.Repeat("x", n*10)}); err != nil {
+			t.Fatalf("creating joke: %v", err)
+		}
+	}
+
+	stats, err = repo.JokeLengthPercentiles(ctx)
+	if err != nil {
+		t.Fatalf("JokeLengthPercentiles: %v", err)
+	}
+	want = model.LengthPercentiles{Count: 10, Min: 10, P50: 50, P90: 90, P99: 100, Max: 100}
+	if *stats != want {
+		t.Errorf("stats = %+v, want %+v", *stats, want)
+	}
+}
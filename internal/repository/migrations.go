@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+type migration struct {
+	version    int
+	name       string
+	statements []string
+}
+
+// migrations are applied in order on startup. Append new migrations to the
+// end and never edit one that has already been released.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "add_jokes_public_id",
+		statements: []string{
+			`ALTER TABLE jokes ADD COLUMN public_id TEXT`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_jokes_public_id ON jokes (public_id)`,
+		},
+	},
+	{
+		version: 2,
+		name:    "add_jokes_featured",
+		statements: []string{
+			`ALTER TABLE jokes ADD COLUMN featured BOOLEAN NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version: 3,
+		name:    "create_collections",
+		statements: []string{
+			`CREATE TABLE collections (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL,
+				description TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			)`,
+			`CREATE TABLE collection_jokes (
+				collection_id INTEGER NOT NULL REFERENCES collections (id) ON DELETE CASCADE,
+				joke_id INTEGER NOT NULL REFERENCES jokes (id) ON DELETE CASCADE,
+				position INTEGER NOT NULL,
+				added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (collection_id, joke_id)
+			)`,
+			`CREATE INDEX idx_collection_jokes_position ON collection_jokes (collection_id, position)`,
+		},
+	},
+	{
+		version: 4,
+		name:    "add_jokes_last_accessed_at",
+		statements: []string{
+			`ALTER TABLE jokes ADD COLUMN last_accessed_at TIMESTAMP`,
+		},
+	},
+	{
+		version: 5,
+		name:    "add_jokes_setup_punchline",
+		statements: []string{
+			`ALTER TABLE jokes ADD COLUMN setup TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE jokes ADD COLUMN punchline TEXT NOT NULL DEFAULT ''`,
+			`UPDATE jokes SET setup = text`,
+		},
+	},
+	{
+		version: 6,
+		name:    "add_jokes_text_hash",
+		statements: []string{
+			`ALTER TABLE jokes ADD COLUMN text_hash TEXT`,
+			`CREATE INDEX idx_jokes_text_hash ON jokes (text_hash)`,
+		},
+	},
+	{
+		version: 7,
+		name:    "add_jokes_text_length",
+		statements: []string{
+			`ALTER TABLE jokes ADD COLUMN text_length INTEGER`,
+			`CREATE INDEX idx_jokes_text_length ON jokes (text_length)`,
+		},
+	},
+	{
+		version: 8,
+		name:    "add_jokes_view_count",
+		statements: []string{
+			`ALTER TABLE jokes ADD COLUMN view_count INTEGER NOT NULL DEFAULT 0`,
+			`CREATE INDEX idx_jokes_view_count ON jokes (view_count)`,
+		},
+	},
+	{
+		version: 9,
+		name:    "add_jokes_created_at_index",
+		statements: []string{
+			`CREATE INDEX idx_jokes_created_at ON jokes (created_at, id)`,
+		},
+	},
+	{
+		version: 10,
+		name:    "create_feature_flags",
+		statements: []string{
+			// Databases from before this migration already have the table.
+			`CREATE TABLE IF NOT EXISTS feature_flags (
+				name TEXT PRIMARY KEY,
+				enabled BOOLEAN NOT NULL DEFAULT 0,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			)`,
+			// GET /api/joke/{id}/tell shipped before it was put behind a
+			// flag, so it stays on unless an admin has turned it off.
+			`INSERT OR IGNORE INTO feature_flags (name, enabled) VALUES ('tell_jokes', 1)`,
+		},
+	},
+}
+
+// migrate applies every migration that hasn't been recorded in
+// schema_migrations yet, each one in its own transaction.
+func migrate(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("error creating schema_migrations table: %w", err)
+	}
+
+	for _, m := range migrations {
+		var applied bool
+		err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = ?)`, m.version).Scan(&applied)
+		if err != nil {
+			return fmt.Errorf("error checking migration %d: %w", m.version, err)
+		}
+
+		if applied {
+			continue
+		}
+
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("error applying migration %d (%s): %w", m.version, m.name, err)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range m.statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+		m.version,
+		m.name,
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListAppliedMigrations returns the migrations recorded in schema_migrations,
+// oldest first.
+func (r *SQLiteJokeRepository) ListAppliedMigrations(ctx context.Context) ([]*model.SchemaMigration, error) {
+	query := `
+		SELECT version, name, applied_at
+		FROM schema_migrations
+		ORDER BY version
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error listing migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make([]*model.SchemaMigration, 0)
+	for rows.Next() {
+		m := &model.SchemaMigration{}
+		if err := rows.Scan(&m.Version, &m.Name, &m.AppliedAt); err != nil {
+			return nil, fmt.Errorf("error scanning migration: %w", err)
+		}
+		applied = append(applied, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing migrations: %w", err)
+	}
+
+	return applied, nil
+}
+
+// LatestMigrationVersion is the version of the newest known migration, which
+// a fully migrated database has applied.
+func LatestMigrationVersion() int {
+	return migrations[len(migrations)-1].version
+}
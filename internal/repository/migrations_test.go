@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"maps"
+	"path/filepath"
+	"testing"
+)
+
+func TestListAppliedMigrationsReportsLatest(t *testing.T) {
+	repo := newTestRepository(t, Options{})
+
+	applied, err := repo.ListAppliedMigrations(context.Background())
+	if err != nil {
+		t.Fatalf("listing migrations: %v", err)
+	}
+
+	if len(applied) != len(migrations) {
+		t.Fatalf("got %d applied migrations, want %d", len(applied), len(migrations))
+	}
+
+	latest := migrations[len(migrations)-1]
+	if got := applied[len(applied)-1]; got.Version != latest.version || got.Name != latest.name {
+		t.Errorf("latest applied = %d %s, want %d %s", got.Version, got.Name, latest.version, latest.name)
+	}
+	for _, m := range applied {
+		if m.AppliedAt.IsZero() {
+			t.Errorf("migration %d has no applied_at", m.Version)
+		}
+	}
+}
+
+func TestMigrateKeepsExistingFeatureFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jokes.db")
+
+	// A database whose feature_flags table was created before it had a
+	// migration, with the tell_jokes flag turned off.
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE jokes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			text TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE feature_flags (
+			name TEXT PRIMARY KEY,
+			enabled BOOLEAN NOT NULL DEFAULT 0,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`INSERT INTO feature_flags (name, enabled) VALUES ('tell_jokes', 0), ('debug_responses', 1)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("creating old schema: %v", err)
+		}
+	}
+	db.Close()
+
+	repo, err := NewSQLiteJokeRepository(path, Options{})
+	if err != nil {
+		t.Fatalf("opening old database: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	assertFlags(t, repo, map[string]bool{"debug_responses": true, "tell_jokes": false})
+
+	// A fresh database starts with tell_jokes on.
+	assertFlags(t, newTestRepository(t, Options{}), map[string]bool{"tell_jokes": true})
+}
+
+func assertFlags(t *testing.T, repo *SQLiteJokeRepository, want map[string]bool) {
+	t.Helper()
+
+	flags, err := repo.ListFeatureFlags(context.Background())
+	if err != nil {
+		t.Fatalf("listing feature flags: %v", err)
+	}
+
+	got := make(map[string]bool)
+	for _, flag := range flags {
+		got[flag.Name] = flag.Enabled
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("flags = %v, want %v", got, want)
+	}
+}
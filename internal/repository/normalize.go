@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// NormalizeText folds case and collapses whitespace, so texts that only
+// differ in those respects compare equal.
+func NormalizeText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// textHash is stored in jokes.text_hash to look jokes up by normalized text.
+// Hashing keeps the indexed column small no matter how long, or compressed,
+// the text is.
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(NormalizeText(text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// backfillTextHashes computes the text hash of jokes stored before the
+// column existed.
+func backfillTextHashes(db *sql.DB) error {
+	return backfillFromText(db, "text_hash", func(text string) any {
+		return textHash(text)
+	})
+}
+
+// backfillFromText sets column, which must be derived from the joke text,
+// on every joke where it is still NULL. The text is decoded in Go because
+// it may be stored compressed.
+func backfillFromText(db *sql.DB, column string, value func(text string) any) error {
+	rows, err := db.Query(`SELECT id, text FROM jokes WHERE ` + column + ` IS NULL`)
+	if err != nil {
+		return fmt.Errorf("error finding jokes without %s: %w", column, err)
+	}
+
+	values := make(map[int64]any)
+	for rows.Next() {
+		var id int64
+		var stored []byte
+		if err := rows.Scan(&id, &stored); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning joke: %w", err)
+		}
+
+		text, err := decodeText(stored)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		values[id] = value(text)
+	}
+	rows.Close()
+
+	for id, v := range values {
+		if _, err := db.Exec(`UPDATE jokes SET `+column+` = ? WHERE id = ?`, v, id); err != nil {
+			return fmt.Errorf("error assigning %s: %w", column, err)
+		}
+	}
+
+	return nil
+}
+
+// textLength is stored in jokes.text_length, the length of the text in
+// characters, so length filters work on compressed text too.
+func textLength(text string) int {
+	return utf8.RuneCountInString(text)
+}
+
+// backfillTextLengths computes the text length of jokes stored before the
+// column existed.
+func backfillTextLengths(db *sql.DB) error {
+	return backfillFromText(db, "text_length", func(text string) any {
+		return textLength(text)
+	})
+}
+
+// NormalizeProgress counts the jokes RenormalizeJokes has looked at so far,
+// and how many of them it had to fix.
+type NormalizeProgress struct {
+	Scanned int `json:"scanned"`
+	Updated int `json:"updated"`
+	Batches int `json:"batches"`
+}
+
+// RenormalizeJokes recomputes the columns derived from the joke text,
+// text_hash and text_length, for every joke. Unlike the backfill at startup
+// it also fixes values that are set but stale, for example after the
+// normalization rules changed. Jokes are processed in batches of batchSize
+// in ID order, each in its own transaction, so writers are never blocked for
+// long. progress, if not nil, is called after every batch.
+func (r *SQLiteJokeRepository) RenormalizeJokes(ctx context.Context, batchSize int, progress func(NormalizeProgress)) (NormalizeProgress, error) {
+	var done NormalizeProgress
+
+	var lastID int64
+	for {
+		n, updated, next, err := r.renormalizeBatch(ctx, lastID, batchSize)
+		if err != nil {
+			return done, err
+		}
+		if n == 0 {
+			return done, nil
+		}
+
+		done.Scanned += n
+		done.Updated += updated
+		done.Batches++
+		lastID = next
+
+		if progress != nil {
+			progress(done)
+		}
+	}
+}
+
+// renormalizeBatch fixes the derived columns of up to limit jokes with an ID
+// above afterID. It returns how many jokes it looked at, how many it
+// updated and the highest ID it saw.
+//
+// The jokes are read before the transaction starts, so it takes the write
+// lock with its first statement instead of upgrading a read lock, which
+// fails when another write landed in between. Each update only applies if
+// the stored values are still the ones read, so a joke edited meanwhile,
+// which got fresh values from the edit, is left alone.
+func (r *SQLiteJokeRepository) renormalizeBatch(ctx context.Context, afterID int64, limit int) (scanned, updated int, lastID int64, err error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, text, text_hash, text_length
+		FROM jokes
+		WHERE id > ?
+		ORDER BY id
+		LIMIT ?
+	`, afterID, limit)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("error listing jokes: %w", err)
+	}
+
+	type fix struct {
+		id                int64
+		oldHash, hash     sql.NullString
+		oldLength, length sql.NullInt64
+	}
+	var fixes []fix
+
+	for rows.Next() {
+		var (
+			f      fix
+			stored []byte
+		)
+		if err := rows.Scan(&f.id, &stored, &f.oldHash, &f.oldLength); err != nil {
+			rows.Close()
+			return 0, 0, 0, fmt.Errorf("error scanning joke: %w", err)
+		}
+
+		text, err := decodeText(stored)
+		if err != nil {
+			rows.Close()
+			return 0, 0, 0, err
+		}
+
+		scanned++
+		lastID = f.id
+
+		f.hash = sql.NullString{String: textHash(text), Valid: true}
+		f.length = sql.NullInt64{Int64: int64(textLength(text)), Valid: true}
+		if f.hash != f.oldHash || f.length != f.oldLength {
+			fixes = append(fixes, f)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, 0, 0, fmt.Errorf("error listing jokes: %w", err)
+	}
+	rows.Close()
+
+	if len(fixes) == 0 {
+		return scanned, 0, lastID, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE jokes
+		SET text_hash = ?, text_length = ?
+		WHERE id = ? AND text_hash IS ? AND text_length IS ?
+	`
+	for _, f := range fixes {
+		result, err := tx.ExecContext(ctx, query, f.hash, f.length, f.id, f.oldHash, f.oldLength)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("error normalizing joke: %w", err)
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			updated += int(n)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, 0, fmt.Errorf("error committing batch: %w", err)
+	}
+
+	return scanned, updated, lastID, nil
+}
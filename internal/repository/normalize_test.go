@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func TestRenormalizeJokesFixesLegacyRows(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{})
+
+	texts := []string{"Knock  knock", "Who's THERE?", "A joke", "Another joke", "The last joke"}
+	var ids []int64
+	for _, text := range texts {
+		id, err := repo.CreateJoke(ctx, &model.Joke{Text: text})
+		if err != nil {
+			t.Fatalf("creating joke: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	// Legacy rows: two never had the derived columns, one has a stale hash.
+	for _, id := range ids[:2] {
+		if _, err := repo.db.ExecContext(ctx, `UPDATE jokes SET text_hash = NULL, text_length = NULL WHERE id = ?`, id); err != nil {
+			t.Fatalf("clearing columns: %v", err)
+		}
+	}
+	if _, err := repo.db.ExecContext(ctx, `UPDATE jokes SET text_hash = 'stale' WHERE id = ?`, ids[3]); err != nil {
+		t.Fatalf("staling hash: %v", err)
+	}
+
+	var reports []NormalizeProgress
+	done, err := repo.RenormalizeJokes(ctx, 2, func(p NormalizeProgress) {
+		reports = append(reports, p)
+	})
+	if err != nil {
+		t.Fatalf("RenormalizeJokes: %v", err)
+	}
+
+	if want := (NormalizeProgress{Scanned: 5, Updated: 3, Batches: 3}); done != want {
+		t.Errorf("result = %+v, want %+v", done, want)
+	}
+	if len(reports) != 3 || reports[0].Scanned != 2 || reports[2] != done {
+		t.Errorf("progress reports = %+v, want one per batch ending with the result", reports)
+	}
+
+	for i, id := range ids {
+		var hash sql.NullString
+		var length sql.NullInt64
+		if err := repo.db.QueryRowContext(ctx, `SELECT text_hash, text_length FROM jokes WHERE id = ?`, id).Scan(&hash, &length); err != nil {
+			t.Fatalf("reading joke %d: %v", id, err)
+		}
+		if hash.String != textHash(texts[i]) || length.Int64 != int64(textLength(texts[i])) {
+			t.Errorf("joke %d: hash, length = %q, %v, want %q, %d", id, hash.String, length, textHash(texts[i]), textLength(texts[i]))
+		}
+	}
+
+	// Lookups by normalized text find the fixed rows again.
+	jokes, err := repo.FindJokesByText(ctx, "knock knock")
+	if err != nil || len(jokes) != 1 || jokes[0].ID != ids[0] {
+		t.Errorf("FindJokesByText = %v, %v, want joke %d", jokes, err, ids[0])
+	}
+
+	again, err := repo.RenormalizeJokes(ctx, 2, nil)
+	if err != nil {
+		t.Fatalf("RenormalizeJokes again: %v", err)
+	}
+	if again.Updated != 0 {
+		t.Errorf("second run updated %d jokes, want 0", again.Updated)
+	}
+}
+
+func TestRenormalizeJokesEmpty(t *testing.T) {
+	repo := newTestRepository(t, Options{})
+
+	done, err := repo.RenormalizeJokes(context.Background(), 10, func(p NormalizeProgress) {
+		t.Errorf("progress reported for an empty table: %+v", p)
+	})
+	if err != nil {
+		t.Fatalf("RenormalizeJokes: %v", err)
+	}
+	if done != (NormalizeProgress{}) {
+		t.Errorf("result = %+v, want nothing done", done)
+	}
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// MaintenanceRepository holds housekeeping operations that keep the
+// database consistent.
+type MaintenanceRepository interface {
+	DeleteOrphanedRows(ctx context.Context) (map[string]int64, error)
+	ListAppliedMigrations(ctx context.Context) ([]*model.SchemaMigration, error)
+	RenormalizeJokes(ctx context.Context, batchSize int, progress func(NormalizeProgress)) (NormalizeProgress, error)
+}
+
+// orphanQueries delete the rows of each join table that point at a parent
+// row that no longer exists. Foreign keys prevent new orphans, but rows
+// written before they were enforced can still be around.
+var orphanQueries = []struct {
+	table string
+	query string
+}{
+	{
+		table: "collection_jokes",
+		query: `
+			DELETE FROM collection_jokes
+			WHERE joke_id NOT IN (SELECT id FROM jokes)
+				OR collection_id NOT IN (SELECT id FROM collections)
+		`,
+	},
+}
+
+// DeleteOrphanedRows removes orphaned join table rows and returns how many
+// rows were deleted per table.
+func (r *SQLiteJokeRepository) DeleteOrphanedRows(ctx context.Context) (map[string]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	deleted := make(map[string]int64, len(orphanQueries))
+	for _, q := range orphanQueries {
+		result, err := tx.ExecContext(ctx, q.query)
+		if err != nil {
+			return nil, fmt.Errorf("error deleting orphaned %s rows: %w", q.table, err)
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("error getting rows affected: %w", err)
+		}
+		deleted[q.table] = n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func TestDeleteOrphanedRows(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, Options{})
+
+	collectionID, err := repo.CreateCollection(ctx, &model.Collection{Name: "favorites"})
+	if err != nil {
+		t.Fatalf("creating collection: %v", err)
+	}
+
+	var jokeIDs []int64
+	for _, text := range []string{"kept", "orphaned", "cascaded"} {
+		id, err := repo.CreateJoke(ctx, &model.Joke{Text: text})
+		if err != nil {
+			t.Fatalf("creating joke: %v", err)
+		}
+		if err := repo.AddJokeToCollection(ctx, collectionID, id); err != nil {
+			t.Fatalf("adding joke to collection: %v", err)
+		}
+		jokeIDs = append(jokeIDs, id)
+	}
+
+	// Delete a joke behind the foreign keys' back to leave an orphan.
+	conn, err := repo.db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("getting connection: %v", err)
+	}
+	if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+		t.Fatalf("disabling foreign keys: %v", err)
+	}
+	if _, err := conn.ExecContext(ctx, `DELETE FROM jokes WHERE id = ?`, jokeIDs[1]); err != nil {
+		t.Fatalf("deleting joke: %v", err)
+	}
+	if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = ON`); err != nil {
+		t.Fatalf("enabling foreign keys: %v", err)
+	}
+	conn.Close()
+
+	// With foreign keys enforced, a regular delete cascades instead.
+	if err := repo.DeleteJoke(ctx, jokeIDs[2]); err != nil {
+		t.Fatalf("deleting joke: %v", err)
+	}
+
+	deleted, err := repo.DeleteOrphanedRows(ctx)
+	if err != nil {
+		t.Fatalf("deleting orphaned rows: %v", err)
+	}
+	if deleted["collection_jokes"] != 1 {
+		t.Errorf("deleted %d collection_jokes rows, want 1", deleted["collection_jokes"])
+	}
+
+	count, err := repo.CountCollectionJokes(ctx, collectionID)
+	if err != nil {
+		t.Fatalf("counting collection jokes: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("collection has %d jokes, want 1", count)
+	}
+
+	deleted, err = repo.DeleteOrphanedRows(ctx)
+	if err != nil {
+		t.Fatalf("deleting orphaned rows again: %v", err)
+	}
+	if deleted["collection_jokes"] != 0 {
+		t.Errorf("second run deleted %d rows, want 0", deleted["collection_jokes"])
+	}
+}
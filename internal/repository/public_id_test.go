@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestMigrateBackfillsPublicIDs(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "jokes.db")
+
+	// A database from before public IDs and the migration runner existed.
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	_, err = db.Exec(`CREATE TABLE jokes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		text TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		t.Fatalf("creating old schema: %v", err)
+	}
+	for _, text := range []string{"first old joke", "second old joke"} {
+		if _, err := db.Exec(`INSERT INTO jokes (text) VALUES (?)`, text); err != nil {
+			t.Fatalf("inserting joke: %v", err)
+		}
+	}
+	db.Close()
+
+	repo, err := NewSQLiteJokeRepository(path, Options{PublicIDs: true})
+	if err != nil {
+		t.Fatalf("opening old database: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	jokes, err := repo.ListJokes(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("listing jokes: %v", err)
+	}
+	if len(jokes) != 2 {
+		t.Fatalf("got %d jokes after migrating, want 2", len(jokes))
+	}
+
+	seen := make(map[string]bool)
+	for _, joke := range jokes {
+		if _, err := uuid.Parse(joke.PublicID); err != nil {
+			t.Fatalf("joke %d has public ID %q: %v", joke.ID, joke.PublicID, err)
+		}
+		if seen[joke.PublicID] {
+			t.Errorf("public ID %s assigned twice", joke.PublicID)
+		}
+		seen[joke.PublicID] = true
+
+		id, err := repo.ResolvePublicID(ctx, joke.PublicID)
+		if err != nil {
+			t.Fatalf("resolving %s: %v", joke.PublicID, err)
+		}
+		if id != joke.ID {
+			t.Errorf("%s resolved to %d, want %d", joke.PublicID, id, joke.ID)
+		}
+	}
+}
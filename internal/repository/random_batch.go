@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// RandomBatchJokeRepository is a JokeRepository decorator that coalesces
+// GetRandomJoke calls: it fetches a batch of distinct random jokes with one
+// GetRandomJokes call and hands them out one per call until the batch runs
+// out. Callers arriving while a batch is fetched wait for it instead of
+// querying themselves, so a burst of requests costs one query per batch
+// rather than one each.
+//
+// A batch is dropped once it is older than maxAge, and whenever a joke is
+// changed or deleted through the decorator, so it never serves jokes long
+// after they changed. All other calls go straight to the wrapped repository.
+type RandomBatchJokeRepository struct {
+	JokeRepository
+
+	size   int
+	maxAge time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	batch     []*model.Joke
+	fetchedAt time.Time
+}
+
+func NewRandomBatchJokeRepository(next JokeRepository, size int, maxAge time.Duration) *RandomBatchJokeRepository {
+	return &RandomBatchJokeRepository{
+		JokeRepository: next,
+		size:           size,
+		maxAge:         maxAge,
+		now:            time.Now,
+	}
+}
+
+func (r *RandomBatchJokeRepository) GetRandomJoke(ctx context.Context) (*model.Joke, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.batch) == 0 || r.now().Sub(r.fetchedAt) > r.maxAge {
+		// Holding r.mu across the query makes concurrent callers wait for
+		// this batch instead of fetching their own.
+		jokes, err := r.JokeRepository.GetRandomJokes(ctx, r.size)
+		if err != nil {
+			return nil, err
+		}
+		if len(jokes) == 0 {
+			r.batch = nil
+			return nil, ErrNoJokes
+		}
+
+		r.batch = jokes
+		r.fetchedAt = r.now()
+	}
+
+	joke := r.batch[len(r.batch)-1]
+	r.batch = r.batch[:len(r.batch)-1]
+	return joke, nil
+}
+
+func (r *RandomBatchJokeRepository) invalidate() {
+	r.mu.Lock()
+	r.batch = nil
+	r.mu.Unlock()
+}
+
+func (r *RandomBatchJokeRepository) UpdateJoke(ctx context.Context, joke *model.Joke) error {
+	err := r.JokeRepository.UpdateJoke(ctx, joke)
+	if err == nil {
+		r.invalidate()
+	}
+	return err
+}
+
+func (r *RandomBatchJokeRepository) DeleteJoke(ctx context.Context, id int64) error {
+	err := r.JokeRepository.DeleteJoke(ctx, id)
+	if err == nil {
+		r.invalidate()
+	}
+	return err
+}
+
+func (r *RandomBatchJokeRepository) ReplaceJokes(ctx context.Context, jokes []*model.Joke) error {
+	err := r.JokeRepository.ReplaceJokes(ctx, jokes)
+	if err == nil {
+		r.invalidate()
+	}
+	return err
+}
+
+func (r *RandomBatchJokeRepository) SetJokeFeatured(ctx context.Context, id int64, featured bool) error {
+	err := r.JokeRepository.SetJokeFeatured(ctx, id, featured)
+	if err == nil {
+		r.invalidate()
+	}
+	return err
+}
+
+func (r *RandomBatchJokeRepository) ResetJokeCounters(ctx context.Context, id int64) (*model.Joke, error) {
+	joke, err := r.JokeRepository.ResetJokeCounters(ctx, id)
+	if err == nil {
+		r.invalidate()
+	}
+	return joke, err
+}
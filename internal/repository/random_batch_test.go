@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// countingRepository counts the random joke queries that reach it.
+type countingRepository struct {
+	JokeRepository
+	calls atomic.Int64
+}
+
+func (c *countingRepository) GetRandomJoke(ctx context.Context) (*model.Joke, error) {
+	c.calls.Add(1)
+	return c.JokeRepository.GetRandomJoke(ctx)
+}
+
+func (c *countingRepository) GetRandomJokes(ctx context.Context, n int) ([]*model.Joke, error) {
+	c.calls.Add(1)
+	return c.JokeRepository.GetRandomJokes(ctx, n)
+}
+
+func TestRandomBatchCoalescesBursts(t *testing.T) {
+	ctx := context.Background()
+	sqlite := newTestRepository(t, Options{})
+	for i := 0; i < 30; i++ {
+		if _, err := sqlite.CreateJoke(ctx, &model.Joke{Text: fmt.Sprintf("joke %d", i)}); err != nil {
+			t.Fatalf("creating joke: %v", err)
+		}
+	}
+
+	counting := &countingRepository{JokeRepository: sqlite}
+	repo := NewRandomBatchJokeRepository(counting, 10, time.Minute)
+
+	const requests = 100
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = make(map[int64]int)
+	)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			joke, err := repo.GetRandomJoke(ctx)
+			if err != nil {
+				t.Errorf("GetRandomJoke: %v", err)
+				return
+			}
+
+			mu.Lock()
+			seen[joke.ID]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if calls := counting.calls.Load(); calls != requests/10 {
+		t.Errorf("%d queries for %d requests, want %d", calls, requests, requests/10)
+	}
+	if len(seen) < 10 {
+		t.Errorf("%d requests saw only %d distinct jokes", requests, len(seen))
+	}
+}
+
+func TestRandomBatchDropsStaleJokes(t *testing.T) {
+	ctx := context.Background()
+	sqlite := newTestRepository(t, Options{})
+
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		id, err := sqlite.CreateJoke(ctx, &model.Joke{Text: fmt.Sprintf("joke %d", i)})
+		if err != nil {
+			t.Fatalf("creating joke: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	counting := &countingRepository{JokeRepository: sqlite}
+	repo := NewRandomBatchJokeRepository(counting, 5, time.Minute)
+	now := time.Now()
+	repo.now = func() time.Time { return now }
+
+	if _, err := repo.GetRandomJoke(ctx); err != nil {
+		t.Fatalf("GetRandomJoke: %v", err)
+	}
+
+	// A delete through the decorator drops the batch, so the deleted joke
+	// is never served from it.
+	if err := repo.DeleteJoke(ctx, ids[0]); err != nil {
+		t.Fatalf("deleting joke: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		joke, err := repo.GetRandomJoke(ctx)
+		if err != nil {
+			t.Fatalf("GetRandomJoke: %v", err)
+		}
+		if joke.ID == ids[0] {
+			t.Fatalf("served deleted joke %d", ids[0])
+		}
+	}
+	if calls := counting.calls.Load(); calls != 2 {
+		t.Errorf("%d queries, want 2: one batch before and one after the delete", calls)
+	}
+
+	// An old batch is refetched even with jokes left in it.
+	if _, err := repo.GetRandomJoke(ctx); err != nil {
+		t.Fatalf("GetRandomJoke: %v", err)
+	}
+	now = now.Add(2 * time.Minute)
+	if _, err := repo.GetRandomJoke(ctx); err != nil {
+		t.Fatalf("GetRandomJoke: %v", err)
+	}
+	if calls := counting.calls.Load(); calls != 4 {
+		t.Errorf("%d queries, want 4 after the batch expired", calls)
+	}
+}
+
+func TestRandomBatchDropsBatchOnCounterReset(t *testing.T) {
+	ctx := context.Background()
+	sqlite := newTestRepository(t, Options{})
+
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		id, err := sqlite.CreateJoke(ctx, &model.Joke{Text: fmt.Sprintf("joke %d", i)})
+		if err != nil {
+			t.Fatalf("creating joke: %v", err)
+		}
+		if _, err := sqlite.IncrementAndGet(ctx, id, "view_count", 3); err != nil {
+			t.Fatalf("incrementing views: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	counting := &countingRepository{JokeRepository: sqlite}
+	repo := NewRandomBatchJokeRepository(counting, 3, time.Minute)
+	if _, err := repo.GetRandomJoke(ctx); err != nil {
+		t.Fatalf("GetRandomJoke: %v", err)
+	}
+
+	for _, id := range ids {
+		if _, err := repo.ResetJokeCounters(ctx, id); err != nil {
+			t.Fatalf("ResetJokeCounters: %v", err)
+		}
+	}
+
+	// The batch still held two jokes with their old view counts; the
+	// reset must drop it rather than serve them.
+	joke, err := repo.GetRandomJoke(ctx)
+	if err != nil {
+		t.Fatalf("GetRandomJoke: %v", err)
+	}
+	if joke.ViewCount != 0 {
+		t.Errorf("view count = %d after reset, want 0", joke.ViewCount)
+	}
+	if calls := counting.calls.Load(); calls != 2 {
+		t.Errorf("%d queries, want 2: one batch before and one after the reset", calls)
+	}
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// RandomIDCacheJokeRepository is a JokeRepository decorator that serves
+// GetRandomJoke from an in-memory list of joke IDs instead of an
+// ORDER BY RANDOM() scan: it picks an ID and fetches that joke by primary
+// key. Writes made through it mark the list stale so it is reloaded before
+// the next pick, and Run reloads it periodically to pick up writes made
+// elsewhere, such as by jokectl.
+//
+// All other calls go straight to the wrapped repository.
+type RandomIDCacheJokeRepository struct {
+	JokeRepository
+
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	ids   []int64
+	stale bool
+}
+
+func NewRandomIDCacheJokeRepository(next JokeRepository, logger *slog.Logger) *RandomIDCacheJokeRepository {
+	return &RandomIDCacheJokeRepository{
+		JokeRepository: next,
+		logger:         logger,
+		stale:          true,
+	}
+}
+
+// Refresh reloads the cached IDs.
+func (r *RandomIDCacheJokeRepository) Refresh(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.reload(ctx)
+}
+
+// reload must be called with r.mu held. Holding it across the query keeps a
+// write that lands during the reload from being overwritten by an older
+// list.
+func (r *RandomIDCacheJokeRepository) reload(ctx context.Context) error {
+	ids, err := r.JokeRepository.ListJokeIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.ids = ids
+	r.stale = false
+	return nil
+}
+
+func (r *RandomIDCacheJokeRepository) invalidate() {
+	r.mu.Lock()
+	r.stale = true
+	r.mu.Unlock()
+}
+
+// pick returns a random cached ID, reloading the list first when it is
+// stale. ok is false when there are no jokes.
+func (r *RandomIDCacheJokeRepository) pick(ctx context.Context) (id int64, ok bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stale {
+		if err := r.reload(ctx); err != nil {
+			return 0, false, err
+		}
+	}
+
+	if len(r.ids) == 0 {
+		return 0, false, nil
+	}
+
+	return r.ids[rand.IntN(len(r.ids))], true, nil
+}
+
+func (r *RandomIDCacheJokeRepository) GetRandomJoke(ctx context.Context) (*model.Joke, error) {
+	id, ok, err := r.pick(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNoJokes
+	}
+
+	joke, err := r.JokeRepository.GetJoke(ctx, id)
+	if errors.Is(err, ErrJokeNotFound) {
+		// Deleted behind the cache's back. Reload and try once more rather
+		// than failing the request.
+		r.invalidate()
+
+		id, ok, err = r.pick(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrNoJokes
+		}
+
+		joke, err = r.JokeRepository.GetJoke(ctx, id)
+	}
+
+	return joke, err
+}
+
+// pickN returns up to n distinct random cached IDs, reloading the list first
+// when it is stale.
+func (r *RandomIDCacheJokeRepository) pickN(ctx context.Context, n int) ([]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stale {
+		if err := r.reload(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	n = min(n, len(r.ids))
+	picked := make(map[int]bool, n)
+	ids := make([]int64, 0, n)
+	for len(ids) < n {
+		i := rand.IntN(len(r.ids))
+		if !picked[i] {
+			picked[i] = true
+			ids = append(ids, r.ids[i])
+		}
+	}
+
+	return ids, nil
+}
+
+func (r *RandomIDCacheJokeRepository) GetRandomJokes(ctx context.Context, n int) ([]*model.Joke, error) {
+	ids, err := r.pickN(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+
+	jokes := make([]*model.Joke, 0, len(ids))
+	for _, id := range ids {
+		joke, err := r.JokeRepository.GetJoke(ctx, id)
+		if errors.Is(err, ErrJokeNotFound) {
+			// Deleted behind the cache's back; return one joke fewer.
+			r.invalidate()
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		jokes = append(jokes, joke)
+	}
+
+	return jokes, nil
+}
+
+func (r *RandomIDCacheJokeRepository) CreateJoke(ctx context.Context, joke *model.Joke) (int64, error) {
+	id, err := r.JokeRepository.CreateJoke(ctx, joke)
+	if err == nil {
+		r.invalidate()
+	}
+	return id, err
+}
+
+func (r *RandomIDCacheJokeRepository) CreateJokeWithID(ctx context.Context, id int64, joke *model.Joke) error {
+	err := r.JokeRepository.CreateJokeWithID(ctx, id, joke)
+	if err == nil {
+		r.invalidate()
+	}
+	return err
+}
+
+func (r *RandomIDCacheJokeRepository) DeleteJoke(ctx context.Context, id int64) error {
+	err := r.JokeRepository.DeleteJoke(ctx, id)
+	if err == nil {
+		r.invalidate()
+	}
+	return err
+}
+
+func (r *RandomIDCacheJokeRepository) ReplaceJokes(ctx context.Context, jokes []*model.Joke) error {
+	err := r.JokeRepository.ReplaceJokes(ctx, jokes)
+	if err == nil {
+		r.invalidate()
+	}
+	return err
+}
+
+func (r *RandomIDCacheJokeRepository) GetOrCreateJokes(ctx context.Context, jokes []*model.Joke) ([]UpsertResult, error) {
+	results, err := r.JokeRepository.GetOrCreateJokes(ctx, jokes)
+	if err == nil {
+		r.invalidate()
+	}
+	return results, err
+}
+
+// Run reloads the cached IDs every interval until ctx is cancelled.
+func (r *RandomIDCacheJokeRepository) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Refresh(ctx); err != nil {
+				r.logger.Error("Failed to refresh cached joke IDs", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
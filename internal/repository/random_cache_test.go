@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// drawIDs draws n random jokes and returns how often each ID came up.
+func drawIDs(t *testing.T, repo JokeRepository, n int) map[int64]int {
+	t.Helper()
+
+	seen := make(map[int64]int)
+	for i := 0; i < n; i++ {
+		joke, err := repo.GetRandomJoke(context.Background())
+		if err != nil {
+			t.Fatalf("GetRandomJoke: %v", err)
+		}
+		if joke.Text == "" {
+			t.Fatalf("GetRandomJoke returned joke %d without text", joke.ID)
+		}
+		seen[joke.ID]++
+	}
+
+	return seen
+}
+
+func TestRandomIDCacheFollowsWrites(t *testing.T) {
+	ctx := context.Background()
+	sqlite := newTestRepository(t, Options{})
+	repo := NewRandomIDCacheJokeRepository(sqlite, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if _, err := repo.GetRandomJoke(ctx); !errors.Is(err, ErrNoJokes) {
+		t.Fatalf("GetRandomJoke on an empty table: err = %v, want ErrNoJokes", err)
+	}
+
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		id, err := repo.CreateJoke(ctx, &model.Joke{Text: fmt.Sprintf("joke %d", i)})
+		if err != nil {
+			t.Fatalf("creating joke: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	// The empty list cached above must not survive the inserts.
+	if seen := drawIDs(t, repo, 200); len(seen) != 3 {
+		t.Errorf("drew %v after inserting 3 jokes, want all 3", seen)
+	}
+
+	if err := repo.DeleteJoke(ctx, ids[1]); err != nil {
+		t.Fatalf("deleting joke: %v", err)
+	}
+
+	if seen := drawIDs(t, repo, 200); seen[ids[1]] > 0 || len(seen) != 2 {
+		t.Errorf("drew %v after deleting joke %d", seen, ids[1])
+	}
+}
+
+func TestRandomIDCacheRefreshPicksUpOutsideWrites(t *testing.T) {
+	ctx := context.Background()
+	sqlite := newTestRepository(t, Options{})
+	repo := NewRandomIDCacheJokeRepository(sqlite, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		id, err := sqlite.CreateJoke(ctx, &model.Joke{Text: fmt.Sprintf("joke %d", i)})
+		if err != nil {
+			t.Fatalf("creating joke: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	drawIDs(t, repo, 10)
+
+	// Writes that bypass the cache, as another process would make them.
+	if err := sqlite.DeleteJoke(ctx, ids[0]); err != nil {
+		t.Fatalf("deleting joke: %v", err)
+	}
+	added, err := sqlite.CreateJoke(ctx, &model.Joke{Text: "added elsewhere"})
+	if err != nil {
+		t.Fatalf("creating joke: %v", err)
+	}
+
+	// A stale ID is retried instead of failing the request.
+	if seen := drawIDs(t, repo, 200); seen[ids[0]] > 0 {
+		t.Errorf("drew deleted joke %d: %v", ids[0], seen)
+	}
+
+	if err := repo.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	seen := drawIDs(t, repo, 200)
+	if seen[ids[0]] > 0 {
+		t.Errorf("drew deleted joke %d after refresh: %v", ids[0], seen)
+	}
+	if seen[added] == 0 || len(seen) != 3 {
+		t.Errorf("drew %v after refresh, want jokes %d, %d and %d", seen, ids[1], ids[2], added)
+	}
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func TestGetRandomJokeEmpty(t *testing.T) {
+	ctx := context.Background()
+
+	repo, err := NewSQLiteJokeRepository(filepath.Join(t.TempDir(), "jokes.db"), Options{})
+	if err != nil {
+		t.Fatalf("creating repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	if _, err := repo.GetRandomJoke(ctx); !errors.Is(err, ErrNoJokes) {
+		t.Fatalf("GetRandomJoke on an empty table = %v, want ErrNoJokes", err)
+	}
+
+	if _, err := repo.CreateJoke(ctx, &model.Joke{Text: "The only joke"}); err != nil {
+		t.Fatalf("creating joke: %v", err)
+	}
+
+	joke, err := repo.GetRandomJoke(ctx)
+	if err != nil {
+		t.Fatalf("GetRandomJoke: %v", err)
+	}
+	if joke.Text != "The only joke" {
+		t.Errorf("GetRandomJoke text = %q, want %q", joke.Text, "The only joke")
+	}
+}
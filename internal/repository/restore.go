@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// ReplaceJokes deletes every joke and inserts jokes in their place, in one
+// transaction, so readers see either the old set or the new one. Jokes
+// keep their ID, public ID, timestamps, featured flag and view count where
+// set; a zero ID or empty public ID is assigned as for a new joke. Removing
+// the old jokes also removes them from collections.
+func (r *SQLiteJokeRepository) ReplaceJokes(ctx context.Context, jokes []*model.Joke) error {
+	if r.opts.MaxJokes > 0 && len(jokes) > r.opts.MaxJokes {
+		return ErrJokeLimitReached
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM jokes`); err != nil {
+		return fmt.Errorf("error deleting jokes: %w", err)
+	}
+
+	query := `
+		INSERT INTO jokes (id, public_id, text, text_hash, text_length, setup, punchline, featured, view_count, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now().UTC()
+	for _, joke := range jokes {
+		var id any
+		if joke.ID != 0 {
+			id = joke.ID
+		}
+
+		publicID := joke.PublicID
+		if publicID == "" {
+			publicID = newPublicID()
+		}
+
+		createdAt, updatedAt := joke.CreatedAt.UTC(), joke.UpdatedAt.UTC()
+		if joke.CreatedAt.IsZero() {
+			createdAt = now
+		}
+		if joke.UpdatedAt.IsZero() {
+			updatedAt = createdAt
+		}
+
+		_, err := tx.ExecContext(ctx, query, id, publicID, r.encodeText(joke.Text), textHash(joke.Text), textLength(joke.Text), r.encodeText(jokeSetup(joke)), r.encodeText(joke.Punchline), joke.Featured, joke.ViewCount, createdAt, updatedAt)
+		if err != nil {
+			return fmt.Errorf("error restoring joke: %w", constraintError(err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing jokes: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// shufflePrime is the modulus of the shuffle key. Keys stay below it, so
+// multiplying a key by one of the shuffle multipliers can't overflow
+// SQLite's 64-bit integers as long as the multipliers stay below 2^31.
+const shufflePrime = 4294967291
+
+// shuffleKey is an ordering expression that scatters joke IDs
+// pseudo-randomly depending on the @shuffle_a and @shuffle_b parameters.
+// Each round XORs in a seed half (SQLite has no XOR operator, so it is
+// spelled (x | s) - (x & s)) and multiplies modulo shufflePrime. The XOR
+// keeps the rounds from collapsing into a single, visibly regular, affine
+// step.
+var shuffleKey = shuffleRound(shuffleRound("jokes.id", "@shuffle_a", 1597334677), "@shuffle_b", 1865811235)
+
+func shuffleRound(x, seed string, multiplier int64) string {
+	return fmt.Sprintf("((((%[1]s) | %[2]s) - ((%[1]s) & %[2]s)) %% %[4]d * %[3]d %% %[4]d)", x, seed, multiplier, shufflePrime)
+}
+
+// shuffleArgs derives the shuffle parameters from seed.
+func shuffleArgs(seed string) []any {
+	hash := fnv.New64a()
+	hash.Write([]byte(seed))
+	sum := hash.Sum(nil)
+
+	return []any{
+		sql.Named("shuffle_a", int64(binary.BigEndian.Uint32(sum[:4]))),
+		sql.Named("shuffle_b", int64(binary.BigEndian.Uint32(sum[4:]))),
+	}
+}
+
+// ListShuffledJokes returns a page of jokes in an order that looks random
+// but is fixed for a given seed, so paging with the same seed walks through
+// every joke exactly once. Adding or removing jokes doesn't move the others
+// relative to each other.
+func (r *SQLiteJokeRepository) ListShuffledJokes(ctx context.Context, seed string, limit, offset int) ([]*model.Joke, error) {
+	query := `
+		SELECT ` + jokeColumns + `
+		FROM jokes
+		ORDER BY ` + shuffleKey + `, jokes.id
+		LIMIT @limit OFFSET @offset
+	`
+
+	args := append(shuffleArgs(seed), sql.Named("limit", limit), sql.Named("offset", offset))
+	return r.queryJokes(ctx, query, args...)
+}
+
+// GetRandomSample returns up to n jokes picked at random, the same ones for
+// the same seed as long as the set of jokes doesn't change. It is the first
+// page of the shuffle ListShuffledJokes walks for that seed.
+func (r *SQLiteJokeRepository) GetRandomSample(ctx context.Context, n int, seed int64) ([]*model.Joke, error) {
+	return r.ListShuffledJokes(ctx, strconv.FormatInt(seed, 10), n, 0)
+}
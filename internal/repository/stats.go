@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// CountJokesPerDay returns how many jokes were created on each UTC day
+// since the given time, oldest day first. Days without jokes are left out.
+func (r *SQLiteJokeRepository) CountJokesPerDay(ctx context.Context, since time.Time) ([]model.DailyCount, error) {
+	query := `
+		SELECT date(created_at) AS day, COUNT(*)
+		FROM jokes
+		WHERE created_at >= ?
+		GROUP BY day
+		ORDER BY day
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("error counting jokes per day: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []model.DailyCount
+	for rows.Next() {
+		var count model.DailyCount
+		if err := rows.Scan(&count.Date, &count.Count); err != nil {
+			return nil, fmt.Errorf("error scanning daily count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error counting jokes per day: %w", err)
+	}
+
+	return counts, nil
+}
+
+// JokeLengthPercentiles returns the minimum, maximum and 50th, 90th and
+// 99th percentile of joke text lengths. SQLite has no percentile function,
+// so each percentile is read as the row at its nearest rank in the
+// text_length index, inside one transaction for a consistent view.
+func (r *SQLiteJokeRepository) JokeLengthPercentiles(ctx context.Context) (*model.LengthPercentiles, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var stats model.LengthPercentiles
+	var minLength, maxLength sql.NullInt64
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*), MIN(text_length), MAX(text_length) FROM jokes`).Scan(&stats.Count, &minLength, &maxLength)
+	if err != nil {
+		return nil, fmt.Errorf("error reading joke lengths: %w", err)
+	}
+	if stats.Count == 0 {
+		return &stats, nil
+	}
+	stats.Min, stats.Max = int(minLength.Int64), int(maxLength.Int64)
+
+	for _, p := range []struct {
+		percent int
+		dst     *int
+	}{{50, &stats.P50}, {90, &stats.P90}, {99, &stats.P99}} {
+		// The nearest rank is ceil(percent/100 * count), counting from 1.
+		rank := (p.percent*stats.Count + 99) / 100
+		err := tx.QueryRowContext(ctx, `SELECT text_length FROM jokes ORDER BY text_length LIMIT 1 OFFSET ?`, rank-1).Scan(p.dst)
+		if err != nil {
+			return nil, fmt.Errorf("error reading p%d joke length: %w", p.percent, err)
+		}
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// UpsertResult is the outcome of GetOrCreateJokes for one joke.
+type UpsertResult struct {
+	Joke    *model.Joke
+	Created bool
+}
+
+// GetOrCreateJokes creates each joke unless a joke with the same normalized
+// text (see NormalizeText) already exists, in which case the oldest such
+// joke is returned instead. Jokes repeated within the batch are created
+// once. Everything happens in one transaction: either all new jokes are
+// stored or none are.
+func (r *SQLiteJokeRepository) GetOrCreateJokes(ctx context.Context, jokes []*model.Joke) ([]UpsertResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	getQuery := `
+		SELECT ` + jokeColumns + `
+		FROM jokes
+		WHERE id = ?
+	`
+
+	results := make([]UpsertResult, 0, len(jokes))
+	for _, joke := range jokes {
+		id, created, err := r.getOrInsertJoke(ctx, tx, 0, joke)
+		if err != nil {
+			return nil, err
+		}
+
+		stored, err := r.scanJoke(tx.QueryRowContext(ctx, getQuery, id))
+		if err != nil {
+			return nil, fmt.Errorf("error getting joke: %w", err)
+		}
+		results = append(results, UpsertResult{Joke: stored, Created: created})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing jokes: %w", err)
+	}
+
+	return results, nil
+}
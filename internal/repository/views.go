@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+// AddJokeViews adds a batch of views, keyed by joke ID, to the view counts
+// in a single transaction. Views of jokes that have been deleted since are
+// dropped.
+func (r *SQLiteJokeRepository) AddJokeViews(ctx context.Context, views map[int64]int64) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `UPDATE jokes SET view_count = view_count + ? WHERE id = ?`)
+	if err != nil {
+		return fmt.Errorf("error preparing view update: %w", err)
+	}
+	defer stmt.Close()
+
+	for id, n := range views {
+		if _, err := stmt.ExecContext(ctx, n, id); err != nil {
+			return fmt.Errorf("error adding joke views: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing joke views: %w", err)
+	}
+
+	return nil
+}
+
+// ListMostViewedJokes lists jokes that have been viewed at least once, most
+// viewed first.
+func (r *SQLiteJokeRepository) ListMostViewedJokes(ctx context.Context, limit, offset int) ([]*model.Joke, error) {
+	query := `
+		SELECT ` + jokeColumns + `
+		FROM jokes
+		WHERE view_count > 0
+		ORDER BY view_count DESC, id ASC
+		LIMIT ? OFFSET ?
+	`
+
+	return r.queryJokes(ctx, query, limit, offset)
+}
+
+// TouchJokes sets the last access time of a batch of jokes, keyed by joke
+// ID, in a single transaction. Jokes that have been deleted since are
+// skipped.
+func (r *SQLiteJokeRepository) TouchJokes(ctx context.Context, accessed map[int64]time.Time) error {
+	if len(accessed) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `UPDATE jokes SET last_accessed_at = ? WHERE id = ?`)
+	if err != nil {
+		return fmt.Errorf("error preparing access update: %w", err)
+	}
+	defer stmt.Close()
+
+	for id, at := range accessed {
+		if _, err := stmt.ExecContext(ctx, at.UTC(), id); err != nil {
+			return fmt.Errorf("error touching joke: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing joke accesses: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package sanitize
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	scriptOrStyle = regexp.MustCompile(`(?is)<script\b[^>]*>.*?</script\s*>|<style\b[^>]*>.*?</style\s*>`)
+	htmlTag       = regexp.MustCompile(`(?s)</?[a-zA-Z!][^>]*>`)
+	whitespace    = regexp.MustCompile(`[ \t]+`)
+)
+
+// ContainsHTML reports whether s contains anything that looks like an HTML tag.
+func ContainsHTML(s string) bool {
+	return htmlTag.MatchString(s)
+}
+
+// StripHTML removes HTML tags from s, dropping the contents of script and
+// style elements entirely. Entities are left untouched so that escaped
+// markup can't turn back into tags.
+func StripHTML(s string) string {
+	s = scriptOrStyle.ReplaceAllString(s, "")
+	s = htmlTag.ReplaceAllString(s, "")
+	s = whitespace.ReplaceAllString(s, " ")
+
+	return strings.TrimSpace(s)
+}
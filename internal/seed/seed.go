@@ -0,0 +1,41 @@
+package seed
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+//go:embed jokes.json
+var starterJokes []byte
+
+// SeedIfEmpty inserts the embedded starter jokes, but only when the
+// repository has no jokes yet. It is safe to call on every startup and
+// returns the number of jokes inserted.
+func SeedIfEmpty(ctx context.Context, repo repository.JokeRepository) (int, error) {
+	count, err := repo.CountJokes(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error checking for existing jokes: %w", err)
+	}
+
+	if count > 0 {
+		return 0, nil
+	}
+
+	var texts []string
+	if err := json.Unmarshal(starterJokes, &texts); err != nil {
+		return 0, fmt.Errorf("error decoding starter jokes: %w", err)
+	}
+
+	for i, text := range texts {
+		if _, err := repo.CreateJoke(ctx, &model.Joke{Text: text}); err != nil {
+			return i, fmt.Errorf("error seeding joke: %w", err)
+		}
+	}
+
+	return len(texts), nil
+}
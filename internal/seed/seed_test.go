@@ -0,0 +1,79 @@
+package seed
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func newTestRepository(t *testing.T) *repository.SQLiteJokeRepository {
+	t.Helper()
+
+	repo, err := repository.NewSQLiteJokeRepository(filepath.Join(t.TempDir(), "jokes.db"), repository.Options{})
+	if err != nil {
+		t.Fatalf("creating repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	return repo
+}
+
+func TestSeedIfEmptySeedsEmptyDatabase(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	var texts []string
+	if err := json.Unmarshal(starterJokes, &texts); err != nil {
+		t.Fatalf("decoding starter jokes: %v", err)
+	}
+
+	n, err := SeedIfEmpty(ctx, repo)
+	if err != nil {
+		t.Fatalf("SeedIfEmpty: %v", err)
+	}
+	if n != len(texts) {
+		t.Errorf("seeded %d jokes, want %d", n, len(texts))
+	}
+
+	// A second run finds the seeded jokes and leaves them alone.
+	if n, err := SeedIfEmpty(ctx, repo); err != nil || n != 0 {
+		t.Errorf("second SeedIfEmpty = %d, %v, want 0, nil", n, err)
+	}
+
+	count, err := repo.CountJokes(ctx)
+	if err != nil {
+		t.Fatalf("counting jokes: %v", err)
+	}
+	if count != len(texts) {
+		t.Errorf("%d jokes stored, want %d", count, len(texts))
+	}
+}
+
+func TestSeedIfEmptySkipsExistingJokes(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t)
+
+	if _, err := repo.CreateJoke(ctx, &model.Joke{Text: "An existing joke"}); err != nil {
+		t.Fatalf("creating joke: %v", err)
+	}
+
+	n, err := SeedIfEmpty(ctx, repo)
+	if err != nil {
+		t.Fatalf("SeedIfEmpty: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("seeded %d jokes into a non-empty database", n)
+	}
+
+	count, err := repo.CountJokes(ctx)
+	if err != nil {
+		t.Fatalf("counting jokes: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("%d jokes stored, want 1", count)
+	}
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRouterReadProtectedPaths(t *testing.T) {
+	const readKey = "test-read-key"
+
+	srv, _ := newTestServerWithDeps(t, func(deps *Deps) {
+		deps.ReadAPIKey = readKey
+		deps.ReadProtectedPaths = []string{"/api/joke/featured"}
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		header     http.Header
+		wantStatus int
+	}{
+		{"protected without key", "/api/joke/featured", nil, http.StatusUnauthorized},
+		{"protected with wrong key", "/api/joke/featured", http.Header{"Read-Api-Key": {"nope"}}, http.StatusUnauthorized},
+		{"protected with read key", "/api/joke/featured", http.Header{"Read-Api-Key": {readKey}}, http.StatusOK},
+		{"protected with admin key", "/api/joke/featured", http.Header{"Admin-Api-Key": {testAdminAPIKey}}, http.StatusOK},
+		{"public", "/api/joke/", nil, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := doRequest(t, http.MethodGet, srv.URL+tt.path, "", tt.header)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/handler"
+)
+
+func TestRouterCreateJokeBodyErrors(t *testing.T) {
+	srv, _ := newTestServer(t)
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode string
+	}{
+		{"empty", "", "empty_body"},
+		{"whitespace only", " \n\t ", "empty_body"},
+		{"malformed", `{"text":`, "invalid_json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, http.MethodPost, srv.URL+"/api/admin/joke", tt.body, admin)
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+			}
+
+			var payload struct {
+				Code string `json:"code"`
+			}
+			if err := json.Unmarshal([]byte(body), &payload); err != nil {
+				t.Fatalf("decoding body %q: %v", body, err)
+			}
+			if payload.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", payload.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRouterBodyReadTimeout(t *testing.T) {
+	srv, _ := newTestServerWithDeps(t, func(deps *Deps) {
+		deps.BodyReadTimeout = 100 * time.Millisecond
+	})
+
+	// The body starts but never finishes, like a client dribbling bytes.
+	body, slow := io.Pipe()
+	t.Cleanup(func() { slow.Close() })
+	go slow.Write([]byte(`{"text": "Why did the`))
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/admin/joke", body)
+	if err != nil {
+		t.Fatalf("building request: %v", err)
+	}
+	req.Header.Set("Admin-Api-Key", testAdminAPIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusRequestTimeout {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusRequestTimeout)
+	}
+
+	var errResp handler.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		t.Fatalf("decoding error: %v", err)
+	}
+	if errResp.Code != "body_timeout" {
+		t.Errorf("code = %q, want %q", errResp.Code, "body_timeout")
+	}
+
+	// A body sent in one go is unaffected.
+	resp, _ = doRequest(t, http.MethodPost, srv.URL+"/api/admin/joke", `{"text": "Why did the chicken cross the road?"}`, http.Header{"Admin-Api-Key": {testAdminAPIKey}})
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("fast body status = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+}
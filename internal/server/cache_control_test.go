@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	internalMiddleware "github.com/treboc/huhu-api/internal/middleware"
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func TestRouterCacheControl(t *testing.T) {
+	srv, repo := newTestServerWithDeps(t, func(deps *Deps) {
+		deps.CacheControl = []internalMiddleware.CacheControlPolicy{
+			{Path: "/api/joke/random", Value: "no-store"},
+			{Path: "/api/joke/", Value: "public, max-age=60"},
+			{Path: "/api/joke/*", Value: "public, max-age=300"},
+		}
+	})
+
+	id, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "knock knock"})
+	if err != nil {
+		t.Fatalf("seeding joke: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/joke/", "public, max-age=60"},
+		{fmt.Sprintf("/api/joke/%d", id), "public, max-age=300"},
+		{"/api/joke/random", "no-store"},
+		{"/api/joke/checksum", "no-cache"},
+		{"/api/joke/9999", ""},
+		{"/healthz", ""},
+	}
+
+	for _, tt := range tests {
+		resp, _ := doRequest(t, http.MethodGet, srv.URL+tt.path, "", nil)
+		if got := resp.Header.Get("Cache-Control"); got != tt.want {
+			t.Errorf("%s: Cache-Control = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
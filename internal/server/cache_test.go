@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	internalMiddleware "github.com/treboc/huhu-api/internal/middleware"
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterResponseCache(t *testing.T) {
+	srv, _ := newTestServerWithDeps(t, func(deps *Deps) {
+		deps.ResponseCacheTTL = time.Minute
+	}, func(repo *repository.SQLiteJokeRepository) {
+		if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "first"}); err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+	})
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+
+	list := func(t *testing.T) (cacheStatus string, total int) {
+		t.Helper()
+
+		resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/joke/", "", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+
+		var result struct {
+			Total int `json:"total"`
+		}
+		if err := json.Unmarshal([]byte(body), &result); err != nil {
+			t.Fatalf("decoding list: %v", err)
+		}
+		return resp.Header.Get(internalMiddleware.CacheStatusHeader), result.Total
+	}
+
+	if status, total := list(t); status != "MISS" || total != 1 {
+		t.Fatalf("first list = %s with %d jokes, want MISS with 1", status, total)
+	}
+	if status, total := list(t); status != "HIT" || total != 1 {
+		t.Fatalf("second list = %s with %d jokes, want HIT with 1", status, total)
+	}
+
+	resp, _ := doRequest(t, http.MethodPost, srv.URL+"/api/admin/joke", `{"text":"second"}`, admin)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create status = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+
+	if status, total := list(t); status != "MISS" || total != 2 {
+		t.Errorf("list after write = %s with %d jokes, want MISS with 2", status, total)
+	}
+
+	// Random picks are never cached.
+	resp, _ = doRequest(t, http.MethodGet, srv.URL+"/api/joke/random", "", nil)
+	if got := resp.Header.Get(internalMiddleware.CacheStatusHeader); got != "" {
+		t.Errorf("random joke %s = %q, want no cache involvement", internalMiddleware.CacheStatusHeader, got)
+	}
+}
+
+func TestRouterResponseCacheKeepsPerRequestHeaders(t *testing.T) {
+	srv, _ := newTestServerWithDeps(t, func(deps *Deps) {
+		deps.ResponseCacheTTL = time.Minute
+	}, func(repo *repository.SQLiteJokeRepository) {
+		if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "cached joke"}); err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+	})
+
+	for i, tt := range []struct {
+		origin, correlationID, wantCache string
+	}{
+		{"https://a.example", "corr-one", "MISS"},
+		{"https://b.example", "corr-two", "HIT"},
+	} {
+		resp, _ := doRequest(t, http.MethodGet, srv.URL+"/api/joke/", "", http.Header{
+			"Origin":           {tt.origin},
+			"X-Correlation-Id": {tt.correlationID},
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want %d", i, resp.StatusCode, http.StatusOK)
+		}
+
+		if got := resp.Header.Get("X-Cache"); got != tt.wantCache {
+			t.Errorf("request %d: X-Cache = %q, want %q", i, got, tt.wantCache)
+		}
+		if got := resp.Header.Get("Access-Control-Allow-Origin"); got != tt.origin {
+			t.Errorf("request %d: Access-Control-Allow-Origin = %q, want %q", i, got, tt.origin)
+		}
+		for _, name := range []string{"X-Request-Id", "X-Correlation-Id"} {
+			if got := resp.Header.Get(name); got != tt.correlationID {
+				t.Errorf("request %d: %s = %q, want %q", i, name, got, tt.correlationID)
+			}
+		}
+
+		// The handler's own headers are replayed, without repeating the
+		// Vary values CORS adds per request.
+		if got := resp.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("request %d: Content-Type = %q", i, got)
+		}
+		vary := strings.Join(resp.Header.Values("Vary"), ", ")
+		if !strings.Contains(vary, "Prefer") || strings.Count(vary, "Origin") != 1 {
+			t.Errorf("request %d: Vary = %q, want Origin once and Prefer", i, vary)
+		}
+	}
+}
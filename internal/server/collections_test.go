@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterGetCollection(t *testing.T) {
+	srv, _ := newTestServerWithDeps(t, func(deps *Deps) {
+		deps.HandlerOptions.StrictParams = true
+	}, func(repo *repository.SQLiteJokeRepository) {
+		ctx := context.Background()
+		collection, err := repo.CreateCollection(ctx, &model.Collection{Name: "Favourites"})
+		if err != nil {
+			t.Fatalf("creating collection: %v", err)
+		}
+		for i := 0; i < 3; i++ {
+			id, err := repo.CreateJoke(ctx, &model.Joke{Text: fmt.Sprintf("joke %d", i)})
+			if err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+			if err := repo.AddJokeToCollection(ctx, collection, id); err != nil {
+				t.Fatalf("adding joke to collection: %v", err)
+			}
+		}
+		if _, err := repo.CreateCollection(ctx, &model.Collection{Name: "Empty"}); err != nil {
+			t.Fatalf("creating collection: %v", err)
+		}
+	})
+
+	resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/collections/1?limit=2", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", resp.StatusCode, http.StatusOK, body)
+	}
+
+	var page struct {
+		Name    string       `json:"name"`
+		Jokes   []model.Joke `json:"jokes"`
+		Total   int          `json:"total"`
+		HasMore bool         `json:"has_more"`
+	}
+	if err := json.Unmarshal([]byte(body), &page); err != nil {
+		t.Fatalf("decoding collection: %v", err)
+	}
+	if page.Name != "Favourites" || len(page.Jokes) != 2 || page.Total != 3 || !page.HasMore {
+		t.Errorf("page = %+v, want 2 of 3 jokes of Favourites", page)
+	}
+	if link := resp.Header.Get("Link"); !strings.Contains(link, `rel="next"`) || !strings.Contains(link, `rel="last"`) {
+		t.Errorf("Link = %q, want next and last links", link)
+	}
+
+	// The jokes exist, so an empty collection isn't the empty deployment.
+	_, body = doRequest(t, http.MethodGet, srv.URL+"/api/collections/2", "", nil)
+	if strings.Contains(body, "empty_collection") {
+		t.Errorf("empty collection body = %s, want no empty_collection flag", body)
+	}
+
+	resp, _ = doRequest(t, http.MethodGet, srv.URL+"/api/collections/1?page=2", "", nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown parameter: status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterCompressionSkipsStreams(t *testing.T) {
+	srv, _ := newTestServerWithDeps(t, func(deps *Deps) {
+		deps.CompressResponses = true
+	}, func(repo *repository.SQLiteJokeRepository) {
+		_, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "Knock knock. Who's there?", Setup: "Knock knock.", Punchline: "Who's there?"})
+		if err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+		if err := repo.SetFeatureFlag(context.Background(), TellJokesFlag, true); err != nil {
+			t.Fatalf("enabling %s: %v", TellJokesFlag, err)
+		}
+	})
+	gzipOK := http.Header{"Accept-Encoding": {"gzip"}}
+
+	resp, _ := doRequest(t, http.MethodGet, srv.URL+"/api/joke/", "", gzipOK)
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("list Content-Encoding = %q, want gzip", got)
+	}
+
+	resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/joke/1/tell", "", gzipOK)
+	if got := resp.Header.Get("Content-Encoding"); got != "" {
+		t.Errorf("tell Content-Encoding = %q, want none", got)
+	}
+	if !strings.Contains(body, "event: punchline") {
+		t.Errorf("tell body is not a plain event stream: %q", body)
+	}
+}
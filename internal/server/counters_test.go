@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func TestRouterResetJokeCounters(t *testing.T) {
+	srv, repo := newTestServer(t)
+	ctx := context.Background()
+
+	id, err := repo.CreateJoke(ctx, &model.Joke{Setup: "Knock knock.", Punchline: "Who's there?", Text: "Knock knock. Who's there?"})
+	if err != nil {
+		t.Fatalf("seeding joke: %v", err)
+	}
+	if err := repo.SetJokeFeatured(ctx, id, true); err != nil {
+		t.Fatalf("featuring joke: %v", err)
+	}
+	if err := repo.AddJokeViews(ctx, map[int64]int64{id: 42}); err != nil {
+		t.Fatalf("adding views: %v", err)
+	}
+
+	before, err := repo.GetJoke(ctx, id)
+	if err != nil {
+		t.Fatalf("getting joke: %v", err)
+	}
+	if before.ViewCount != 42 {
+		t.Fatalf("view count = %d before reset, want 42", before.ViewCount)
+	}
+
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+	resp, body := doRequest(t, http.MethodPost, fmt.Sprintf("%s/api/admin/joke/%d/reset-counters", srv.URL, id), "", admin)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", resp.StatusCode, http.StatusOK, body)
+	}
+
+	var got model.Joke
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatalf("decoding joke: %v", err)
+	}
+	if got.ViewCount != 0 {
+		t.Errorf("returned view count = %d, want 0", got.ViewCount)
+	}
+
+	after, err := repo.GetJoke(ctx, id)
+	if err != nil {
+		t.Fatalf("getting joke: %v", err)
+	}
+	if after.ViewCount != 0 {
+		t.Errorf("stored view count = %d, want 0", after.ViewCount)
+	}
+
+	after.ViewCount = before.ViewCount
+	if !reflect.DeepEqual(after, before) {
+		t.Errorf("joke changed beyond its counters:\n got %+v\nwant %+v", after, before)
+	}
+
+	if resp, _ := doRequest(t, http.MethodPost, srv.URL+"/api/admin/joke/9999/reset-counters", "", admin); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("missing joke: status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterDebugResponses(t *testing.T) {
+	seed := func(repo *repository.SQLiteJokeRepository) {
+		if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "knock knock"}); err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+	}
+	enableDebug := func(repo *repository.SQLiteJokeRepository) {
+		if err := repo.SetFeatureFlag(context.Background(), "debug_responses", true); err != nil {
+			t.Fatalf("enabling flag: %v", err)
+		}
+	}
+
+	debugObject := func(t *testing.T, srv *httptest.Server) map[string]any {
+		t.Helper()
+
+		resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/joke/1?debug=true", "", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want %d (body %s)", resp.StatusCode, http.StatusOK, body)
+		}
+
+		var payload map[string]any
+		if err := json.Unmarshal([]byte(body), &payload); err != nil {
+			t.Fatalf("decoding body %q: %v", body, err)
+		}
+
+		debug, _ := payload["_debug"].(map[string]any)
+		return debug
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		srv, _ := newTestServer(t, seed)
+
+		if debug := debugObject(t, srv); debug != nil {
+			t.Fatalf("_debug present while flag is disabled: %v", debug)
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		srv, _ := newTestServer(t, seed, enableDebug)
+
+		debug := debugObject(t, srv)
+		if debug == nil {
+			t.Fatal("_debug missing while flag is enabled")
+		}
+		if debug["route"] != "/api/joke/{id}" {
+			t.Errorf("route = %v, want %q", debug["route"], "/api/joke/{id}")
+		}
+	})
+}
@@ -0,0 +1,16 @@
+package server
+
+import internalMiddleware "github.com/treboc/huhu-api/internal/middleware"
+
+// deprecations lists the deprecated endpoints and query parameters. Clients
+// using them get Deprecation and Sunset headers, and each use is logged.
+// Add an entry when an endpoint or parameter is superseded, for example:
+//
+//	{
+//		Path:   "/api/joke/",
+//		Param:  "offset",
+//		Since:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+//		Sunset: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+//		Link:   "https://jokes.example.com/docs/cursors",
+//	}
+var deprecations []internalMiddleware.Deprecation
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	internalMiddleware "github.com/treboc/huhu-api/internal/middleware"
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterDeprecations(t *testing.T) {
+	saved := deprecations
+	t.Cleanup(func() { deprecations = saved })
+	deprecations = []internalMiddleware.Deprecation{
+		{Path: "/api/joke/", Param: "offset", Since: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Link: "https://example.com/docs/cursors"},
+	}
+
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		for i := 0; i < 3; i++ {
+			if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: fmt.Sprintf("joke %d", i)}); err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+		}
+	})
+
+	resp, _ := doRequest(t, http.MethodGet, srv.URL+"/api/joke/?limit=1&offset=1", "", nil)
+	if got := resp.Header.Get("Deprecation"); got != "@1704067200" {
+		t.Errorf("Deprecation = %q, want @1704067200", got)
+	}
+
+	// The deprecation link is added next to the pagination links.
+	links := strings.Join(resp.Header.Values("Link"), ", ")
+	if !strings.Contains(links, `rel="deprecation"`) || !strings.Contains(links, `rel="next"`) {
+		t.Errorf("Link = %q, want both the deprecation and pagination links", links)
+	}
+
+	for _, path := range []string{"/api/joke/?limit=1", "/api/joke/1"} {
+		resp, _ := doRequest(t, http.MethodGet, srv.URL+path, "", nil)
+		if got := resp.Header.Get("Deprecation"); got != "" {
+			t.Errorf("GET %s: Deprecation = %q, want none", path, got)
+		}
+	}
+}
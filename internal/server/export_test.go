@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterExportRange(t *testing.T) {
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		for i := 0; i < 5; i++ {
+			if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: fmt.Sprintf("joke %d", i)}); err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+		}
+	})
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+
+	resp, full := doRequest(t, http.MethodGet, srv.URL+"/api/admin/jokes/export", "", admin)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := resp.Header.Get("Accept-Ranges"); got != "bytes" {
+		t.Errorf("Accept-Ranges = %q, want bytes", got)
+	}
+
+	var jokes []model.Joke
+	if err := json.Unmarshal([]byte(full), &jokes); err != nil {
+		t.Fatalf("decoding export: %v", err)
+	}
+	if len(jokes) != 5 {
+		t.Fatalf("exported %d jokes, want 5", len(jokes))
+	}
+
+	rangeHeader := http.Header{"Admin-Api-Key": {testAdminAPIKey}, "Range": {"bytes=10-29"}}
+	resp, part := doRequest(t, http.MethodGet, srv.URL+"/api/admin/jokes/export", "", rangeHeader)
+	if resp.StatusCode != http.StatusPartialContent {
+		t.Fatalf("range status = %d, want %d", resp.StatusCode, http.StatusPartialContent)
+	}
+	if part != full[10:30] {
+		t.Errorf("range body = %q, want %q", part, full[10:30])
+	}
+	if want := fmt.Sprintf("bytes 10-29/%d", len(full)); resp.Header.Get("Content-Range") != want {
+		t.Errorf("Content-Range = %q, want %q", resp.Header.Get("Content-Range"), want)
+	}
+}
+
+func TestRouterExportCreatedRange(t *testing.T) {
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		var jokes []*model.Joke
+		for day := 1; day <= 4; day++ {
+			at := time.Date(2024, 5, day, 12, 0, 0, 0, time.UTC)
+			jokes = append(jokes, &model.Joke{ID: int64(day), Text: fmt.Sprintf("joke of May %d", day), CreatedAt: at, UpdatedAt: at})
+		}
+		if err := repo.ReplaceJokes(context.Background(), jokes); err != nil {
+			t.Fatalf("seeding jokes: %v", err)
+		}
+	})
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+
+	tests := []struct {
+		query string
+		want  []int64
+	}{
+		{"", []int64{1, 2, 3, 4}},
+		{"?created_after=2024-05-02T00:00:00Z", []int64{2, 3, 4}},
+		{"?created_after=2024-05-02T00:00:00Z&created_before=2024-05-04T00:00:00Z", []int64{2, 3}},
+		{"?created_before=2024-05-01T00:00:00Z", nil},
+	}
+
+	for _, tt := range tests {
+		resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/admin/jokes/export"+tt.query, "", admin)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status = %d, want %d: %s", tt.query, resp.StatusCode, http.StatusOK, body)
+		}
+
+		var jokes []model.Joke
+		if err := json.Unmarshal([]byte(body), &jokes); err != nil {
+			t.Fatalf("%s: decoding export: %v", tt.query, err)
+		}
+
+		var got []int64
+		for _, joke := range jokes {
+			got = append(got, joke.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: exported %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	if resp, _ := doRequest(t, http.MethodGet, srv.URL+"/api/admin/jokes/export?created_after=yesterday", "", admin); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid created_after: status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/featureflag"
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterFeatureFlagGatesRoute(t *testing.T) {
+	var flags *featureflag.Store
+	srv, repo := newTestServerWithDeps(t, func(deps *Deps) {
+		flags = deps.FeatureFlags
+		deps.HandlerOptions.TellPause = time.Millisecond
+	}, func(repo *repository.SQLiteJokeRepository) {
+		if _, err := repo.CreateJoke(context.Background(), &model.Joke{Setup: "Knock knock.", Punchline: "Who's there?"}); err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+	})
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+	tellURL := srv.URL + "/api/joke/1/tell"
+
+	tell := func() (int, string) {
+		t.Helper()
+		resp, body := doRequest(t, http.MethodGet, tellURL, "", nil)
+		return resp.StatusCode, body
+	}
+
+	// The route predates the flag, so it is on by default.
+	if status, body := tell(); status != http.StatusOK || !strings.Contains(body, "event: punchline") {
+		t.Fatalf("default: status = %d, body = %q, want the event stream", status, body)
+	}
+
+	// Disabled behind this instance's back, as another instance would do
+	// it: the route stays up until the next refresh.
+	if err := repo.SetFeatureFlag(context.Background(), TellJokesFlag, false); err != nil {
+		t.Fatalf("disabling flag: %v", err)
+	}
+	if status, _ := tell(); status != http.StatusOK {
+		t.Errorf("before refresh: status = %d, want %d", status, http.StatusOK)
+	}
+
+	if err := flags.Refresh(context.Background()); err != nil {
+		t.Fatalf("refreshing flags: %v", err)
+	}
+	if status, _ := tell(); status != http.StatusNotFound {
+		t.Errorf("after refresh: status = %d, want %d", status, http.StatusNotFound)
+	}
+
+	// The admin toggle applies on this instance right away.
+	if resp, _ := doRequest(t, http.MethodPut, srv.URL+"/api/admin/features/"+TellJokesFlag, `{"enabled": true}`, admin); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("enabling flag: status = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+	if status, body := tell(); status != http.StatusOK || !strings.Contains(body, "event: punchline") {
+		t.Errorf("after enabling: status = %d, body = %q, want the event stream", status, body)
+	}
+}
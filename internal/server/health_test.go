@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterHealthz(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	resp, body := doRequest(t, http.MethodGet, srv.URL+"/healthz", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if body != "OK" {
+		t.Errorf("body = %q, want %q", body, "OK")
+	}
+}
+
+func TestRouterHealthzVerbose(t *testing.T) {
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "A healthy joke"}); err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+	})
+
+	resp, body := doRequest(t, http.MethodGet, srv.URL+"/healthz?verbose=true", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	want := `{"status":"ok","checks":{"database":"ok","migrations":"applied","jokes_count":1}}`
+	if strings.TrimSpace(body) != want {
+		t.Errorf("body = %s, want %s", body, want)
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterJokesHTML(t *testing.T) {
+	texts := []string{"First joke", `<script>alert("boo")</script>`, "Third joke"}
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		for _, text := range texts {
+			if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: text}); err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+		}
+	})
+
+	resp, body := doRequest(t, http.MethodGet, srv.URL+"/jokes?limit=2", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "text/html; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want text/html", got)
+	}
+
+	if !strings.Contains(body, "First joke") {
+		t.Errorf("first page is missing the first joke:\n%s", body)
+	}
+	if strings.Contains(body, "<script>") || !strings.Contains(body, "&lt;script&gt;alert(&#34;boo&#34;)&lt;/script&gt;") {
+		t.Errorf("joke text is not HTML escaped:\n%s", body)
+	}
+	if strings.Contains(body, "Third joke") || strings.Contains(body, `rel="prev"`) {
+		t.Errorf("first page shows more than its jokes or a previous link:\n%s", body)
+	}
+
+	next := `<a href="/jokes?limit=2&amp;offset=2" rel="next">`
+	if !strings.Contains(body, next) {
+		t.Fatalf("first page has no next link %s:\n%s", next, body)
+	}
+
+	resp, body = doRequest(t, http.MethodGet, srv.URL+"/jokes?limit=2&offset=2", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("second page: status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if !strings.Contains(body, "Third joke") || strings.Contains(body, "First joke") {
+		t.Errorf("second page shows the wrong jokes:\n%s", body)
+	}
+	if !strings.Contains(body, `<a href="/jokes?limit=2&amp;offset=0" rel="prev">`) || strings.Contains(body, `rel="next"`) {
+		t.Errorf("second page links are wrong:\n%s", body)
+	}
+}
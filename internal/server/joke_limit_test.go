@@ -0,0 +1,47 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterJokeLimit(t *testing.T) {
+	capped, err := repository.NewSQLiteJokeRepository(filepath.Join(t.TempDir(), "capped.db"), repository.Options{MaxJokes: 2})
+	if err != nil {
+		t.Fatalf("creating repository: %v", err)
+	}
+	t.Cleanup(func() { capped.Close() })
+
+	srv, _ := newTestServerWithDeps(t, func(deps *Deps) { deps.Repo = capped })
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+
+	for i := 0; i < 2; i++ {
+		resp, body := doRequest(t, http.MethodPost, srv.URL+"/api/admin/joke", fmt.Sprintf(`{"text":"joke %d"}`, i), admin)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("create %d status = %d, want %d (body %s)", i, resp.StatusCode, http.StatusCreated, body)
+		}
+	}
+
+	requests := []struct {
+		path string
+		body string
+	}{
+		{"/api/admin/joke", `{"text":"one too many"}`},
+		{"/api/admin/jokes/upsert", `[{"text":"joke 0"},{"text":"one too many"}]`},
+	}
+
+	for _, req := range requests {
+		resp, body := doRequest(t, http.MethodPost, srv.URL+req.path, req.body, admin)
+		if resp.StatusCode != http.StatusInsufficientStorage {
+			t.Errorf("POST %s status = %d, want %d", req.path, resp.StatusCode, http.StatusInsufficientStorage)
+		}
+		if !strings.Contains(body, `"code":"joke_limit_reached"`) {
+			t.Errorf("POST %s body = %s, want code joke_limit_reached", req.path, body)
+		}
+	}
+}
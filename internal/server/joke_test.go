@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func TestRouterTwoPartJoke(t *testing.T) {
+	srv, _ := newTestServer(t)
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+
+	resp, body := doRequest(t, http.MethodPost, srv.URL+"/api/admin/joke",
+		`{"setup":"Why did the gopher cross the road?","punchline":"To get to the other side."}`, admin)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create status = %d, want %d (body %s)", resp.StatusCode, http.StatusCreated, body)
+	}
+	location := resp.Header.Get("Location")
+
+	getJoke := func(t *testing.T, url string) model.Joke {
+		t.Helper()
+
+		resp, body := doRequest(t, http.MethodGet, url, "", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("get status = %d, want %d (body %s)", resp.StatusCode, http.StatusOK, body)
+		}
+
+		var joke model.Joke
+		if err := json.Unmarshal([]byte(body), &joke); err != nil {
+			t.Fatalf("decoding joke: %v", err)
+		}
+		return joke
+	}
+
+	joke := getJoke(t, srv.URL+location)
+	if joke.Setup != "Why did the gopher cross the road?" || joke.Punchline != "To get to the other side." {
+		t.Errorf("setup, punchline = %q, %q", joke.Setup, joke.Punchline)
+	}
+	if joke.Text != "Why did the gopher cross the road? To get to the other side." {
+		t.Errorf("text = %q", joke.Text)
+	}
+
+	joke = getJoke(t, srv.URL+location+"?reveal=false")
+	if joke.Punchline != "" {
+		t.Errorf("punchline = %q with reveal=false, want none", joke.Punchline)
+	}
+	if joke.Text != joke.Setup {
+		t.Errorf("text = %q with reveal=false, want setup %q", joke.Text, joke.Setup)
+	}
+
+	resp, _ = doRequest(t, http.MethodPost, srv.URL+"/api/admin/joke", `{"setup":"Only a setup"}`, admin)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("setup without punchline: status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestRouterHeadJoke(t *testing.T) {
+	srv, repo := newTestServer(t)
+
+	id, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "knock knock"})
+	if err != nil {
+		t.Fatalf("seeding joke: %v", err)
+	}
+
+	resp, body := doRequest(t, http.MethodHead, fmt.Sprintf("%s/api/joke/%d", srv.URL, id), "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if body != "" {
+		t.Errorf("body = %q, want empty", body)
+	}
+	if resp.Header.Get("ETag") == "" || resp.Header.Get("Last-Modified") == "" {
+		t.Errorf("missing validators: ETag %q, Last-Modified %q", resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"))
+	}
+
+	getResp, _ := doRequest(t, http.MethodGet, fmt.Sprintf("%s/api/joke/%d", srv.URL, id), "", nil)
+	if getResp.Header.Get("ETag") != resp.Header.Get("ETag") {
+		t.Errorf("HEAD ETag %q differs from GET ETag %q", resp.Header.Get("ETag"), getResp.Header.Get("ETag"))
+	}
+
+	resp, body = doRequest(t, http.MethodHead, srv.URL+"/api/joke/9999", "", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("missing joke status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+	if body != "" {
+		t.Errorf("missing joke body = %q, want empty", body)
+	}
+}
+
+func TestRouterConditionalGetJoke(t *testing.T) {
+	srv, repo := newTestServer(t)
+
+	id, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "knock knock"})
+	if err != nil {
+		t.Fatalf("seeding joke: %v", err)
+	}
+	url := fmt.Sprintf("%s/api/joke/%d", srv.URL, id)
+
+	resp, _ := doRequest(t, http.MethodGet, url, "", nil)
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+
+	tests := []struct {
+		name          string
+		header        http.Header
+		wantStatus    int
+		wantBody      bool
+		wantPreferred string
+	}{
+		{"etag", http.Header{"If-None-Match": {etag}}, http.StatusNotModified, false, ""},
+		{"last modified", http.Header{"If-Modified-Since": {lastModified}}, http.StatusNotModified, false, ""},
+		{"stale etag", http.Header{"If-None-Match": {`"other"`}}, http.StatusOK, true, ""},
+		{"no-304", http.Header{"If-None-Match": {etag}, "Prefer": {"no-304"}}, http.StatusOK, true, "no-304"},
+		{"no-304 minimal", http.Header{"If-None-Match": {etag}, "Prefer": {"no-304, return=minimal"}}, http.StatusOK, false, "no-304, return=minimal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, http.MethodGet, url, "", tt.header)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := strings.Contains(body, "knock knock"); got != tt.wantBody {
+				t.Errorf("body = %q, want joke: %t", body, tt.wantBody)
+			}
+			if got := resp.Header.Get("Preference-Applied"); got != tt.wantPreferred {
+				t.Errorf("Preference-Applied = %q, want %q", got, tt.wantPreferred)
+			}
+			if got := resp.Header.Get("ETag"); got != etag {
+				t.Errorf("ETag = %q, want %q", got, etag)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterLatestJokes(t *testing.T) {
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		for i := 0; i < 8; i++ {
+			if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: fmt.Sprintf("joke %d", i)}); err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+		}
+	})
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantCount  int
+	}{
+		{query: "", wantStatus: http.StatusOK, wantCount: 5},
+		{query: "?count=3", wantStatus: http.StatusOK, wantCount: 3},
+		{query: "?count=50", wantStatus: http.StatusOK, wantCount: 8},
+		{query: "?count=51", wantStatus: http.StatusBadRequest},
+		{query: "?count=0", wantStatus: http.StatusBadRequest},
+		{query: "?count=abc", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/joke/latest"+tt.query, "", nil)
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("GET latest%s: status = %d, want %d", tt.query, resp.StatusCode, tt.wantStatus)
+			continue
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+
+		var latest struct {
+			Jokes []model.Joke `json:"jokes"`
+			Count int          `json:"count"`
+		}
+		if err := json.Unmarshal([]byte(body), &latest); err != nil {
+			t.Fatalf("decoding latest jokes: %v", err)
+		}
+		if len(latest.Jokes) != tt.wantCount || latest.Count != tt.wantCount {
+			t.Errorf("GET latest%s: got %d jokes, count %d, want %d", tt.query, len(latest.Jokes), latest.Count, tt.wantCount)
+		}
+	}
+}
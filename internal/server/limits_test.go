@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/handler"
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterMaxOffset(t *testing.T) {
+	srv, _ := newTestServerWithDeps(t, func(deps *Deps) {
+		deps.HandlerOptions.MaxOffset = 100
+	}, func(repo *repository.SQLiteJokeRepository) {
+		if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "the only joke"}); err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+	})
+
+	for _, path := range []string{"/api/joke/?offset=0", "/api/joke/?offset=100", "/api/joke/featured?offset=100"} {
+		resp, body := doRequest(t, http.MethodGet, srv.URL+path, "", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: status = %d, want %d: %s", path, resp.StatusCode, http.StatusOK, body)
+		}
+	}
+
+	for _, path := range []string{"/api/joke/?offset=101", "/api/joke/?offset=1000000&format=ndjson", "/api/joke/featured?offset=101", "/jokes?offset=101"} {
+		resp, body := doRequest(t, http.MethodGet, srv.URL+path, "", nil)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", path, resp.StatusCode, http.StatusBadRequest)
+			continue
+		}
+
+		var errResp handler.ErrorResponse
+		if err := json.Unmarshal([]byte(body), &errResp); err != nil {
+			t.Fatalf("%s: decoding error: %v", path, err)
+		}
+		if errResp.Code != "offset_too_large" {
+			t.Errorf("%s: code = %q, want offset_too_large", path, errResp.Code)
+		}
+	}
+}
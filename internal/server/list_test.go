@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterListJokesOutOfRange(t *testing.T) {
+	srv, repo := newTestServer(t)
+
+	for _, text := range []string{"one", "two", "three"} {
+		if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: text}); err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+	}
+
+	tests := []struct {
+		offset         int
+		wantJokes      int
+		wantOutOfRange bool
+	}{
+		{offset: 2, wantJokes: 1, wantOutOfRange: false},
+		{offset: 3, wantJokes: 0, wantOutOfRange: true},
+		{offset: 500, wantJokes: 0, wantOutOfRange: true},
+	}
+
+	for _, tt := range tests {
+		resp, body := doRequest(t, http.MethodGet, fmt.Sprintf("%s/api/joke/?offset=%d", srv.URL, tt.offset), "", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("offset %d: status = %d, want %d", tt.offset, resp.StatusCode, http.StatusOK)
+		}
+
+		var list struct {
+			Jokes      []model.Joke `json:"jokes"`
+			Total      int          `json:"total"`
+			OutOfRange bool         `json:"out_of_range"`
+		}
+		if err := json.Unmarshal([]byte(body), &list); err != nil {
+			t.Fatalf("decoding list: %v", err)
+		}
+
+		if len(list.Jokes) != tt.wantJokes || list.Total != 3 {
+			t.Errorf("offset %d: got %d jokes of %d, want %d of 3", tt.offset, len(list.Jokes), list.Total, tt.wantJokes)
+		}
+		if list.OutOfRange != tt.wantOutOfRange {
+			t.Errorf("offset %d: out_of_range = %v, want %v", tt.offset, list.OutOfRange, tt.wantOutOfRange)
+		}
+	}
+}
+
+func TestRouterEmptyCollection(t *testing.T) {
+	srv, repo := newTestServer(t)
+
+	type listResponse struct {
+		Jokes           []model.Joke `json:"jokes"`
+		EmptyCollection bool         `json:"empty_collection"`
+		Message         string       `json:"message"`
+	}
+
+	list := func(path string) listResponse {
+		t.Helper()
+
+		resp, body := doRequest(t, http.MethodGet, srv.URL+path, "", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: status = %d, want %d", path, resp.StatusCode, http.StatusOK)
+		}
+
+		var list listResponse
+		if err := json.Unmarshal([]byte(body), &list); err != nil {
+			t.Fatalf("GET %s: decoding list: %v", path, err)
+		}
+		return list
+	}
+
+	paths := []string{"/api/joke/", "/api/joke/?include_total=false", "/api/joke/featured"}
+
+	for _, path := range paths {
+		if got := list(path); !got.EmptyCollection || got.Message == "" {
+			t.Errorf("GET %s on an empty table: empty_collection = %v, message = %q", path, got.EmptyCollection, got.Message)
+		}
+	}
+
+	if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "not featured"}); err != nil {
+		t.Fatalf("seeding joke: %v", err)
+	}
+
+	// No featured jokes is an empty filter result, not an empty collection.
+	if got := list("/api/joke/featured"); len(got.Jokes) != 0 || got.EmptyCollection || got.Message != "" {
+		t.Errorf("featured with jokes in the table: got %d jokes, empty_collection = %v, message = %q", len(got.Jokes), got.EmptyCollection, got.Message)
+	}
+
+	for _, path := range paths[:2] {
+		if got := list(path); len(got.Jokes) != 1 || got.EmptyCollection {
+			t.Errorf("GET %s: got %d jokes, empty_collection = %v", path, len(got.Jokes), got.EmptyCollection)
+		}
+	}
+}
+
+func TestRouterShuffledList(t *testing.T) {
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		for i := 0; i < 12; i++ {
+			if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: fmt.Sprintf("joke %d", i)}); err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+		}
+	})
+
+	seen := make(map[int64]bool)
+	for offset := 0; ; offset += 5 {
+		url := fmt.Sprintf("%s/api/joke/?sort=random&seed=session-1&limit=5&offset=%d", srv.URL, offset)
+		resp, body := doRequest(t, http.MethodGet, url, "", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want %d (body %s)", resp.StatusCode, http.StatusOK, body)
+		}
+
+		var list struct {
+			Jokes   []model.Joke `json:"jokes"`
+			Total   int          `json:"total"`
+			HasMore bool         `json:"has_more"`
+		}
+		if err := json.Unmarshal([]byte(body), &list); err != nil {
+			t.Fatalf("decoding list: %v", err)
+		}
+		if list.Total != 12 {
+			t.Errorf("total = %d, want 12", list.Total)
+		}
+
+		for _, joke := range list.Jokes {
+			if seen[joke.ID] {
+				t.Fatalf("joke %d listed twice", joke.ID)
+			}
+			seen[joke.ID] = true
+		}
+
+		if !list.HasMore {
+			break
+		}
+	}
+
+	if len(seen) != 12 {
+		t.Errorf("paged through %d jokes, want 12", len(seen))
+	}
+
+	for _, query := range []string{"sort=random", "sort=popular"} {
+		resp, _ := doRequest(t, http.MethodGet, srv.URL+"/api/joke/?"+query, "", nil)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, resp.StatusCode, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestRouterListJokesPrefer(t *testing.T) {
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		for i := 0; i < 30; i++ {
+			if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: fmt.Sprintf("joke %d", i)}); err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+		}
+	})
+
+	resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/joke/", "", http.Header{"Prefer": {"max-count=25"}})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := resp.Header.Get("Preference-Applied"); got != "max-count=25" {
+		t.Errorf("Preference-Applied = %q, want %q", got, "max-count=25")
+	}
+
+	var list struct {
+		Jokes []model.Joke `json:"jokes"`
+		Limit int          `json:"limit"`
+	}
+	if err := json.Unmarshal([]byte(body), &list); err != nil {
+		t.Fatalf("decoding list: %v", err)
+	}
+	if len(list.Jokes) != 25 || list.Limit != 25 {
+		t.Errorf("got %d jokes with limit %d, want 25 and 25", len(list.Jokes), list.Limit)
+	}
+}
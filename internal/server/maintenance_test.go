@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterSchemaVersion(t *testing.T) {
+	srv, _ := newTestServer(t)
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+
+	resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/admin/schema-version", "", admin)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	var payload struct {
+		Version    int                      `json:"version"`
+		Migrations []*model.SchemaMigration `json:"migrations"`
+	}
+	if err := json.Unmarshal([]byte(body), &payload); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+
+	if len(payload.Migrations) == 0 {
+		t.Fatal("no migrations reported")
+	}
+	if latest := payload.Migrations[len(payload.Migrations)-1].Version; payload.Version != latest {
+		t.Errorf("version = %d, want latest migration %d", payload.Version, latest)
+	}
+}
+
+func TestRouterNormalizeJokes(t *testing.T) {
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		for i := 0; i < 3; i++ {
+			if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: fmt.Sprintf("joke %d", i)}); err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+		}
+	})
+
+	resp, _ := doRequest(t, http.MethodPost, srv.URL+"/api/admin/jokes/normalize", "", nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("without key: status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+
+	resp, body := doRequest(t, http.MethodPost, srv.URL+"/api/admin/jokes/normalize", "", http.Header{"Admin-Api-Key": {testAdminAPIKey}})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", resp.StatusCode, http.StatusOK, body)
+	}
+
+	var done repository.NormalizeProgress
+	if err := json.Unmarshal([]byte(body), &done); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if done.Scanned != 3 || done.Updated != 0 {
+		t.Errorf("result = %+v, want 3 scanned and none updated", done)
+	}
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterExportMarkdown(t *testing.T) {
+	texts := []string{
+		"Why did the chicken cross the road?",
+		"*bold* claims [link](x) and `code` <b>",
+		"- not a bullet\n1. not a list",
+	}
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		for _, text := range texts {
+			if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: text}); err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+		}
+	})
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+
+	resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/admin/jokes/export.md", "", admin)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := resp.Header.Get("Content-Type"); !strings.HasPrefix(got, "text/markdown") {
+		t.Errorf("Content-Type = %q, want text/markdown", got)
+	}
+
+	for _, want := range []string{
+		"1. Why did the chicken cross the road?\n",
+		"2. \\*bold\\* claims \\[link\\](x) and \\`code\\` \\<b\\>\n",
+		"3. \\- not a bullet\n   1\\. not a list\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("export missing %q:\n%s", want, body)
+		}
+	}
+}
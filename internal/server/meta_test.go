@@ -0,0 +1,56 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/handler"
+)
+
+func TestRouterMeta(t *testing.T) {
+	srv, _ := newTestServerWithDeps(t, func(deps *Deps) {
+		deps.MaxURLLength = 4096
+		deps.MaxStreams = 3
+		deps.HandlerOptions.MaxOffset = 500
+		deps.HandlerOptions.DuplicateSubmissionWindow = 90 * time.Second
+	})
+
+	resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/meta", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	var meta handler.MetaResponse
+	if err := json.Unmarshal([]byte(body), &meta); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+
+	if meta.Pagination.DefaultPageSize != 10 || meta.Pagination.MaxPreferredPageSize != 100 || meta.Pagination.MaxOffset != 500 {
+		t.Errorf("pagination = %+v, want default 10, preferred max 100, max offset 500", meta.Pagination)
+	}
+	want := handler.MetaLimits{
+		MaxRandomCount:                   20,
+		MaxURLLength:                     4096,
+		MaxStreams:                       3,
+		DuplicateSubmissionWindowSeconds: 90,
+	}
+	if meta.Limits != want {
+		t.Errorf("limits = %+v, want %+v", meta.Limits, want)
+	}
+	if !slices.Contains(meta.ListFormats, "ndjson") || !slices.Contains(meta.TimeFormats, "unix") {
+		t.Errorf("formats = %v, time formats = %v", meta.ListFormats, meta.TimeFormats)
+	}
+
+	// Unset limits are left out rather than reported as zero.
+	srv, _ = newTestServer(t)
+	_, body = doRequest(t, http.MethodGet, srv.URL+"/api/meta", "", nil)
+	for _, field := range []string{"max_offset", "max_streams", "duplicate_submission_window_seconds"} {
+		if strings.Contains(body, field) {
+			t.Errorf("unconfigured %s reported: %s", field, body)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+	"github.com/treboc/huhu-api/internal/views"
+)
+
+func TestRouterMostViewedJokes(t *testing.T) {
+	var ids []int64
+	var counter *views.Counter
+	srv, repo := newTestServerWithDeps(t, func(deps *Deps) {
+		counter = views.NewCounter(deps.Repo, deps.Logger)
+		deps.HandlerOptions.Views = counter
+	}, func(repo *repository.SQLiteJokeRepository) {
+		for _, text := range []string{"rarely viewed", "never viewed", "often viewed"} {
+			id, err := repo.CreateJoke(context.Background(), &model.Joke{Text: text})
+			if err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+			ids = append(ids, id)
+		}
+	})
+
+	for _, id := range []int64{ids[0], ids[2], ids[2], ids[2]} {
+		if resp, _ := doRequest(t, http.MethodGet, fmt.Sprintf("%s/api/joke/%d", srv.URL, id), "", nil); resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET joke %d: status %d", id, resp.StatusCode)
+		}
+	}
+
+	// Views are only written on flush.
+	if joke, err := repo.GetJoke(context.Background(), ids[2]); err != nil || joke.ViewCount != 0 {
+		t.Fatalf("view count before flush = %v, %v, want 0", joke, err)
+	}
+
+	if err := counter.Flush(context.Background()); err != nil {
+		t.Fatalf("flushing views: %v", err)
+	}
+
+	resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/joke/most-viewed", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	var list struct {
+		Jokes []model.Joke `json:"jokes"`
+	}
+	if err := json.Unmarshal([]byte(body), &list); err != nil {
+		t.Fatalf("decoding list: %v", err)
+	}
+
+	if len(list.Jokes) != 2 {
+		t.Fatalf("got %d jokes, want the 2 viewed ones", len(list.Jokes))
+	}
+	if list.Jokes[0].ID != ids[2] || list.Jokes[0].ViewCount != 3 {
+		t.Errorf("first = joke %d with %d views, want joke %d with 3", list.Jokes[0].ID, list.Jokes[0].ViewCount, ids[2])
+	}
+	if list.Jokes[1].ID != ids[0] || list.Jokes[1].ViewCount != 1 {
+		t.Errorf("second = joke %d with %d views, want joke %d with 1", list.Jokes[1].ID, list.Jokes[1].ViewCount, ids[0])
+	}
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterGetJokeMultipart(t *testing.T) {
+	var id int64
+	srv, _ := newTestServerWithDeps(t, func(deps *Deps) {
+		deps.ResponseCacheTTL = time.Minute
+	}, func(repo *repository.SQLiteJokeRepository) {
+		var err error
+		id, err = repo.CreateJoke(context.Background(), &model.Joke{Text: "Knock knock. Who's there? Multipart."})
+		if err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+	})
+	url := fmt.Sprintf("%s/api/joke/%d", srv.URL, id)
+
+	// Warm the cache with the JSON representation first.
+	resp, _ := doRequest(t, http.MethodGet, url, "", nil)
+	if got := resp.Header.Get("Content-Type"); got != "application/json" {
+		t.Fatalf("Content-Type = %q, want application/json", got)
+	}
+
+	resp, body := doRequest(t, http.MethodGet, url, "", http.Header{"Accept": {"multipart/mixed"}})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q, want multipart/mixed", resp.Header.Get("Content-Type"))
+	}
+
+	mr := multipart.NewReader(strings.NewReader(body), params["boundary"])
+
+	part, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("reading JSON part: %v", err)
+	}
+	if got := part.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("first part Content-Type = %q, want application/json", got)
+	}
+	var joke model.Joke
+	if err := json.NewDecoder(part).Decode(&joke); err != nil {
+		t.Fatalf("decoding JSON part: %v", err)
+	}
+
+	part, err = mr.NextPart()
+	if err != nil {
+		t.Fatalf("reading text part: %v", err)
+	}
+	if got := part.Header.Get("Content-Type"); !strings.HasPrefix(got, "text/plain") {
+		t.Errorf("second part Content-Type = %q, want text/plain", got)
+	}
+	text, err := io.ReadAll(part)
+	if err != nil {
+		t.Fatalf("reading text part: %v", err)
+	}
+
+	if joke.ID != id || string(text) != joke.Text || joke.Text != "Knock knock. Who's there? Multipart." {
+		t.Errorf("parts = %+v and %q, want joke %d with matching text", joke, text, id)
+	}
+
+	if _, err := mr.NextPart(); err != io.EOF {
+		t.Errorf("third part: err = %v, want io.EOF", err)
+	}
+}
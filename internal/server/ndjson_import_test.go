@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/handler"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterImportNDJSON(t *testing.T) {
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}, "Content-Type": {"application/x-ndjson"}}
+
+	listTexts := func(t *testing.T, repo *repository.SQLiteJokeRepository) []string {
+		t.Helper()
+
+		jokes, err := repo.ListJokes(context.Background(), 100, 0)
+		if err != nil {
+			t.Fatalf("listing jokes: %v", err)
+		}
+
+		var texts []string
+		for _, joke := range jokes {
+			texts = append(texts, joke.Text)
+		}
+		return texts
+	}
+
+	t.Run("valid stream", func(t *testing.T) {
+		srv, repo := newTestServer(t)
+
+		var body strings.Builder
+		for i := 0; i < 1203; i++ {
+			fmt.Fprintf(&body, "{\"text\": \"joke %d\"}\n", i)
+		}
+		// A repeat of an earlier joke, a blank line and a final line
+		// without a newline.
+		body.WriteString("{\"text\": \"joke 0\"}\n\n{\"setup\": \"Knock knock.\", \"punchline\": \"Who's there?\"}")
+
+		resp, respBody := doRequest(t, http.MethodPost, srv.URL+"/api/admin/jokes/import?format=ndjson", body.String(), admin)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", resp.StatusCode, http.StatusOK, respBody)
+		}
+
+		var got handler.NDJSONImportResponse
+		if err := json.Unmarshal([]byte(respBody), &got); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		if got.Lines != 1206 || got.Created != 1204 || got.Existing != 1 || got.Skipped != 0 || got.Batches != 3 {
+			t.Errorf("response counts = %d lines, %d created, %d existing, %d skipped, %d batches, want 1206, 1204, 1, 0, 3",
+				got.Lines, got.Created, got.Existing, got.Skipped, got.Batches)
+		}
+		if len(got.Succeeded) != 1205 || len(got.Failed) != 0 {
+			t.Fatalf("got %d succeeded and %d failed, want 1205 and 0", len(got.Succeeded), len(got.Failed))
+		}
+		// The repeated joke on line 1204 is the one from line 1, and the
+		// blank line 1205 is not an item.
+		if repeat := got.Succeeded[1203]; repeat.ID != 1204 || repeat.Status != "existing" || repeat.Joke.ID != got.Succeeded[0].Joke.ID {
+			t.Errorf("repeated line = %+v, want existing joke %d", repeat, got.Succeeded[0].Joke.ID)
+		}
+		if last := got.Succeeded[1204]; last.ID != 1206 || last.Status != "created" {
+			t.Errorf("last line = %+v, want line 1206 created", last)
+		}
+
+		count, err := repo.CountJokes(context.Background())
+		if err != nil {
+			t.Fatalf("counting jokes: %v", err)
+		}
+		if count != 1204 {
+			t.Errorf("stored %d jokes, want 1204", count)
+		}
+	})
+
+	body := "{\"text\": \"first\"}\n{\"text\": \n{\"text\": \"third\"}\n"
+
+	t.Run("bad line aborts", func(t *testing.T) {
+		srv, repo := newTestServer(t)
+
+		resp, respBody := doRequest(t, http.MethodPost, srv.URL+"/api/admin/jokes/import?format=ndjson", body, admin)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+		}
+
+		var errResp handler.ErrorResponse
+		if err := json.Unmarshal([]byte(respBody), &errResp); err != nil {
+			t.Fatalf("decoding error: %v", err)
+		}
+		if errResp.Code != "invalid_line" || !strings.HasPrefix(errResp.Error, "Line 2:") {
+			t.Errorf("error = %+v, want invalid_line at line 2", errResp)
+		}
+
+		if texts := listTexts(t, repo); !slices.Equal(texts, []string{"first"}) {
+			t.Errorf("stored %q, want only the line before the bad one", texts)
+		}
+	})
+
+	t.Run("bad line skipped", func(t *testing.T) {
+		srv, repo := newTestServerWithDeps(t, func(deps *Deps) {
+			deps.HandlerOptions.NDJSONImportSkipInvalid = true
+		})
+
+		resp, respBody := doRequest(t, http.MethodPost, srv.URL+"/api/admin/jokes/import?format=ndjson", body+"{\"text\": \"  \"}\n", admin)
+		if resp.StatusCode != http.StatusMultiStatus {
+			t.Fatalf("status = %d, want %d: %s", resp.StatusCode, http.StatusMultiStatus, respBody)
+		}
+
+		var got handler.NDJSONImportResponse
+		if err := json.Unmarshal([]byte(respBody), &got); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		if got.Lines != 4 || got.Created != 2 || got.Skipped != 2 || got.Batches != 1 {
+			t.Errorf("response counts = %+v, want 4 lines, 2 created, 2 skipped, 1 batch", got)
+		}
+
+		var lines []int
+		for _, item := range got.Succeeded {
+			lines = append(lines, item.ID)
+		}
+		if !slices.Equal(lines, []int{1, 3}) {
+			t.Errorf("succeeded lines = %v, want [1 3]", lines)
+		}
+		wantFailed := []handler.BatchFailure{
+			{ID: 2, Error: "Invalid JSON", Code: "invalid_json"},
+			{ID: 4, Error: "Joke text is required", Code: "invalid_joke"},
+		}
+		if !reflect.DeepEqual(got.Failed, wantFailed) {
+			t.Errorf("failed = %+v, want %+v", got.Failed, wantFailed)
+		}
+
+		if texts := listTexts(t, repo); !slices.Equal(texts, []string{"first", "third"}) {
+			t.Errorf("stored %q, want the valid lines", texts)
+		}
+	})
+
+	srv, _ := newTestServer(t)
+	if resp, _ := doRequest(t, http.MethodPost, srv.URL+"/api/admin/jokes/import", body, admin); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("without format: status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
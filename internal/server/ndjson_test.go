@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterListJokesNDJSON(t *testing.T) {
+	const count = 150
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		for i := 0; i < count; i++ {
+			if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: fmt.Sprintf("joke %d", i)}); err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+		}
+	})
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"format=ndjson", count},
+		{"format=ndjson&offset=140", 10},
+		{"format=ndjson&limit=25&offset=10", 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/joke?"+tt.query, "", nil)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+				t.Errorf("Content-Type = %q, want application/x-ndjson", ct)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+			if len(lines) != tt.want {
+				t.Fatalf("got %d lines, want %d", len(lines), tt.want)
+			}
+
+			for i, line := range lines {
+				var joke model.Joke
+				if err := json.Unmarshal([]byte(line), &joke); err != nil {
+					t.Fatalf("line %d is not a joke object: %v", i+1, err)
+				}
+				if joke.ID == 0 || joke.Text == "" {
+					t.Fatalf("line %d = %s, want a complete joke", i+1, line)
+				}
+			}
+		})
+	}
+
+	resp, _ := doRequest(t, http.MethodGet, srv.URL+"/api/joke?format=xml", "", nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown format status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/featureflag"
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterJokeNotFound(t *testing.T) {
+	newServer := func(jokeNotFound, seed bool) *httptest.Server {
+		repo, err := repository.NewSQLiteJokeRepository(filepath.Join(t.TempDir(), "jokes.db"), repository.Options{})
+		if err != nil {
+			t.Fatalf("creating repository: %v", err)
+		}
+		t.Cleanup(func() { repo.Close() })
+
+		if seed {
+			if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "A consolation joke"}); err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+		}
+
+		srv := httptest.NewServer(NewRouter(Deps{
+			Logger:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+			Repo:            repo,
+			FeatureFlagRepo: repo,
+			FeatureFlags:    featureflag.NewStore(repo),
+			AdminAPIKey:     testAdminAPIKey,
+			JokeNotFound:    jokeNotFound,
+		}))
+		t.Cleanup(srv.Close)
+
+		return srv
+	}
+
+	srv := newServer(true, true)
+	for _, path := range []string{"/api/nope", "/api/joke/1/nope"} {
+		resp, body := doRequest(t, http.MethodGet, srv.URL+path, "", nil)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want %d", path, resp.StatusCode, http.StatusNotFound)
+		}
+		if resp.Header.Get("X-Joke-Reason") == "" || !strings.Contains(body, `"joke":"A consolation joke"`) {
+			t.Errorf("%s: body = %s, want the random joke", path, body)
+		}
+	}
+
+	// Only API paths get the joke.
+	if resp, body := doRequest(t, http.MethodGet, srv.URL+"/nope", "", nil); resp.StatusCode != http.StatusNotFound || strings.Contains(body, "consolation") {
+		t.Errorf("/nope: status = %d, body %s, want a plain 404", resp.StatusCode, body)
+	}
+
+	// Without jokes to hand out it is a plain 404.
+	empty := newServer(true, false)
+	if resp, body := doRequest(t, http.MethodGet, empty.URL+"/api/nope", "", nil); resp.StatusCode != http.StatusNotFound || resp.Header.Get("X-Joke-Reason") != "" {
+		t.Errorf("empty database: status = %d, body %s, want a plain 404", resp.StatusCode, body)
+	}
+
+	disabled := newServer(false, true)
+	if resp, body := doRequest(t, http.MethodGet, disabled.URL+"/api/nope", "", nil); resp.StatusCode != http.StatusNotFound || strings.Contains(body, "consolation") {
+		t.Errorf("disabled: status = %d, body %s, want a plain 404", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/handler"
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/profanity"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterProfanityFilter(t *testing.T) {
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+	words := profanity.New([]string{"heck"})
+
+	t.Run("reject", func(t *testing.T) {
+		srv, _ := newTestServerWithDeps(t, func(deps *Deps) {
+			deps.HandlerOptions.Profanity = words
+			deps.HandlerOptions.ProfanityMode = handler.ProfanityReject
+		})
+
+		resp, body := doRequest(t, http.MethodPost, srv.URL+"/api/admin/joke", `{"text":"What the heck"}`, admin)
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Fatalf("status = %d, want %d (body %s)", resp.StatusCode, http.StatusUnprocessableEntity, body)
+		}
+
+		resp, body = doRequest(t, http.MethodPost, srv.URL+"/api/admin/joke", `{"setup":"Why?","punchline":"Heck knows"}`, admin)
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Fatalf("two-part status = %d, want %d (body %s)", resp.StatusCode, http.StatusUnprocessableEntity, body)
+		}
+	})
+
+	t.Run("mask", func(t *testing.T) {
+		srv, _ := newTestServerWithDeps(t, func(deps *Deps) {
+			deps.HandlerOptions.Profanity = words
+			deps.HandlerOptions.ProfanityMode = handler.ProfanityMask
+		})
+
+		resp, body := doRequest(t, http.MethodPost, srv.URL+"/api/admin/joke", `{"text":"What the heck"}`, admin)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("status = %d, want %d (body %s)", resp.StatusCode, http.StatusCreated, body)
+		}
+
+		var joke model.Joke
+		if err := json.Unmarshal([]byte(body), &joke); err != nil {
+			t.Fatalf("decoding joke: %v", err)
+		}
+		if joke.Text != "What the ****" {
+			t.Errorf("text = %q, want %q", joke.Text, "What the ****")
+		}
+	})
+
+	t.Run("clean random", func(t *testing.T) {
+		var cleanID int64
+		srv, _ := newTestServerWithDeps(t, func(deps *Deps) {
+			deps.HandlerOptions.Profanity = words
+		}, func(repo *repository.SQLiteJokeRepository) {
+			for i := 0; i < 5; i++ {
+				if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: fmt.Sprintf("heck number %d", i)}); err != nil {
+					t.Fatalf("seeding joke: %v", err)
+				}
+			}
+
+			id, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "A clean joke"})
+			if err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+			cleanID = id
+		})
+
+		for i := 0; i < 10; i++ {
+			resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/joke/random?clean=true", "", nil)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+			}
+
+			var joke model.Joke
+			if err := json.Unmarshal([]byte(body), &joke); err != nil {
+				t.Fatalf("decoding joke: %v", err)
+			}
+			if joke.ID != cleanID {
+				t.Fatalf("clean random returned joke %d (%q), want %d", joke.ID, joke.Text, cleanID)
+			}
+		}
+	})
+
+	t.Run("no clean jokes", func(t *testing.T) {
+		srv, _ := newTestServerWithDeps(t, func(deps *Deps) {
+			deps.HandlerOptions.Profanity = words
+		}, func(repo *repository.SQLiteJokeRepository) {
+			if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "heck"}); err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+		})
+
+		resp, _ := doRequest(t, http.MethodGet, srv.URL+"/api/joke/random?clean=true", "", nil)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterSeededRandomJoke(t *testing.T) {
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		for i := 0; i < 10; i++ {
+			if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: fmt.Sprintf("joke %d", i)}); err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+		}
+	})
+
+	randomJoke := func(t *testing.T, seed string) int64 {
+		t.Helper()
+
+		resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/joke/random?seed="+seed, "", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+
+		var joke model.Joke
+		if err := json.Unmarshal([]byte(body), &joke); err != nil {
+			t.Fatalf("decoding joke: %v", err)
+		}
+		return joke.ID
+	}
+
+	for _, seed := range []string{"abc", "another-seed", "42"} {
+		want := randomJoke(t, seed)
+		for i := 0; i < 5; i++ {
+			if got := randomJoke(t, seed); got != want {
+				t.Fatalf("seed %q returned joke %d, earlier %d", seed, got, want)
+			}
+		}
+	}
+}
+
+func TestRouterRandomDistribution(t *testing.T) {
+	ids := map[string]bool{}
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		for i := 0; i < 3; i++ {
+			id, err := repo.CreateJoke(context.Background(), &model.Joke{Text: fmt.Sprintf("joke %d", i)})
+			if err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+			ids[strconv.FormatInt(id, 10)] = true
+		}
+	})
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+
+	resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/admin/random/distribution?draws=200", "", admin)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d (body %s)", resp.StatusCode, http.StatusOK, body)
+	}
+
+	var result struct {
+		Draws  int            `json:"draws"`
+		Counts map[string]int `json:"counts"`
+	}
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+
+	total := 0
+	for id, count := range result.Counts {
+		if !ids[id] {
+			t.Errorf("drew unknown joke ID %s", id)
+		}
+		total += count
+	}
+	if result.Draws != 200 || total != 200 {
+		t.Errorf("draws = %d, counts add up to %d, want 200", result.Draws, total)
+	}
+
+	resp, _ = doRequest(t, http.MethodGet, srv.URL+"/api/admin/random/distribution?draws=1000000", "", admin)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status for too many draws = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestRouterRandomJokeMaxLength(t *testing.T) {
+	short := map[int64]bool{}
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		for _, text := range []string{
+			"Short one.",
+			"Tiny joke!",
+			strings.Repeat("This joke goes on and on. ", 10),
+			strings.Repeat("So does this one, much too long. ", 5),
+		} {
+			id, err := repo.CreateJoke(context.Background(), &model.Joke{Text: text})
+			if err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+			if len(text) <= 20 {
+				short[id] = true
+			}
+		}
+	})
+
+	for i := 0; i < 20; i++ {
+		resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/joke/random?max_length=20", "", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+
+		var joke model.Joke
+		if err := json.Unmarshal([]byte(body), &joke); err != nil {
+			t.Fatalf("decoding joke: %v", err)
+		}
+		if !short[joke.ID] {
+			t.Fatalf("max_length=20 returned joke %d with %d characters", joke.ID, len(joke.Text))
+		}
+	}
+
+	resp, _ := doRequest(t, http.MethodGet, srv.URL+"/api/joke/random?max_length=5", "", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("no fitting joke: status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+
+	resp, _ = doRequest(t, http.MethodGet, srv.URL+"/api/joke/random?max_length=0", "", nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("max_length=0: status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
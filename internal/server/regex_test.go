@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterListJokesRegex(t *testing.T) {
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		for _, text := range []string{"Knock knock", "Why did the chicken cross the road?", "knock KNOCK, who's there?", "A horse walks into a bar"} {
+			if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: text}); err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+		}
+	})
+
+	resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/joke/?regex="+url.QueryEscape(`(?i)^knock\s+knock`), "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", resp.StatusCode, http.StatusOK, body)
+	}
+
+	var list struct {
+		Jokes   []model.Joke `json:"jokes"`
+		HasMore bool         `json:"has_more"`
+		Total   *int         `json:"total"`
+	}
+	if err := json.Unmarshal([]byte(body), &list); err != nil {
+		t.Fatalf("decoding list: %v", err)
+	}
+
+	var texts []string
+	for _, joke := range list.Jokes {
+		texts = append(texts, joke.Text)
+	}
+	if want := []string{"Knock knock", "knock KNOCK, who's there?"}; !slices.Equal(texts, want) {
+		t.Errorf("jokes = %q, want %q", texts, want)
+	}
+	if list.Total == nil || *list.Total != 2 {
+		t.Errorf("total = %v, want 2", list.Total)
+	}
+
+	// The regex combines with the created_at filters instead of being rejected.
+	resp, body = doRequest(t, http.MethodGet, srv.URL+"/api/joke/?regex=%5Eknock&created_after=2000-01-01T00:00:00Z", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("regex with created_after: status = %d, want %d: %s", resp.StatusCode, http.StatusOK, body)
+	}
+	if !strings.Contains(body, `"total":1`) {
+		t.Errorf("regex with created_after: body = %s, want total 1", body)
+	}
+
+	for _, pattern := range []string{"(unclosed", `\p{Nope}`, strings.Repeat("a", 201), "((a{50}){50})"} {
+		resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/joke/?regex="+url.QueryEscape(pattern), "", nil)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("regex %.20q: status = %d, want %d: %s", pattern, resp.StatusCode, http.StatusBadRequest, body)
+		}
+	}
+}
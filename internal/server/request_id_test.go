@@ -0,0 +1,95 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/featureflag"
+	"github.com/treboc/huhu-api/internal/handler"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// syncBuffer is a bytes.Buffer that log handlers on server goroutines can
+// write to while the test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestRouterRequestIDCorrelation(t *testing.T) {
+	repo, err := repository.NewSQLiteJokeRepository(filepath.Join(t.TempDir(), "jokes.db"), repository.Options{})
+	if err != nil {
+		t.Fatalf("creating repository: %v", err)
+	}
+	// A closed database makes every write fail, so the handler logs an error.
+	repo.Close()
+
+	var logs syncBuffer
+	srv := httptest.NewServer(NewRouter(Deps{
+		Logger:          slog.New(slog.NewJSONHandler(&logs, nil)),
+		Repo:            repo,
+		FeatureFlagRepo: repo,
+		FeatureFlags:    featureflag.NewStore(repo),
+		AdminAPIKey:     testAdminAPIKey,
+	}))
+	t.Cleanup(srv.Close)
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+
+	resp, body := doRequest(t, http.MethodPost, srv.URL+"/api/admin/joke", `{"text":"knock knock"}`, admin)
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d (body %s)", resp.StatusCode, http.StatusInternalServerError, body)
+	}
+
+	requestID := resp.Header.Get("X-Request-ID")
+	if requestID == "" {
+		t.Fatal("response has no X-Request-ID header")
+	}
+
+	var errResp handler.ErrorResponse
+	if err := json.Unmarshal([]byte(body), &errResp); err != nil {
+		t.Fatalf("decoding error response: %v", err)
+	}
+	if errResp.RequestID != requestID {
+		t.Errorf("error body request_id = %q, want %q", errResp.RequestID, requestID)
+	}
+
+	var logged bool
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry struct {
+			Msg       string `json:"msg"`
+			RequestID string `json:"request_id"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("decoding log line %q: %v", line, err)
+		}
+		if entry.Msg != "Failed to create joke" {
+			continue
+		}
+		logged = true
+		if entry.RequestID != requestID {
+			t.Errorf("handler log request_id = %q, want %q", entry.RequestID, requestID)
+		}
+	}
+	if !logged {
+		t.Errorf("no handler log line for the failed create in:\n%s", logs.String())
+	}
+}
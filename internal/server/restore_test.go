@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/backup"
+	"github.com/treboc/huhu-api/internal/handler"
+	"github.com/treboc/huhu-api/internal/model"
+)
+
+func TestRouterRestoreBackup(t *testing.T) {
+	dir := t.TempDir()
+	srv, repo := newTestServerWithDeps(t, func(deps *Deps) {
+		deps.HandlerOptions.BackupDir = dir
+	})
+	ctx := context.Background()
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		id, err := repo.CreateJoke(ctx, &model.Joke{Text: fmt.Sprintf("joke %d", i)})
+		if err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	path, err := backup.NewWriter(repo, dir, 3, slog.New(slog.NewTextHandler(io.Discard, nil))).Backup(ctx)
+	if err != nil {
+		t.Fatalf("writing backup: %v", err)
+	}
+
+	// Change the jokes after the backup was taken.
+	if err := repo.DeleteJoke(ctx, ids[1]); err != nil {
+		t.Fatalf("deleting joke: %v", err)
+	}
+	added, err := repo.CreateJoke(ctx, &model.Joke{Text: "added after the backup"})
+	if err != nil {
+		t.Fatalf("creating joke: %v", err)
+	}
+
+	resp, body := doRequest(t, http.MethodPost, srv.URL+"/api/admin/restore", fmt.Sprintf(`{"file": %q}`, filepath.Base(path)), admin)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", resp.StatusCode, http.StatusOK, body)
+	}
+
+	var restored handler.RestoreResponse
+	if err := json.Unmarshal([]byte(body), &restored); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if restored.Restored != 3 {
+		t.Errorf("restored = %d, want 3", restored.Restored)
+	}
+
+	for i, id := range ids {
+		joke, err := repo.GetJoke(ctx, id)
+		if err != nil {
+			t.Fatalf("joke %d after restore: %v", id, err)
+		}
+		if want := fmt.Sprintf("joke %d", i); joke.Text != want {
+			t.Errorf("joke %d text = %q, want %q", id, joke.Text, want)
+		}
+	}
+	if _, err := repo.GetJoke(ctx, added); err == nil {
+		t.Errorf("joke %d added after the backup survived the restore", added)
+	}
+
+	tests := []struct {
+		file       string
+		wantStatus int
+	}{
+		{"../jokes.db", http.StatusBadRequest},
+		{"../../etc/passwd", http.StatusBadRequest},
+		{"/etc/passwd", http.StatusBadRequest},
+		{"nested/" + filepath.Base(path), http.StatusBadRequest},
+		{"..", http.StatusBadRequest},
+		{"", http.StatusBadRequest},
+		{"jokes-19700101T000000Z.json", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		resp, _ := doRequest(t, http.MethodPost, srv.URL+"/api/admin/restore", fmt.Sprintf(`{"file": %q}`, tt.file), admin)
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("file %q: status = %d, want %d", tt.file, resp.StatusCode, tt.wantStatus)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/cors"
+	"github.com/treboc/huhu-api/internal/consistency"
+	"github.com/treboc/huhu-api/internal/featureflag"
+	"github.com/treboc/huhu-api/internal/handler"
+	internalMiddleware "github.com/treboc/huhu-api/internal/middleware"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+// Deps are the dependencies needed to build the API router.
+type Deps struct {
+	Logger          *slog.Logger
+	Repo            repository.JokeRepository
+	FeatureFlagRepo repository.FeatureFlagRepository
+	MaintenanceRepo repository.MaintenanceRepository
+	HealthRepo      handler.HealthRepository
+	FeatureFlags    *featureflag.Store
+	Consistency     *consistency.Checker
+	AdminAPIKey     string
+	HandlerOptions  handler.Options
+	StartedAt       time.Time
+	MaxURLLength    int
+
+	// AdminJWT, when set, accepts bearer tokens with the admin scope on the
+	// admin API. An empty AdminAPIKey then makes them the only way in.
+	AdminJWT *internalMiddleware.JWTVerifier
+
+	// JokeNotFound answers unknown /api paths with a random joke.
+	JokeNotFound bool
+
+	// AllowOrigin decides which origins may make CORS requests. All origins
+	// are allowed when it is nil.
+	AllowOrigin func(origin string) bool
+
+	// CompressResponses gzips responses for clients that accept it, at
+	// CompressionLevel (1 to 9, 0 for the default level).
+	CompressResponses bool
+	CompressionLevel  int
+
+	// AdminQueryKey accepts the admin key from the api_key query parameter
+	// over TLS, for clients that cannot set headers.
+	AdminQueryKey bool
+
+	// AccessLogSampleEvery logs only one in this many successful requests.
+	// Failed requests are always logged.
+	AccessLogSampleEvery int
+
+	// ReadAPIKey protects the reads under ReadProtectedPaths, which are
+	// path.Match patterns such as /api/collections/*. The admin key is
+	// accepted for them as well. All reads are public without a read key.
+	ReadAPIKey         string
+	ReadProtectedPaths []string
+
+	// ServerTiming reports database and handler time in the Server-Timing
+	// response header. Database time is only known when Repo reports to
+	// middleware.RecordQueryTiming.
+	ServerTiming bool
+
+	// CircuitBreaker, when set, answers API requests with 503 while it is
+	// open. Repo should report to it through a CircuitBreakerJokeRepository.
+	CircuitBreaker *repository.CircuitBreaker
+
+	// RejectSuspiciousQueries answers requests whose numeric or enum query
+	// parameters contain SQL meta-sequences with 400.
+	RejectSuspiciousQueries bool
+
+	// ResponseCacheTTL caches public read responses in memory for this
+	// long. Admin writes invalidate the cache. Cache hits don't record joke
+	// accesses for the stale report or views. Zero disables the cache.
+	ResponseCacheTTL time.Duration
+
+	// BodyReadTimeout limits how long requests with a body may take to send
+	// it. Handlers answer a body that doesn't arrive in time with 408. Zero
+	// disables the limit.
+	BodyReadTimeout time.Duration
+
+	// MaxStreams caps concurrent streaming responses: random joke streams
+	// and told jokes. Zero means no cap.
+	MaxStreams int
+
+	// ContentSecurityPolicy is sent with every response, next to the other
+	// security headers. Empty leaves the header out.
+	ContentSecurityPolicy string
+
+	// CacheControl sets the Cache-Control header of successful reads per
+	// path, for handlers that don't set one themselves. The first matching
+	// policy applies.
+	CacheControl []internalMiddleware.CacheControlPolicy
+}
+
+// TellJokesFlag is the feature flag that enables the experimental
+// GET /api/joke/{id}/tell event stream. It is on unless turned off, since
+// the route predates the flag.
+const TellJokesFlag = "tell_jokes"
+
+// uncompressedPaths are streaming and byte range routes that must never be
+// compressed.
+var uncompressedPaths = []string{
+	"/api/joke/*/tell",
+	"/api/joke/random/stream",
+	"/api/admin/jokes/export",
+}
+
+// structuredQueryParams are the query parameters that only ever hold
+// numbers, booleans, durations or enum values. Free text parameters such as
+// text and seed are left out.
+var structuredQueryParams = []string{
+	"limit", "offset", "count", "n", "days", "draws", "max_length", "include_total",
+	"sort", "format", "time_format", "period", "clean", "interval", "reveal", "idempotent", "verbose",
+}
+
+// NewRouter wires up all middleware and routes of the API.
+func NewRouter(deps Deps) http.Handler {
+	jokeHandler := handler.NewJokeHandler(deps.Repo, deps.Logger, deps.HandlerOptions)
+	featureFlagHandler := handler.NewFeatureFlagHandler(deps.FeatureFlagRepo, deps.FeatureFlags, deps.Logger)
+	collectionHandler := handler.NewCollectionHandler(deps.Repo, deps.Logger, deps.HandlerOptions)
+
+	r := chi.NewRouter()
+
+	r.Use(middleware.RequestID)
+	r.Use(internalMiddleware.CorrelationID)
+	r.Use(internalMiddleware.RequestIDHeader)
+	r.Use(internalMiddleware.SecurityHeaders(deps.ContentSecurityPolicy))
+	r.Use(middleware.RealIP)
+	r.Use(internalMiddleware.MaxURLLength(deps.MaxURLLength))
+	if deps.BodyReadTimeout > 0 {
+		r.Use(internalMiddleware.BodyReadTimeout(deps.BodyReadTimeout))
+	}
+	r.Use(internalMiddleware.Logger(deps.Logger, deps.AccessLogSampleEvery))
+	r.Use(middleware.Recoverer)
+
+	if len(deprecations) > 0 {
+		r.Use(internalMiddleware.Deprecated(deps.Logger, deprecations...))
+	}
+
+	if deps.RejectSuspiciousQueries {
+		r.Use(internalMiddleware.SuspiciousQuery(deps.Logger, structuredQueryParams...))
+	}
+
+	allowOrigin := deps.AllowOrigin
+	if allowOrigin == nil {
+		allowOrigin = func(origin string) bool { return true }
+	}
+
+	r.Use(cors.Handler(cors.Options{
+		AllowOriginFunc: func(r *http.Request, origin string) bool {
+			return allowOrigin(origin)
+		},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Read-API-Key", "Prefer"},
+		ExposedHeaders:   []string{"Link", "Preference-Applied", "Deprecation", "Sunset"},
+		AllowCredentials: true,
+		MaxAge:           300,
+	}))
+
+	if deps.CompressResponses {
+		r.Use(internalMiddleware.Compress(deps.CompressionLevel, uncompressedPaths...))
+	}
+
+	if deps.ReadAPIKey != "" && len(deps.ReadProtectedPaths) > 0 {
+		r.Use(internalMiddleware.ReadAuth(deps.ReadAPIKey, deps.AdminAPIKey, deps.ReadProtectedPaths...))
+	}
+
+	if deps.ServerTiming {
+		r.Use(internalMiddleware.ServerTiming)
+	}
+
+	if len(deps.CacheControl) > 0 {
+		r.Use(internalMiddleware.CacheControl(deps.CacheControl...))
+	}
+
+	if deps.FeatureFlags != nil {
+		r.Use(internalMiddleware.DebugResponses(deps.FeatureFlags))
+	}
+
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Hello from the Jokes API!"))
+	})
+
+	r.Get("/healthz", handler.HandleHealthz(deps.HealthRepo, deps.Logger))
+
+	// cache serves read routes from the response cache when it is enabled.
+	// Random picks and streams are never cached.
+	cache := func(next http.Handler) http.Handler { return next }
+	var responseCache *internalMiddleware.ResponseCache
+	if deps.ResponseCacheTTL > 0 {
+		responseCache = internalMiddleware.NewResponseCache(deps.ResponseCacheTTL)
+		cache = responseCache.Handler
+	}
+
+	r.With(cache).Get("/jokes", jokeHandler.ListJokesHTML)
+
+	// experimental hides a route behind a 404 until its feature flag is
+	// enabled. Without a flag store every route is served.
+	experimental := func(flag string) func(http.Handler) http.Handler {
+		if deps.FeatureFlags == nil {
+			return func(next http.Handler) http.Handler { return next }
+		}
+		return internalMiddleware.RequireFeature(deps.FeatureFlags, flag)
+	}
+
+	// streams caps the long-lived streaming routes together.
+	streams := func(next http.Handler) http.Handler { return next }
+	if deps.MaxStreams > 0 {
+		streams = internalMiddleware.MaxStreams(deps.MaxStreams)
+	}
+
+	jokeRouter := chi.NewRouter()
+	jokeRouter.With(cache).Get("/", jokeHandler.ListJokes)
+	jokeRouter.Get("/random", jokeHandler.GetRandomJoke)
+	jokeRouter.With(streams).Get("/random/stream", jokeHandler.StreamRandomJokes)
+	jokeRouter.Get("/checksum", jokeHandler.GetJokesChecksum)
+	jokeRouter.With(cache).Get("/featured", jokeHandler.ListFeaturedJokes)
+	jokeRouter.With(cache).Get("/most-viewed", jokeHandler.ListMostViewedJokes)
+	jokeRouter.With(cache).Get("/latest", jokeHandler.ListLatestJokes)
+	jokeRouter.With(cache).Get("/{id}", jokeHandler.GetJoke)
+	jokeRouter.Head("/{id}", jokeHandler.GetJoke)
+	jokeRouter.With(cache).Get("/{id}/qr", jokeHandler.GetJokeQRCode)
+	jokeRouter.With(streams, experimental(TellJokesFlag)).Get("/{id}/tell", jokeHandler.TellJoke)
+
+	adminRouter := chi.NewRouter()
+	adminRouter.Use(internalMiddleware.AdminAuth(deps.AdminAPIKey, internalMiddleware.AdminAuthOptions{
+		AllowQueryKey: deps.AdminQueryKey,
+		Logger:        deps.Logger,
+		JWT:           deps.AdminJWT,
+	}))
+	if responseCache != nil {
+		adminRouter.Use(responseCache.InvalidateOnWrite)
+	}
+	adminRouter.Post("/joke", jokeHandler.CreateJoke)
+	adminRouter.Post("/joke/validate", jokeHandler.ValidateJoke)
+	adminRouter.Get("/joke/by-text", jokeHandler.FindJokesByText)
+	adminRouter.Put("/joke/{id}", jokeHandler.UpdateJoke)
+	adminRouter.Delete("/joke/{id}", jokeHandler.DeleteJoke)
+	adminRouter.Put("/joke/{id}/featured", jokeHandler.FeatureJoke)
+	adminRouter.Delete("/joke/{id}/featured", jokeHandler.UnfeatureJoke)
+	adminRouter.Post("/joke/{id}/reset-counters", jokeHandler.ResetJokeCounters)
+	adminRouter.Post("/jokes/import", jokeHandler.ImportJokes)
+	adminRouter.Post("/jokes/import/remote", jokeHandler.ImportRemoteJokes)
+	adminRouter.Post("/jokes/upsert", jokeHandler.UpsertJokes)
+	adminRouter.Post("/restore", jokeHandler.RestoreBackup)
+	adminRouter.Get("/jokes/stale", jokeHandler.ListStaleJokes)
+	adminRouter.Get("/jokes/sample", jokeHandler.GetRandomSample)
+	adminRouter.Get("/jokes/export", jokeHandler.ExportJokes)
+	adminRouter.Get("/jokes/export.md", jokeHandler.ExportJokesMarkdown)
+	adminRouter.Get("/stats/daily-counts", jokeHandler.GetDailyCounts)
+	adminRouter.Get("/stats/length-percentiles", jokeHandler.GetLengthPercentiles)
+	adminRouter.Get("/random/distribution", jokeHandler.GetRandomDistribution)
+	adminRouter.Post("/collections", collectionHandler.CreateCollection)
+	adminRouter.Put("/collections/{id}/jokes/{jokeID}", collectionHandler.AddJoke)
+	adminRouter.Delete("/collections/{id}/jokes/{jokeID}", collectionHandler.RemoveJoke)
+	adminRouter.Get("/runtime", handler.HandleRuntime(deps.StartedAt))
+	adminRouter.Get("/routes", handler.HandleRoutes(r, deps.Logger))
+	adminRouter.Get("/features", featureFlagHandler.ListFeatureFlags)
+	adminRouter.Put("/features/{name}", featureFlagHandler.SetFeatureFlag)
+	if deps.Consistency != nil {
+		adminRouter.Post("/maintenance/orphans", handler.HandleOrphanCleanup(deps.Consistency, deps.Logger))
+	}
+	if deps.MaintenanceRepo != nil {
+		adminRouter.Get("/schema-version", handler.HandleSchemaVersion(deps.MaintenanceRepo, deps.Logger))
+		adminRouter.Post("/jokes/normalize", handler.HandleRenormalize(deps.MaintenanceRepo, deps.Logger))
+	}
+
+	apiRouter := chi.NewRouter()
+	if deps.CircuitBreaker != nil {
+		apiRouter.Use(internalMiddleware.ShortCircuit(deps.CircuitBreaker))
+	}
+	if deps.JokeNotFound {
+		// Must be set before mounting so the subrouters inherit it.
+		apiRouter.NotFound(jokeHandler.NotFoundJoke)
+	}
+	apiRouter.Mount("/admin", adminRouter)
+	apiRouter.Mount("/joke", jokeRouter)
+	apiRouter.Get("/surprise", jokeHandler.Surprise)
+	apiRouter.Get("/meta", handler.HandleMeta(deps.HandlerOptions, handler.ServerLimits{
+		MaxURLLength: deps.MaxURLLength,
+		MaxStreams:   deps.MaxStreams,
+	}))
+	apiRouter.With(cache).Get("/collections/{id}", collectionHandler.GetCollection)
+
+	r.Mount("/api", apiRouter)
+
+	return r
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/featureflag"
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+const testAdminAPIKey = "test-admin-key"
+
+// newTestServer builds the full router on top of a fresh SQLite database.
+// The setup functions run before feature flags are loaded.
+func newTestServer(t *testing.T, setup ...func(*repository.SQLiteJokeRepository)) (*httptest.Server, *repository.SQLiteJokeRepository) {
+	t.Helper()
+
+	return newTestServerWithDeps(t, func(*Deps) {}, setup...)
+}
+
+// newTestServerWithDeps is newTestServer with a hook to adjust the router
+// dependencies.
+func newTestServerWithDeps(t *testing.T, configure func(*Deps), setup ...func(*repository.SQLiteJokeRepository)) (*httptest.Server, *repository.SQLiteJokeRepository) {
+	t.Helper()
+
+	repo, err := repository.NewSQLiteJokeRepository(filepath.Join(t.TempDir(), "jokes.db"), repository.Options{})
+	if err != nil {
+		t.Fatalf("creating repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	for _, fn := range setup {
+		fn(repo)
+	}
+
+	flags := featureflag.NewStore(repo)
+	if err := flags.Refresh(context.Background()); err != nil {
+		t.Fatalf("loading feature flags: %v", err)
+	}
+
+	deps := Deps{
+		Logger:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Repo:            repo,
+		FeatureFlagRepo: repo,
+		MaintenanceRepo: repo,
+		HealthRepo:      repo,
+		FeatureFlags:    flags,
+		AdminAPIKey:     testAdminAPIKey,
+	}
+	configure(&deps)
+
+	srv := httptest.NewServer(NewRouter(deps))
+	t.Cleanup(srv.Close)
+
+	return srv, repo
+}
+
+func doRequest(t *testing.T, method, url, body string, header http.Header) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("building request: %v", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+
+	return resp, string(data)
+}
+
+func TestRouterAdminRequiresKey(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	resp, _ := doRequest(t, http.MethodPost, srv.URL+"/api/admin/joke", `{"text":"knock knock"}`, nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestRouterCreateAndGetJoke(t *testing.T) {
+	srv, _ := newTestServer(t)
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+
+	resp, body := doRequest(t, http.MethodPost, srv.URL+"/api/admin/joke", `{"text":"knock knock"}`, admin)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create status = %d, want %d (body %s)", resp.StatusCode, http.StatusCreated, body)
+	}
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		t.Fatal("create response has no Location header")
+	}
+
+	resp, body = doRequest(t, http.MethodGet, srv.URL+location, "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("get status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	var joke model.Joke
+	if err := json.Unmarshal([]byte(body), &joke); err != nil {
+		t.Fatalf("decoding joke: %v", err)
+	}
+	if joke.Text != "knock knock" {
+		t.Errorf("text = %q, want %q", joke.Text, "knock knock")
+	}
+}
+
+func TestRouterCreateJokeConcurrentDuplicates(t *testing.T) {
+	srv, repo := newTestServer(t)
+
+	const workers = 16
+	var wg sync.WaitGroup
+	statuses := make([]int, workers)
+	locations := make([]string, workers)
+	errs := make([]error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/admin/joke", strings.NewReader(`{"text":"knock knock"}`))
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			req.Header.Set("Admin-Api-Key", testAdminAPIKey)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			resp.Body.Close()
+			statuses[i], locations[i] = resp.StatusCode, resp.Header.Get("Location")
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for i := range statuses {
+		if errs[i] != nil {
+			t.Fatalf("request %d: %v", i, errs[i])
+		}
+		switch statuses[i] {
+		case http.StatusCreated:
+			created++
+		case http.StatusOK:
+		default:
+			t.Errorf("request %d: status = %d, want %d or %d", i, statuses[i], http.StatusCreated, http.StatusOK)
+		}
+		if locations[i] != locations[0] {
+			t.Errorf("request %d: Location = %q, request 0 got %q", i, locations[i], locations[0])
+		}
+	}
+	if created != 1 {
+		t.Errorf("%d requests created the joke, want 1", created)
+	}
+
+	if count, err := repo.CountJokes(context.Background()); err != nil || count != 1 {
+		t.Errorf("CountJokes = %d, %v, want 1", count, err)
+	}
+}
+
+func TestRouterListJokes(t *testing.T) {
+	srv, repo := newTestServer(t)
+
+	for _, text := range []string{"one", "two", "three"} {
+		if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: text}); err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+	}
+
+	resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/joke/?limit=2", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	var list struct {
+		Jokes      []model.Joke `json:"jokes"`
+		Total      int          `json:"total"`
+		TotalPages int          `json:"total_pages"`
+	}
+	if err := json.Unmarshal([]byte(body), &list); err != nil {
+		t.Fatalf("decoding list: %v", err)
+	}
+	if len(list.Jokes) != 2 {
+		t.Errorf("got %d jokes, want 2", len(list.Jokes))
+	}
+	if list.Total != 3 {
+		t.Errorf("total = %d, want 3", list.Total)
+	}
+	if list.TotalPages != 2 {
+		t.Errorf("total_pages = %d, want 2", list.TotalPages)
+	}
+}
+
+func TestRouterGetMissingJoke(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	resp, _ := doRequest(t, http.MethodGet, srv.URL+"/api/joke/42", "", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
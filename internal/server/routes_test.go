@@ -0,0 +1,46 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/handler"
+)
+
+func TestRouterRoutes(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	if resp, _ := doRequest(t, http.MethodGet, srv.URL+"/api/admin/routes", "", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("without admin key: status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+
+	resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/admin/routes", "", http.Header{"Admin-Api-Key": {testAdminAPIKey}})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	var payload handler.RoutesResponse
+	if err := json.Unmarshal([]byte(body), &payload); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+
+	registered := make(map[string]bool)
+	for _, route := range payload.Routes {
+		registered[route.Method+" "+route.Pattern] = true
+	}
+
+	for _, want := range []string{
+		"GET /healthz",
+		"GET /api/joke/",
+		"GET /api/joke/{id}",
+		"HEAD /api/joke/{id}",
+		"POST /api/admin/joke",
+		"GET /api/admin/routes",
+		"GET /api/admin/schema-version",
+	} {
+		if !registered[want] {
+			t.Errorf("route %q missing from %v", want, payload.Routes)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/handler"
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterRandomSample(t *testing.T) {
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		for i := 0; i < 8; i++ {
+			if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: fmt.Sprintf("joke %d", i)}); err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+		}
+	})
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+
+	sample := func(query string) handler.JokeSampleResponse {
+		t.Helper()
+
+		resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/admin/jokes/sample"+query, "", admin)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status = %d, want %d: %s", query, resp.StatusCode, http.StatusOK, body)
+		}
+
+		var got handler.JokeSampleResponse
+		if err := json.Unmarshal([]byte(body), &got); err != nil {
+			t.Fatalf("decoding sample: %v", err)
+		}
+		return got
+	}
+
+	first := sample("?n=3&seed=42")
+	if first.Count != 3 || len(first.Jokes) != 3 || first.Seed != 42 {
+		t.Fatalf("sample = %+v, want 3 jokes for seed 42", first)
+	}
+	again := sample("?n=3&seed=42")
+	for i := range first.Jokes {
+		if again.Jokes[i].ID != first.Jokes[i].ID {
+			t.Fatalf("same seed gave different samples")
+		}
+	}
+
+	if all := sample("?n=20&seed=7"); all.Count != 8 {
+		t.Errorf("oversized sample count = %d, want 8", all.Count)
+	}
+
+	for _, query := range []string{"", "?seed=1", "?n=0&seed=1", "?n=1001&seed=1", "?n=3", "?n=3&seed=abc"} {
+		resp, _ := doRequest(t, http.MethodGet, srv.URL+"/api/admin/jokes/sample"+query, "", admin)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%q: status = %d, want %d", query, resp.StatusCode, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	internalMiddleware "github.com/treboc/huhu-api/internal/middleware"
+)
+
+func TestRouterSecurityHeaders(t *testing.T) {
+	srv, _ := newTestServerWithDeps(t, func(deps *Deps) {
+		deps.ContentSecurityPolicy = internalMiddleware.DefaultContentSecurityPolicy
+	})
+
+	for _, path := range []string{"/jokes", "/api/joke/", "/api/joke/999"} {
+		resp, _ := doRequest(t, http.MethodGet, srv.URL+path, "", nil)
+
+		want := map[string]string{
+			"X-Content-Type-Options":  "nosniff",
+			"X-Frame-Options":         "DENY",
+			"Referrer-Policy":         "no-referrer",
+			"Content-Security-Policy": internalMiddleware.DefaultContentSecurityPolicy,
+		}
+		for name, value := range want {
+			if got := resp.Header.Get(name); got != value {
+				t.Errorf("%s: %s = %q, want %q", path, name, got, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/handler"
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterStableSort(t *testing.T) {
+	early := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		// IDs don't follow creation order, and most timestamps tie.
+		jokes := []*model.Joke{
+			{ID: 1, Text: "joke 1", CreatedAt: late, UpdatedAt: late},
+			{ID: 2, Text: "joke 2", CreatedAt: early, UpdatedAt: early},
+			{ID: 3, Text: "joke 3", CreatedAt: late, UpdatedAt: late},
+			{ID: 4, Text: "joke 4", CreatedAt: early, UpdatedAt: early},
+			{ID: 5, Text: "joke 5", CreatedAt: early, UpdatedAt: early},
+		}
+		if err := repo.ReplaceJokes(context.Background(), jokes); err != nil {
+			t.Fatalf("seeding jokes: %v", err)
+		}
+	})
+
+	listIDs := func(query string) []int64 {
+		t.Helper()
+
+		resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/joke/"+query, "", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status = %d, want %d: %s", query, resp.StatusCode, http.StatusOK, body)
+		}
+
+		var list handler.JokeListResponse
+		if err := json.Unmarshal([]byte(body), &list); err != nil {
+			t.Fatalf("decoding list: %v", err)
+		}
+
+		var ids []int64
+		for _, joke := range list.Jokes {
+			ids = append(ids, joke.ID)
+		}
+		return ids
+	}
+
+	want := []int64{2, 4, 5, 1, 3}
+	for i := 0; i < 5; i++ {
+		if got := listIDs("?sort=stable"); !slices.Equal(got, want) {
+			t.Fatalf("call %d: ids = %v, want %v", i, got, want)
+		}
+	}
+
+	// Pages split between tied jokes neither repeat nor skip one.
+	paged := append(listIDs("?sort=stable&limit=2"), listIDs("?sort=stable&limit=2&offset=2")...)
+	paged = append(paged, listIDs("?sort=stable&limit=2&offset=4")...)
+	if !slices.Equal(paged, want) {
+		t.Errorf("paged ids = %v, want %v", paged, want)
+	}
+
+	for _, query := range []string{"?sort=stable&period=week", "?sort=stable&format=ndjson"} {
+		if resp, _ := doRequest(t, http.MethodGet, srv.URL+"/api/joke/"+query, "", nil); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, resp.StatusCode, http.StatusBadRequest)
+		}
+	}
+}
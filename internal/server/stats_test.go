@@ -0,0 +1,36 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterLengthPercentiles(t *testing.T) {
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		for _, length := range []int{3, 8, 5, 20, 1} {
+			if _, err := repo.CreateJoke(context.Background(), &model.Joke{Text: strings.Repeat("a", length)}); err != nil {
+				t.Fatalf("seeding joke: %v", err)
+			}
+		}
+	})
+
+	resp, body := doRequest(t, http.MethodGet, srv.URL+"/api/admin/stats/length-percentiles", "", http.Header{"Admin-Api-Key": {testAdminAPIKey}})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	var stats model.LengthPercentiles
+	if err := json.Unmarshal([]byte(body), &stats); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	want := model.LengthPercentiles{Count: 5, Min: 1, P50: 5, P90: 20, P99: 20, Max: 20}
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterJokeTemplateVars(t *testing.T) {
+	var id int64
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		var err error
+		id, err = repo.CreateJoke(context.Background(), &model.Joke{Text: "Why did the {animal=chicken} cross the road?"})
+		if err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+	})
+	url := fmt.Sprintf("%s/api/joke/%d", srv.URL, id)
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantText   string
+	}{
+		{"", http.StatusOK, "Why did the {animal=chicken} cross the road?"},
+		{"?vars=", http.StatusOK, "Why did the chicken cross the road?"},
+		{"?vars=animal:dinosaur", http.StatusOK, "Why did the dinosaur cross the road?"},
+		{"?vars=animal:cow,vehicle:bus", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		resp, body := doRequest(t, http.MethodGet, url+tt.query, "", nil)
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%q: status = %d, want %d: %s", tt.query, resp.StatusCode, tt.wantStatus, body)
+			continue
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+
+		var joke model.Joke
+		if err := json.Unmarshal([]byte(body), &joke); err != nil {
+			t.Fatalf("%q: decoding joke: %v", tt.query, err)
+		}
+		if joke.Text != tt.wantText {
+			t.Errorf("%q: text = %q, want %q", tt.query, joke.Text, tt.wantText)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/handler"
+)
+
+func TestRouterDuplicateSubmissionThrottle(t *testing.T) {
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	srv, _ := newTestServerWithDeps(t, func(deps *Deps) {
+		deps.HandlerOptions.DuplicateSubmissionWindow = 30 * time.Second
+		deps.HandlerOptions.Now = func() time.Time { return now }
+	})
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+
+	create := func(text string) (*http.Response, string) {
+		t.Helper()
+		return doRequest(t, http.MethodPost, srv.URL+"/api/admin/joke", fmt.Sprintf(`{"text": %q}`, text), admin)
+	}
+
+	if resp, body := create("Why did the chicken cross the road?"); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("first submission: status = %d, want %d: %s", resp.StatusCode, http.StatusCreated, body)
+	}
+
+	// The same text, differently spaced and cased, right after.
+	now = now.Add(10 * time.Second)
+	resp, body := create("why did the  CHICKEN cross the road?")
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("repeated submission: status = %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+	}
+	if got := resp.Header.Get("Retry-After"); got != "20" {
+		t.Errorf("Retry-After = %q, want 20", got)
+	}
+	var errResp handler.ErrorResponse
+	if err := json.Unmarshal([]byte(body), &errResp); err != nil {
+		t.Fatalf("decoding error: %v", err)
+	}
+	if errResp.Code != "duplicate_submission" {
+		t.Errorf("code = %q, want duplicate_submission", errResp.Code)
+	}
+
+	if resp, _ := create("A different joke"); resp.StatusCode != http.StatusCreated {
+		t.Errorf("different text: status = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+
+	// Once the window has passed, the text may be submitted again and
+	// resolves to the joke already stored.
+	now = now.Add(30 * time.Second)
+	if resp, _ := create("Why did the chicken cross the road?"); resp.StatusCode != http.StatusOK {
+		t.Errorf("after the window: status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
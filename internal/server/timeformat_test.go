@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterTimeFormat(t *testing.T) {
+	createdAt := time.Date(2024, 5, 15, 12, 30, 45, 0, time.UTC)
+
+	const id = 1
+	srv, _ := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		// ReplaceJokes keeps the given timestamps, unlike CreateJoke.
+		if err := repo.ReplaceJokes(context.Background(), []*model.Joke{{ID: id, Text: "A timely joke", CreatedAt: createdAt, UpdatedAt: createdAt}}); err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+	})
+
+	decode := func(path string) map[string]any {
+		t.Helper()
+
+		resp, body := doRequest(t, http.MethodGet, srv.URL+path, "", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status = %d, want %d: %s", path, resp.StatusCode, http.StatusOK, body)
+		}
+
+		var fields map[string]any
+		if err := json.Unmarshal([]byte(body), &fields); err != nil {
+			t.Fatalf("%s: decoding: %v", path, err)
+		}
+		return fields
+	}
+
+	for _, query := range []string{"", "?time_format=rfc3339"} {
+		joke := decode(fmt.Sprintf("/api/joke/%d%s", id, query))
+		if got := joke["created_at"]; got != "2024-05-15T12:30:45Z" {
+			t.Errorf("%q: created_at = %v, want RFC 3339", query, got)
+		}
+	}
+
+	joke := decode(fmt.Sprintf("/api/joke/%d?time_format=unix", id))
+	for _, name := range []string{"created_at", "updated_at"} {
+		if got := joke[name]; got != float64(createdAt.Unix()) {
+			t.Errorf("%s = %v, want %d", name, got, createdAt.Unix())
+		}
+	}
+	if got := joke["id"]; got != float64(id) || joke["joke"] != "A timely joke" {
+		t.Errorf("unix joke = %v, want the other fields unchanged", joke)
+	}
+
+	list := decode("/api/joke/?time_format=unix")
+	jokes, _ := list["jokes"].([]any)
+	if len(jokes) != 1 || jokes[0].(map[string]any)["created_at"] != float64(createdAt.Unix()) {
+		t.Errorf("listed jokes = %v, want created_at %d", jokes, createdAt.Unix())
+	}
+
+	resp, _ := doRequest(t, http.MethodGet, fmt.Sprintf("%s/api/joke/%d?time_format=iso", srv.URL, id), "", nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("time_format=iso: status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestRouterTimeFormatEveryListing(t *testing.T) {
+	createdAt := time.Date(2024, 5, 15, 12, 30, 45, 0, time.UTC)
+
+	srv, _ := newTestServerWithDeps(t, func(deps *Deps) {
+		deps.HandlerOptions.StrictParams = true
+	}, func(repo *repository.SQLiteJokeRepository) {
+		ctx := context.Background()
+		if err := repo.ReplaceJokes(ctx, []*model.Joke{{ID: 1, Text: "A timely joke", Featured: true, CreatedAt: createdAt, UpdatedAt: createdAt}}); err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+		if err := repo.AddJokeViews(ctx, map[int64]int64{1: 3}); err != nil {
+			t.Fatalf("recording views: %v", err)
+		}
+		collection, err := repo.CreateCollection(ctx, &model.Collection{Name: "Timely"})
+		if err != nil {
+			t.Fatalf("creating collection: %v", err)
+		}
+		if err := repo.AddJokeToCollection(ctx, collection, 1); err != nil {
+			t.Fatalf("adding joke to collection: %v", err)
+		}
+	})
+
+	for _, path := range []string{"/api/joke/featured", "/api/joke/latest", "/api/joke/most-viewed", "/api/collections/1"} {
+		resp, body := doRequest(t, http.MethodGet, srv.URL+path+"?time_format=unix", "", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: status = %d, want %d: %s", path, resp.StatusCode, http.StatusOK, body)
+			continue
+		}
+
+		var list struct {
+			Jokes []map[string]any `json:"jokes"`
+		}
+		if err := json.Unmarshal([]byte(body), &list); err != nil {
+			t.Fatalf("%s: decoding: %v", path, err)
+		}
+		if len(list.Jokes) != 1 || list.Jokes[0]["created_at"] != float64(createdAt.Unix()) {
+			t.Errorf("%s: jokes = %v, want created_at %d", path, list.Jokes, createdAt.Unix())
+		}
+
+		resp, _ = doRequest(t, http.MethodGet, srv.URL+path+"?time_format=iso", "", nil)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s?time_format=iso: status = %d, want %d", path, resp.StatusCode, http.StatusBadRequest)
+		}
+	}
+}
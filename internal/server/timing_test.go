@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	internalMiddleware "github.com/treboc/huhu-api/internal/middleware"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterServerTiming(t *testing.T) {
+	srv, _ := newTestServerWithDeps(t, func(deps *Deps) {
+		deps.ServerTiming = true
+		deps.Repo = repository.NewInstrumentedJokeRepository(deps.Repo, internalMiddleware.RecordQueryTiming)
+	})
+
+	resp, _ := doRequest(t, http.MethodGet, srv.URL+"/api/joke/", "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	header := resp.Header.Get("Server-Timing")
+	durations := make(map[string]float64)
+	for _, metric := range strings.Split(header, ",") {
+		name, dur, ok := strings.Cut(strings.TrimSpace(metric), ";dur=")
+		if !ok {
+			t.Fatalf("malformed metric %q in Server-Timing %q", metric, header)
+		}
+		d, err := strconv.ParseFloat(dur, 64)
+		if err != nil {
+			t.Fatalf("malformed duration %q in Server-Timing %q", dur, header)
+		}
+		durations[name] = d
+	}
+
+	db, hasDB := durations["db"]
+	total, hasTotal := durations["total"]
+	if !hasDB || !hasTotal {
+		t.Fatalf("Server-Timing %q lacks db or total", header)
+	}
+	if db > total {
+		t.Errorf("db time %v exceeds total %v", db, total)
+	}
+}
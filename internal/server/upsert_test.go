@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/handler"
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/repository"
+)
+
+func TestRouterUpsertJokes(t *testing.T) {
+	var existingID int64
+	srv, repo := newTestServer(t, func(repo *repository.SQLiteJokeRepository) {
+		id, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "An existing joke"})
+		if err != nil {
+			t.Fatalf("seeding joke: %v", err)
+		}
+		existingID = id
+	})
+	admin := http.Header{"Admin-Api-Key": {testAdminAPIKey}}
+
+	body := `[
+		{"text": "A brand new joke"},
+		{"text": "  an EXISTING   joke "},
+		{"text": "a brand new joke"}
+	]`
+	resp, respBody := doRequest(t, http.MethodPost, srv.URL+"/api/admin/jokes/upsert", body, admin)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d (body %s)", resp.StatusCode, http.StatusOK, respBody)
+	}
+
+	var result handler.UpsertJokesResponse
+	if err := json.Unmarshal([]byte(respBody), &result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+
+	if len(result.Succeeded) != 3 || len(result.Failed) != 0 {
+		t.Fatalf("got %d succeeded and %d failed, want 3 and 0", len(result.Succeeded), len(result.Failed))
+	}
+
+	created := result.Succeeded[0]
+	if created.ID != 0 || created.Status != "created" || created.Joke.Text != "A brand new joke" {
+		t.Errorf("item 0 = %d %s %q, want created %q", created.ID, created.Status, created.Joke.Text, "A brand new joke")
+	}
+	if got := result.Succeeded[1]; got.ID != 1 || got.Status != "existing" || got.Joke.ID != existingID {
+		t.Errorf("item 1 = %d %s joke %d, want existing joke %d", got.ID, got.Status, got.Joke.ID, existingID)
+	}
+	if got := result.Succeeded[2]; got.ID != 2 || got.Status != "existing" || got.Joke.ID != created.Joke.ID {
+		t.Errorf("item 2 = %d %s joke %d, want existing joke %d from the same batch", got.ID, got.Status, got.Joke.ID, created.Joke.ID)
+	}
+	if result.Created != 1 || result.Existing != 2 {
+		t.Errorf("created/existing = %d/%d, want 1/2", result.Created, result.Existing)
+	}
+
+	count, err := repo.CountJokes(context.Background())
+	if err != nil {
+		t.Fatalf("counting jokes: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("joke count = %d, want 2", count)
+	}
+
+	// Invalid jokes fail on their own while the valid ones are stored.
+	resp, respBody = doRequest(t, http.MethodPost, srv.URL+"/api/admin/jokes/upsert", `[{"text":"  "},{"text":"Another new one"},{"text":"<b>bold</b>","setup":"x"}]`, admin)
+	if resp.StatusCode != http.StatusMultiStatus {
+		t.Fatalf("partial batch status = %d, want %d (body %s)", resp.StatusCode, http.StatusMultiStatus, respBody)
+	}
+
+	result = handler.UpsertJokesResponse{}
+	if err := json.Unmarshal([]byte(respBody), &result); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(result.Succeeded) != 1 || result.Succeeded[0].ID != 1 || result.Succeeded[0].Status != "created" {
+		t.Errorf("succeeded = %+v, want item 1 created", result.Succeeded)
+	}
+	wantFailed := []handler.BatchFailure{
+		{ID: 0, Error: "Joke text is required", Code: "invalid_joke"},
+		{ID: 2, Error: "Provide either text or setup and punchline, not both", Code: "invalid_joke"},
+	}
+	if !slices.Equal(result.Failed, wantFailed) {
+		t.Errorf("failed = %+v, want %+v", result.Failed, wantFailed)
+	}
+	if count, _ := repo.CountJokes(context.Background()); count != 3 {
+		t.Errorf("joke count after partial batch = %d, want 3", count)
+	}
+}
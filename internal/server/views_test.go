@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/treboc/huhu-api/internal/model"
+	"github.com/treboc/huhu-api/internal/views"
+)
+
+func TestRouterGetJokeRecordsAccess(t *testing.T) {
+	var counter *views.Counter
+	srv, repo := newTestServerWithDeps(t, func(deps *Deps) {
+		counter = views.NewCounter(deps.Repo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		deps.HandlerOptions.Views = counter
+	})
+
+	id, err := repo.CreateJoke(context.Background(), &model.Joke{Text: "knock knock"})
+	if err != nil {
+		t.Fatalf("seeding joke: %v", err)
+	}
+
+	resp, _ := doRequest(t, http.MethodGet, fmt.Sprintf("%s/api/joke/%d", srv.URL, id), "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	// The read itself doesn't write; the access lands with the next flush.
+	joke, err := repo.GetJoke(context.Background(), id)
+	if err != nil {
+		t.Fatalf("getting joke: %v", err)
+	}
+	if joke.LastAccessedAt != nil {
+		t.Errorf("last_accessed_at = %v before a flush, want none", joke.LastAccessedAt)
+	}
+
+	if err := counter.Flush(context.Background()); err != nil {
+		t.Fatalf("flushing views: %v", err)
+	}
+
+	joke, err = repo.GetJoke(context.Background(), id)
+	if err != nil {
+		t.Fatalf("getting joke: %v", err)
+	}
+	if joke.LastAccessedAt == nil || joke.ViewCount != 1 {
+		t.Errorf("after flush: last_accessed_at = %v, views = %d, want set and 1", joke.LastAccessedAt, joke.ViewCount)
+	}
+}
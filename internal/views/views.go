@@ -0,0 +1,130 @@
+// Package views counts joke views and notes joke access times in memory,
+// and writes them to the database in batches, so reads don't turn into one
+// write each.
+package views
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// flushTimeout bounds the final flush when the counter shuts down.
+const flushTimeout = 5 * time.Second
+
+// Store persists batches of views and access times, keyed by joke ID.
+type Store interface {
+	AddJokeViews(ctx context.Context, views map[int64]int64) error
+	TouchJokes(ctx context.Context, accessed map[int64]time.Time) error
+}
+
+// Counter buffers views recorded with Record until the next Flush.
+type Counter struct {
+	store  Store
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu       sync.Mutex
+	pending  map[int64]int64
+	accessed map[int64]time.Time
+}
+
+func NewCounter(store Store, logger *slog.Logger) *Counter {
+	return &Counter{
+		store:    store,
+		logger:   logger,
+		now:      time.Now,
+		pending:  make(map[int64]int64),
+		accessed: make(map[int64]time.Time),
+	}
+}
+
+// Record counts a view of the joke and notes it as accessed now. It never
+// blocks on the database.
+func (c *Counter) Record(id int64) {
+	now := c.now()
+
+	c.mu.Lock()
+	c.pending[id]++
+	c.accessed[id] = now
+	c.mu.Unlock()
+}
+
+// Flush writes the buffered views and access times to the store. Whatever
+// fails to be written is kept for the next flush.
+func (c *Counter) Flush(ctx context.Context) error {
+	return errors.Join(c.flushViews(ctx), c.flushAccesses(ctx))
+}
+
+func (c *Counter) flushViews(ctx context.Context) error {
+	c.mu.Lock()
+	batch := c.pending
+	c.pending = make(map[int64]int64, len(batch))
+	c.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	if err := c.store.AddJokeViews(ctx, batch); err != nil {
+		c.mu.Lock()
+		for id, n := range c.pending {
+			batch[id] += n
+		}
+		c.pending = batch
+		c.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+func (c *Counter) flushAccesses(ctx context.Context) error {
+	c.mu.Lock()
+	batch := c.accessed
+	c.accessed = make(map[int64]time.Time, len(batch))
+	c.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	if err := c.store.TouchJokes(ctx, batch); err != nil {
+		c.mu.Lock()
+		// Accesses recorded since the batch was taken are newer.
+		for id, at := range c.accessed {
+			batch[id] = at
+		}
+		c.accessed = batch
+		c.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// Run flushes every interval until ctx is cancelled, then flushes one last
+// time so no views are lost on shutdown.
+func (c *Counter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+			defer cancel()
+
+			if err := c.Flush(flushCtx); err != nil {
+				c.logger.Error("Failed to write joke views on shutdown", slog.String("error", err.Error()))
+			}
+			return
+		case <-ticker.C:
+			if err := c.Flush(ctx); err != nil {
+				c.logger.Error("Failed to write joke views", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
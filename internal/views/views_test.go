@@ -0,0 +1,131 @@
+package views
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"maps"
+	"testing"
+	"time"
+)
+
+// fakeStore records the batches it receives and fails while err is set.
+type fakeStore struct {
+	batches  []map[int64]int64
+	accessed map[int64]time.Time
+	err      error
+}
+
+func (s *fakeStore) AddJokeViews(ctx context.Context, views map[int64]int64) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.batches = append(s.batches, maps.Clone(views))
+	return nil
+}
+
+func (s *fakeStore) TouchJokes(ctx context.Context, accessed map[int64]time.Time) error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.accessed == nil {
+		s.accessed = make(map[int64]time.Time)
+	}
+	maps.Copy(s.accessed, accessed)
+	return nil
+}
+
+func TestCounterBatchesViews(t *testing.T) {
+	store := &fakeStore{}
+	counter := NewCounter(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for _, id := range []int64{1, 2, 1, 1} {
+		counter.Record(id)
+	}
+
+	if len(store.batches) != 0 {
+		t.Fatal("views were written before a flush")
+	}
+
+	if err := counter.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	want := map[int64]int64{1: 3, 2: 1}
+	if len(store.batches) != 1 || !maps.Equal(store.batches[0], want) {
+		t.Fatalf("batches = %v, want one batch %v", store.batches, want)
+	}
+
+	// Nothing new, nothing written.
+	if err := counter.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if len(store.batches) != 1 {
+		t.Errorf("empty flush wrote a batch: %v", store.batches)
+	}
+}
+
+func TestCounterKeepsViewsOnFailedFlush(t *testing.T) {
+	store := &fakeStore{err: errors.New("database is locked")}
+	counter := NewCounter(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	counter.Record(1)
+	if err := counter.Flush(context.Background()); err == nil {
+		t.Fatal("Flush succeeded, want the store error")
+	}
+
+	counter.Record(1)
+	counter.Record(2)
+
+	store.err = nil
+	if err := counter.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	want := map[int64]int64{1: 2, 2: 1}
+	if len(store.batches) != 1 || !maps.Equal(store.batches[0], want) {
+		t.Errorf("batches = %v, want one batch %v", store.batches, want)
+	}
+}
+
+func TestCounterFlushesOnShutdown(t *testing.T) {
+	store := &fakeStore{}
+	counter := NewCounter(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	counter.Record(7)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	counter.Run(ctx, time.Hour)
+
+	if len(store.batches) != 1 || store.batches[0][7] != 1 {
+		t.Errorf("batches = %v, want the pending view written", store.batches)
+	}
+}
+
+func TestCounterBuffersAccessTimes(t *testing.T) {
+	store := &fakeStore{err: errors.New("database is locked")}
+	counter := NewCounter(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	counter.now = func() time.Time { return now }
+
+	counter.Record(1)
+	counter.Record(2)
+	if err := counter.Flush(context.Background()); err == nil {
+		t.Fatal("Flush succeeded, want the store error")
+	}
+
+	// A failed flush keeps the access times, and later accesses win.
+	now = now.Add(time.Minute)
+	counter.Record(1)
+	store.err = nil
+	if err := counter.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	want := map[int64]time.Time{1: now, 2: now.Add(-time.Minute)}
+	if !maps.Equal(store.accessed, want) {
+		t.Errorf("accessed = %v, want %v", store.accessed, want)
+	}
+}